@@ -0,0 +1,101 @@
+package main
+
+import (
+	"log"
+)
+
+// 可绑定到全局热键的动作名
+const (
+	actionShowQuickSelector = "showQuickSelector"
+	actionPastePrevious     = "pastePrevious"
+	actionToggleMonitoring  = "toggleMonitoring"
+	actionToggleIncognito   = "toggleIncognito"
+)
+
+// hotkeyAction 可以绑定到全局热键的动作
+type hotkeyAction struct {
+	Name  string
+	Label string
+	run   func()
+}
+
+// hotkeyActions 返回动作目录，顺序即界面中的显示顺序
+func (ca *ClipboardApp) hotkeyActions() []hotkeyAction {
+	return []hotkeyAction{
+		{Name: actionShowQuickSelector, Label: "显示快速选择界面", run: ca.showQuickSelector},
+		{Name: actionPastePrevious, Label: "粘贴上一条记录", run: ca.pastePrevious},
+		{Name: actionToggleMonitoring, Label: "暂停/恢复监控", run: ca.toggleMonitoring},
+		{Name: actionToggleIncognito, Label: "开启/关闭隐身模式", run: ca.toggleIncognito},
+	}
+}
+
+// findHotkeyAction 按名称查找动作
+func (ca *ClipboardApp) findHotkeyAction(name string) (hotkeyAction, bool) {
+	for _, action := range ca.hotkeyActions() {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return hotkeyAction{}, false
+}
+
+// evalOnUI 在 UI 线程上执行 JavaScript，热键回调运行在其他 goroutine 中
+func (ca *ClipboardApp) evalOnUI(js string) {
+	if ca.w == nil {
+		return
+	}
+	ca.w.Dispatch(func() {
+		ca.w.Eval(js)
+	})
+}
+
+// showQuickSelector 显示快速选择界面
+func (ca *ClipboardApp) showQuickSelector() {
+	log.Printf("全局热键被触发，显示快速选择界面")
+	ca.evalOnUI(`
+		if (typeof showQuickSelector === 'function') {
+			showQuickSelector();
+		}
+	`)
+}
+
+// pastePrevious 粘贴当前剪贴板内容之前的那一条记录
+func (ca *ClipboardApp) pastePrevious() {
+	ca.w.Dispatch(func() {
+		if ca.locked {
+			log.Printf("历史记录已锁定，无法粘贴上一条记录")
+			return
+		}
+		previous, ok := ca.monitor.Previous()
+		if !ok {
+			log.Printf("没有上一条记录可粘贴")
+			return
+		}
+		if err := ca.pasteEntry(previous, ca.settings.TypeInsteadOfPaste); err != nil {
+			log.Printf("粘贴上一条记录失败: %v", err)
+		}
+	})
+}
+
+// toggleMonitoring 无限期暂停或恢复记录，状态变化由事件通知前端
+func (ca *ClipboardApp) toggleMonitoring() {
+	ca.w.Dispatch(func() {
+		if ca.monitor.IsPaused() {
+			ca.monitor.Resume()
+		} else {
+			ca.monitor.Pause()
+		}
+	})
+}
+
+// toggleIncognito 开启隐身模式，按设置的时长暂停记录后自动恢复；已暂停时恢复记录。
+// 设置只在 UI 线程上修改，因此同样在 UI 线程上读取。
+func (ca *ClipboardApp) toggleIncognito() {
+	ca.w.Dispatch(func() {
+		if ca.monitor.IsPaused() {
+			ca.monitor.Resume()
+		} else {
+			ca.monitor.PauseFor(ca.settings.IncognitoDuration())
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"clipboard-monitor/clipboard"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// historyEventName 历史记录变更时在 window 上派发的 DOM 事件名
+const historyEventName = "clipboard-history"
+
+// startEventBridge 订阅 Monitor 的历史记录事件并转发给前端
+func (ca *ClipboardApp) startEventBridge() {
+	ca.events = ca.monitor.SubscribeFunc(clipboard.DefaultEventBuffer, func(event clipboard.Event) {
+		// 暂停状态不包含历史记录内容，锁定期间也需要更新
+		private := event.Type != clipboard.EventPaused && event.Type != clipboard.EventResumed
+		event.Entry = event.Entry.Brief()
+		ca.dispatchEvent(historyEventName, event, private)
+	})
+}
+
+// briefEntries 返回发送给前端的条目列表，大条目只包含预览
+func briefEntries(entries []clipboard.ClipboardEntry) []clipboard.ClipboardEntry {
+	brief := make([]clipboard.ClipboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		brief = append(brief, entry.Brief())
+	}
+	return brief
+}
+
+// stopEventBridge 取消事件订阅
+func (ca *ClipboardApp) stopEventBridge() {
+	if ca.events != nil {
+		ca.monitor.Unsubscribe(ca.events)
+		ca.events = nil
+	}
+}
+
+// dispatchEvent 将 detail 序列化为 JSON，在 UI 线程上以 CustomEvent 的形式派发到 window
+//
+// JSON 本身就是合法的 JavaScript 字面量（encoding/json 会转义 U+2028/U+2029 和 HTML 字符），
+// 因此内容无需再做字符串拼接转义。private 为 true 时历史记录锁定期间不派发，避免泄露内容。
+func (ca *ClipboardApp) dispatchEvent(name string, detail interface{}, private bool) {
+	if ca.w == nil {
+		return
+	}
+	data, err := json.Marshal(detail)
+	if err != nil {
+		log.Printf("序列化事件失败: %v", err)
+		return
+	}
+	nameJSON, _ := json.Marshal(name)
+	js := fmt.Sprintf("window.dispatchEvent(new CustomEvent(%s, { detail: %s }));", nameJSON, data)
+
+	ca.w.Dispatch(func() {
+		// locked 只在 UI 线程上修改，这里读取是安全的
+		if private && ca.locked {
+			return
+		}
+		ca.w.Eval(js)
+	})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"clipboard-monitor/clipboard"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	webview "github.com/webview/webview_go"
+)
+
+// fakeWebView 只实现事件转发用到的 Dispatch 和 Eval：
+// Dispatch 的函数排队等待测试 goroutine 执行，相当于 UI 线程
+type fakeWebView struct {
+	webview.WebView
+	ui    chan func()
+	evals []string
+}
+
+func newFakeWebView() *fakeWebView {
+	return &fakeWebView{ui: make(chan func(), 16)}
+}
+
+func (w *fakeWebView) Dispatch(f func()) {
+	w.ui <- f
+}
+
+func (w *fakeWebView) Eval(js string) {
+	w.evals = append(w.evals, js)
+}
+
+// runUI 在 UI 线程上执行下一个排队的函数，返回其间执行的脚本
+func (w *fakeWebView) runUI(t *testing.T) []string {
+	t.Helper()
+	select {
+	case f := <-w.ui:
+		w.evals = nil
+		f()
+		return w.evals
+	case <-time.After(2 * time.Second):
+		t.Fatal("No function dispatched to the UI thread")
+		return nil
+	}
+}
+
+// expectDispatched 执行下一个排队的函数，检查它派发了一个历史记录事件并返回事件内容
+func expectDispatched(t *testing.T, w *fakeWebView, eventType clipboard.EventType) clipboard.Event {
+	t.Helper()
+	evals := w.runUI(t)
+	if len(evals) != 1 {
+		t.Fatalf("Expected one script, got %d", len(evals))
+	}
+
+	prefix := `window.dispatchEvent(new CustomEvent("` + historyEventName + `", { detail: `
+	suffix := ` }));`
+	js := evals[0]
+	if !strings.HasPrefix(js, prefix) || !strings.HasSuffix(js, suffix) {
+		t.Fatalf("Unexpected script: %s", js)
+	}
+	var event clipboard.Event
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(js, prefix), suffix)), &event); err != nil {
+		t.Fatalf("Invalid event payload: %v", err)
+	}
+	if event.Type != eventType {
+		t.Fatalf("Expected %s event, got %s", eventType, event.Type)
+	}
+	return event
+}
+
+func TestEventBridge(t *testing.T) {
+	backend := clipboard.NewMemoryBackend()
+	monitor := clipboard.NewMonitor(10, clipboard.WithBackend(backend), clipboard.WithPreviewThreshold(100))
+	w := newFakeWebView()
+	ca := &ClipboardApp{w: w, monitor: monitor}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Start(ctx)
+	ca.startEventBridge()
+
+	backend.SetContent("hello </script> ")
+	added := expectDispatched(t, w, clipboard.EventAdded)
+	if added.Entry.Content != "hello </script> " || added.Entry.ID == "" {
+		t.Errorf("Unexpected entry: %+v", added.Entry)
+	}
+
+	// 大条目只发送预览
+	large := strings.Repeat("line\n", 50)
+	backend.SetContent(large)
+	brief := expectDispatched(t, w, clipboard.EventAdded)
+	if brief.Entry.Preview == nil || brief.Entry.Content == large {
+		t.Errorf("Expected a preview for the large entry, got %d bytes", len(brief.Entry.Content))
+	}
+	if brief.Seq != added.Seq+1 {
+		t.Errorf("Expected seq %d, got %d", added.Seq+1, brief.Seq)
+	}
+
+	// 锁定期间不派发历史记录内容，暂停状态仍然派发
+	ca.locked = true
+	if _, err := monitor.Pin(added.Entry.ID); err != nil {
+		t.Fatal(err)
+	}
+	if evals := w.runUI(t); len(evals) != 0 {
+		t.Errorf("Expected no script while locked, got %v", evals)
+	}
+	monitor.Pause()
+	paused := expectDispatched(t, w, clipboard.EventPaused)
+	if paused.Pause == nil || !paused.Pause.Paused {
+		t.Errorf("Unexpected pause state: %+v", paused.Pause)
+	}
+	// 锁定期间的事件没有派发，但仍然占用序号
+	if paused.Seq != brief.Seq+2 {
+		t.Errorf("Expected seq %d, got %d", brief.Seq+2, paused.Seq)
+	}
+
+	// 停止后取消订阅，不再派发事件
+	ca.stopEventBridge()
+	if ca.events != nil {
+		t.Error("Expected subscription to be cleared")
+	}
+	monitor.Resume()
+	select {
+	case <-w.ui:
+		t.Error("Unexpected dispatch after stopEventBridge")
+	case <-time.After(100 * time.Millisecond):
+	}
+	ca.stopEventBridge()
+}
@@ -0,0 +1,91 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrSourceUnknown 无法识别剪贴板内容的来源应用程序
+var ErrSourceUnknown = errors.New("clipboard owner unknown")
+
+// SourceBackend 可选接口，能够识别剪贴板内容来源应用程序的后端实现该接口
+type SourceBackend interface {
+	// SourceApp 返回当前剪贴板所有者的进程名，无法识别时返回错误
+	SourceApp() (string, error)
+}
+
+// AppRule 按来源应用程序决定是否记录剪贴板内容的规则
+type AppRule struct {
+	Pattern string // 进程名，不区分大小写并忽略 .exe 后缀，支持 path.Match 的 * ? [] 通配符
+	Allow   bool   // 匹配时记录（true）还是排除（false）
+}
+
+// NewAppRule 创建规则并检查通配符是否有效
+func NewAppRule(pattern string, allow bool) (AppRule, error) {
+	pattern = normalizeAppName(pattern)
+	if pattern == "" {
+		return AppRule{}, errors.New("application pattern is empty")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return AppRule{}, fmt.Errorf("invalid application pattern %q: %v", pattern, err)
+	}
+	return AppRule{Pattern: pattern, Allow: allow}, nil
+}
+
+// Match 规则是否匹配进程名；来源未知时进程名为空，只有 * 能匹配
+func (r AppRule) Match(app string) bool {
+	ok, _ := path.Match(normalizeAppName(r.Pattern), normalizeAppName(app))
+	return ok
+}
+
+// AppAllowed 按顺序匹配规则，由第一个匹配的规则决定是否记录，没有匹配的规则时记录
+func AppAllowed(rules []AppRule, app string) bool {
+	for _, rule := range rules {
+		if rule.Match(app) {
+			return rule.Allow
+		}
+	}
+	return true
+}
+
+// normalizeAppName 规范化进程名：去掉空白和 .exe 后缀并转为小写
+func normalizeAppName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// WithAppRules 指定按来源应用程序排除内容的规则
+func WithAppRules(rules ...AppRule) Option {
+	return func(m *Monitor) {
+		m.appRules = append([]AppRule(nil), rules...)
+	}
+}
+
+// SetAppRules 替换来源应用程序规则，只影响之后捕获的内容
+func (m *Monitor) SetAppRules(rules []AppRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appRules = append([]AppRule(nil), rules...)
+}
+
+// AppRules 返回当前的来源应用程序规则
+func (m *Monitor) AppRules() []AppRule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]AppRule(nil), m.appRules...)
+}
+
+// sourceApp 识别当前剪贴板内容的来源应用程序，后端不支持或识别失败时返回空字符串
+func (m *Monitor) sourceApp() string {
+	sb, ok := m.currentBackend().(SourceBackend)
+	if !ok {
+		return ""
+	}
+	app, err := sb.SourceApp()
+	if err != nil {
+		return ""
+	}
+	return app
+}
@@ -0,0 +1,93 @@
+package clipboard
+
+import "testing"
+
+func TestAppRuleMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		app     string
+		want    bool
+	}{
+		{"keepassxc", "keepassxc", true},
+		{"KeePassXC", "keepassxc", true},
+		{"1Password.exe", "1password", true},
+		{"1password", "1Password.exe", true},
+		{"bank*", "BankClient.exe", true},
+		{"keepass?", "keepassx", true},
+		{"keepass?", "keepassxc", false},
+		{"firefox", "", false},
+		{"*", "", true},
+	}
+	for _, tt := range tests {
+		rule, err := NewAppRule(tt.pattern, false)
+		if err != nil {
+			t.Fatalf("NewAppRule(%q) failed: %v", tt.pattern, err)
+		}
+		if got := rule.Match(tt.app); got != tt.want {
+			t.Errorf("%q.Match(%q) = %v, want %v", tt.pattern, tt.app, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{"", "  ", "bank["} {
+		if _, err := NewAppRule(pattern, false); err == nil {
+			t.Errorf("Expected error for pattern %q", pattern)
+		}
+	}
+}
+
+func TestAppAllowed(t *testing.T) {
+	deny := []AppRule{{Pattern: "keepassxc"}, {Pattern: "1password"}}
+	if AppAllowed(deny, "KeePassXC") || !AppAllowed(deny, "firefox") || !AppAllowed(deny, "") {
+		t.Error("Unexpected result for deny list")
+	}
+
+	// 第一个匹配的规则生效：只记录 firefox 和 code，来源未知的内容也排除
+	allow := []AppRule{{Pattern: "firefox", Allow: true}, {Pattern: "code", Allow: true}, {Pattern: "*"}}
+	if !AppAllowed(allow, "firefox") || !AppAllowed(allow, "code") || AppAllowed(allow, "keepassxc") || AppAllowed(allow, "") {
+		t.Error("Unexpected result for allow list")
+	}
+
+	if !AppAllowed(nil, "anything") {
+		t.Error("Expected content to be allowed without rules")
+	}
+}
+
+func TestMonitorExcludesApps(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend), WithAppRules(AppRule{Pattern: "keepassxc"}))
+
+	backend.SetSourceApp("firefox")
+	backend.SetContent("hello")
+	monitor.checkClipboard()
+	backend.SetSourceApp("keepassxc")
+	backend.SetContent("secret")
+	monitor.checkClipboard()
+	backend.SetSourceApp("")
+	backend.SetContent("unknown")
+	monitor.checkClipboard()
+
+	history := monitor.GetHistory()
+	expectContents(t, history, "unknown", "hello")
+	if history[0].SourceApp != "" || history[1].SourceApp != "firefox" {
+		t.Errorf("Unexpected sources: %q, %q", history[0].SourceApp, history[1].SourceApp)
+	}
+
+	// 再次复制相同内容时记录最新的来源
+	backend.SetSourceApp("code")
+	backend.SetContent("hello")
+	monitor.checkClipboard()
+	if entry := findEntry(t, monitor, "hello"); entry.SourceApp != "code" {
+		t.Errorf("Expected source to be updated, got %q", entry.SourceApp)
+	}
+
+	monitor.SetAppRules(nil)
+	backend.SetSourceApp("keepassxc")
+	backend.SetContent("secret")
+	monitor.checkClipboard()
+	if entry := findEntry(t, monitor, "secret"); entry.SourceApp != "keepassxc" {
+		t.Errorf("Expected source keepassxc, got %q", entry.SourceApp)
+	}
+	if len(monitor.AppRules()) != 0 {
+		t.Errorf("Expected no rules, got %v", monitor.AppRules())
+	}
+}
@@ -0,0 +1,42 @@
+package clipboard
+
+import (
+	"context"
+
+	"github.com/atotto/clipboard"
+)
+
+// Backend 剪贴板后端接口，负责实际读写系统剪贴板
+type Backend interface {
+	// ReadAll 读取剪贴板中的文本
+	ReadAll() (string, error)
+	// WriteAll 写入文本到剪贴板
+	WriteAll(text string) error
+}
+
+// Watcher 可选接口，支持事件驱动的后端实现该接口后 Monitor 将不再轮询。
+// Watch 应阻塞直到 ctx 取消，每当剪贴板可能发生变化时调用 notify；
+// 返回非 ctx 错误时 Monitor 会回退到轮询模式。
+type Watcher interface {
+	Watch(ctx context.Context, notify func()) error
+}
+
+// SystemBackend 基于 github.com/atotto/clipboard 的默认后端
+type SystemBackend struct {
+	formats formatCache // ReadFormats 的缓存，剪贴板未变化时不重新读取
+}
+
+// NewSystemBackend 创建系统剪贴板后端
+func NewSystemBackend() *SystemBackend {
+	return &SystemBackend{}
+}
+
+// ReadAll 读取系统剪贴板文本
+func (b *SystemBackend) ReadAll() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// WriteAll 写入文本到系统剪贴板
+func (b *SystemBackend) WriteAll(text string) error {
+	return clipboard.WriteAll(text)
+}
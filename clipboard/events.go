@@ -0,0 +1,122 @@
+package clipboard
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultEventBuffer 订阅者默认的事件缓冲区大小
+const DefaultEventBuffer = 64
+
+// EventType 历史记录事件类型
+type EventType string
+
+// 历史记录事件
+const (
+	EventAdded    EventType = "added"    // 新条目加入顶部
+	EventPromoted EventType = "promoted" // 已有条目被再次复制，移动到顶部
+	EventUpdated  EventType = "updated"  // 条目被原位修改
+	EventPinned   EventType = "pinned"   // 条目被固定、取消固定或调整了固定顺序，其他条目的位置可能随之变化
+	EventDeleted  EventType = "deleted"  // 条目被删除或因超出上限被淘汰
+	EventCleared  EventType = "cleared"  // 历史记录被清空
+	EventReloaded EventType = "reloaded" // 历史记录整体替换（如挂载存储），订阅者需重新获取
+	EventPaused   EventType = "paused"   // 暂停记录或修改了隐身模式的恢复时间，状态见 Event.Pause
+	EventResumed  EventType = "resumed"  // 恢复记录
+)
+
+// Event 历史记录变更事件
+//
+// Seq 在同一个 Monitor 内严格递增，订阅者收到的事件顺序与变更发生的顺序一致；
+// 缓冲区满时事件会被丢弃，订阅者可以通过 Seq 不连续发现并重新获取历史记录。
+type Event struct {
+	Type  EventType      `json:"type"`
+	Seq   uint64         `json:"seq"`
+	Entry ClipboardEntry `json:"entry"`           // Cleared/Reloaded/Paused/Resumed 事件为零值
+	Pause *PauseState    `json:"pause,omitempty"` // 仅 Paused/Resumed 事件包含，为变更后的状态
+}
+
+// Subscription 事件订阅
+type Subscription struct {
+	C       <-chan Event // 事件通道，取消订阅后关闭
+	ch      chan Event
+	dropped atomic.Uint64
+}
+
+// Dropped 返回因缓冲区已满而丢弃的事件数
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// eventBus 事件分发，发布不会阻塞，每个订阅者有独立的缓冲区
+type eventBus struct {
+	mu   sync.Mutex
+	seq  uint64
+	subs map[*Subscription]struct{}
+}
+
+// Subscribe 订阅历史记录事件，buffer 为缓冲区大小（<= 0 时使用 DefaultEventBuffer）
+//
+// 订阅者需要及时读取 C，缓冲区满时新事件会被丢弃而不是阻塞 Monitor。
+func (m *Monitor) Subscribe(buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultEventBuffer
+	}
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch}
+
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+	if m.events.subs == nil {
+		m.events.subs = make(map[*Subscription]struct{})
+	}
+	m.events.subs[sub] = struct{}{}
+	return sub
+}
+
+// SubscribeFunc 订阅历史记录事件并在独立的 goroutine 中按顺序调用 handler
+func (m *Monitor) SubscribeFunc(buffer int, handler func(Event)) *Subscription {
+	sub := m.Subscribe(buffer)
+	go func() {
+		for event := range sub.C {
+			handler(event)
+		}
+	}()
+	return sub
+}
+
+// Unsubscribe 取消订阅并关闭事件通道，重复调用是安全的
+func (m *Monitor) Unsubscribe(sub *Subscription) {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+	if _, ok := m.events.subs[sub]; !ok {
+		return
+	}
+	delete(m.events.subs, sub)
+	close(sub.ch)
+}
+
+// publish 向全部订阅者发送历史记录事件，调用方需持有 Monitor 的写锁以保证事件顺序与变更顺序一致
+func (m *Monitor) publish(eventType EventType, entry ClipboardEntry) {
+	m.send(Event{Type: eventType, Entry: entry})
+}
+
+// publishState 发送暂停状态变更事件，调用方需持有 Monitor 的写锁
+func (m *Monitor) publishState(eventType EventType, state PauseState) {
+	m.send(Event{Type: eventType, Pause: &state})
+}
+
+// send 分配序号并发送事件，订阅者的缓冲区已满时丢弃
+func (m *Monitor) send(event Event) {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	m.events.seq++
+	event.Seq = m.events.seq
+	for sub := range m.events.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package clipboard
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// nextEvent 读取下一个事件，超时则失败
+func nextEvent(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription closed unexpectedly")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return Event{}
+	}
+}
+
+// expectEvent 读取下一个事件并检查类型和条目内容
+func expectEvent(t *testing.T, sub *Subscription, eventType EventType, content string) Event {
+	t.Helper()
+	event := nextEvent(t, sub)
+	if event.Type != eventType || event.Entry.Content != content {
+		t.Fatalf("Expected %s %q, got %s %q", eventType, content, event.Type, event.Entry.Content)
+	}
+	return event
+}
+
+func TestEventsFollowHistoryChanges(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(2, WithBackend(backend))
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	backend.SetContent("a")
+	monitor.checkClipboard()
+	backend.SetContent("b")
+	monitor.checkClipboard()
+	backend.SetContent("a")
+	monitor.checkClipboard()
+	backend.SetContent("c")
+	monitor.checkClipboard()
+
+	a := expectEvent(t, sub, EventAdded, "a")
+	expectEvent(t, sub, EventAdded, "b")
+	if promoted := expectEvent(t, sub, EventPromoted, "a"); promoted.Entry.ID != a.Entry.ID {
+		t.Errorf("Expected promoted event to carry the original ID")
+	}
+	expectEvent(t, sub, EventAdded, "c")
+	expectEvent(t, sub, EventDeleted, "b") // 超出上限被淘汰
+
+	c := monitor.GetHistory()[0]
+	monitor.UpdateEntry(c.ID, func(entry *ClipboardEntry) { entry.Content = "c2" })
+	expectEvent(t, sub, EventUpdated, "c2")
+
+	monitor.DeleteEntry(c.ID)
+	expectEvent(t, sub, EventDeleted, "c2")
+
+	monitor.ClearHistory()
+	expectEvent(t, sub, EventCleared, "")
+
+	select {
+	case event := <-sub.C:
+		t.Errorf("Unexpected extra event: %+v", event)
+	default:
+	}
+}
+
+func TestEventSequenceIsOrdered(t *testing.T) {
+	monitor := NewMonitor(1000)
+	first := monitor.Subscribe(1000)
+	second := monitor.Subscribe(1000)
+
+	done := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		go func(g int) {
+			for i := 0; i < 100; i++ {
+				monitor.mu.Lock()
+				monitor.addToHistory(ClipboardEntry{Content: fmt.Sprintf("%d-%d", g, i)})
+				monitor.mu.Unlock()
+			}
+			done <- struct{}{}
+		}(g)
+	}
+	for g := 0; g < 4; g++ {
+		<-done
+	}
+
+	// 每个订阅者看到相同且严格递增的序列
+	for i := 0; i < 400; i++ {
+		a, b := nextEvent(t, first), nextEvent(t, second)
+		if a.Seq != uint64(i+1) || b.Seq != a.Seq || b.Entry.ID != a.Entry.ID {
+			t.Fatalf("Event %d out of order: %+v vs %+v", i, a, b)
+		}
+	}
+}
+
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	monitor := NewMonitor(100)
+	slow := monitor.Subscribe(2)
+	fast := monitor.Subscribe(100)
+
+	for i := 0; i < 10; i++ {
+		monitor.addToHistory(ClipboardEntry{Content: fmt.Sprintf("entry %d", i)})
+	}
+
+	if got := slow.Dropped(); got != 8 {
+		t.Errorf("Expected 8 dropped events, got %d", got)
+	}
+	if got := fast.Dropped(); got != 0 {
+		t.Errorf("Expected fast subscriber to drop nothing, got %d", got)
+	}
+
+	// 缓冲区中保留的是最早的事件，之后的事件可以通过 Seq 的跳跃发现丢失
+	expectEvent(t, slow, EventAdded, "entry 0")
+	expectEvent(t, slow, EventAdded, "entry 1")
+	monitor.addToHistory(ClipboardEntry{Content: "after"})
+	if event := expectEvent(t, slow, EventAdded, "after"); event.Seq != 11 {
+		t.Errorf("Expected Seq 11 after gap, got %d", event.Seq)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	monitor := NewMonitor(10)
+	sub := monitor.Subscribe(0)
+	monitor.Unsubscribe(sub)
+	monitor.Unsubscribe(sub)
+
+	if _, ok := <-sub.C; ok {
+		t.Error("Expected channel to be closed after Unsubscribe")
+	}
+	// 取消订阅后发布事件不会出错
+	monitor.addToHistory(ClipboardEntry{Content: "ignored"})
+}
+
+func TestSubscribeFunc(t *testing.T) {
+	monitor := NewMonitor(10)
+	received := make(chan Event, 10)
+	sub := monitor.SubscribeFunc(0, func(event Event) {
+		received <- event
+	})
+	defer monitor.Unsubscribe(sub)
+
+	monitor.addToHistory(ClipboardEntry{Content: "one"})
+	monitor.ClearHistory()
+
+	for _, want := range []EventType{EventAdded, EventCleared} {
+		select {
+		case event := <-received:
+			if event.Type != want {
+				t.Errorf("Expected %s, got %s", want, event.Type)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func TestAttachStorePublishesReload(t *testing.T) {
+	monitor := NewMonitor(10)
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	if err := monitor.AttachStore(&memoryStore{}); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent(t, sub, EventReloaded, "")
+	monitor.DetachStore()
+	expectEvent(t, sub, EventCleared, "")
+}
@@ -0,0 +1,181 @@
+package clipboard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 常用剪贴板 MIME 类型
+const (
+	MIMEText    = "text/plain"
+	MIMEHTML    = "text/html"
+	MIMERTF     = "text/rtf"
+	MIMEPNG     = "image/png"
+	MIMEURIList = "text/uri-list"
+)
+
+// FormatBackend 可选接口，支持读写多种剪贴板格式的后端实现该接口。
+// 返回的映射中纯文本使用 MIMEText 作为键。
+type FormatBackend interface {
+	ReadFormats() (map[string][]byte, error)
+	WriteFormats(formats map[string][]byte) error
+}
+
+// NewEntry 根据剪贴板各格式的数据创建条目，纯文本保存在 Content 中，
+// 没有纯文本时使用文件列表的路径作为主要文本表示
+func NewEntry(formats map[string][]byte) ClipboardEntry {
+	var entry ClipboardEntry
+	for mime, data := range formats {
+		if len(data) == 0 {
+			continue
+		}
+		if mime == MIMEText {
+			entry.Content = string(data)
+			continue
+		}
+		if entry.Formats == nil {
+			entry.Formats = make(map[string][]byte)
+		}
+		entry.Formats[mime] = data
+	}
+	if entry.Content == "" {
+		if uris, ok := entry.Formats[MIMEURIList]; ok {
+			entry.Content = strings.Join(ParseURIList(uris), "\n")
+		}
+	}
+	return entry
+}
+
+// IsEmpty 条目是否不包含任何内容
+func (e ClipboardEntry) IsEmpty() bool {
+	return e.Content == "" && len(e.Formats) == 0
+}
+
+// AllFormats 返回包含纯文本在内的全部格式数据，用于写回剪贴板
+func (e ClipboardEntry) AllFormats() map[string][]byte {
+	formats := make(map[string][]byte, len(e.Formats)+1)
+	for mime, data := range e.Formats {
+		formats[mime] = data
+	}
+	if e.Content != "" {
+		formats[MIMEText] = []byte(e.Content)
+	}
+	return formats
+}
+
+// Key 条目去重使用的键：有文本时为文本本身，否则为各格式数据的摘要
+func (e ClipboardEntry) Key() string {
+	if e.Content != "" {
+		return e.Content
+	}
+
+	mimes := make([]string, 0, len(e.Formats))
+	for mime := range e.Formats {
+		mimes = append(mimes, mime)
+	}
+	sort.Strings(mimes)
+
+	h := sha256.New()
+	for _, mime := range mimes {
+		h.Write([]byte(mime))
+		h.Write([]byte{0})
+		h.Write(e.Formats[mime])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// ParseURIList 解析 text/uri-list 数据，file:// 地址转换为本地路径
+func ParseURIList(data []byte) []string {
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if u, err := url.Parse(line); err == nil && u.Scheme == "file" {
+			line = fileURIPath(u)
+		}
+		paths = append(paths, line)
+	}
+	return paths
+}
+
+// fileURIPath 把 file:// 地址转换为本地路径：file:///C:/a 转换为 C:\a，
+// 带主机名的地址（file://server/share）转换为 UNC 路径 \\server\share
+func fileURIPath(u *url.URL) string {
+	path := u.Path
+	switch {
+	case u.Host != "" && u.Host != "localhost":
+		return `\\` + u.Host + strings.ReplaceAll(path, "/", `\`)
+	case len(path) >= 3 && path[0] == '/' && path[2] == ':':
+		return strings.ReplaceAll(path[1:], "/", `\`)
+	}
+	return path
+}
+
+// formatCache 保存 ReadFormats 上次读取的结果。key 标识读取时剪贴板的状态
+// （如系统的变化计数），状态未变时直接返回上次的结果，避免每次轮询都重新读取全部格式。
+// key 为空表示无法判断剪贴板是否变化，不使用缓存。
+type formatCache struct {
+	mu      sync.Mutex
+	key     string
+	formats map[string][]byte
+}
+
+// get 返回 key 对应的缓存结果
+func (c *formatCache) get(key string) (map[string][]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key == "" || key != c.key {
+		return nil, false
+	}
+	formats := make(map[string][]byte, len(c.formats))
+	for mime, data := range c.formats {
+		formats[mime] = data
+	}
+	return formats, true
+}
+
+// put 记录 key 对应的读取结果
+func (c *formatCache) put(key string, formats map[string][]byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = key
+	c.formats = make(map[string][]byte, len(formats))
+	for mime, data := range formats {
+		c.formats[mime] = data
+	}
+}
+
+// droppedFormats 返回 formats 中未写回（不在 written 中）的格式，按名称排序
+func droppedFormats(formats map[string][]byte, written ...string) []string {
+	var dropped []string
+	for mime := range formats {
+		if !containsString(written, mime) {
+			dropped = append(dropped, mime)
+		}
+	}
+	sort.Strings(dropped)
+	return dropped
+}
+
+// logDropped 只能写回一种格式的后端用它记录未写回的格式
+func logDropped(formats map[string][]byte, written string) {
+	if dropped := droppedFormats(formats, written); len(dropped) > 0 {
+		log.Printf("当前剪贴板后端只能写回一种格式（%s），未写回: %s", written, strings.Join(dropped, ", "))
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// richFormats 需要额外读取的富文本格式
+var richFormats = []string{MIMEHTML, MIMERTF, MIMEPNG, MIMEURIList}
+
+// formatTool 描述用于读写指定格式的命令行工具
+type formatTool struct {
+	list  []string
+	stamp []string // 输出选区的获取时间，所有者变化时随之变化；为空表示工具不支持
+	read  func(mime string) []string
+	write func(mime string) []string
+}
+
+var (
+	wlTool = formatTool{
+		list:  []string{"wl-paste", "--list-types"},
+		read:  func(mime string) []string { return []string{"wl-paste", "--no-newline", "--type", mime} },
+		write: func(mime string) []string { return []string{"wl-copy", "--type", mime} },
+	}
+	xclipTool = formatTool{
+		list:  []string{"xclip", "-selection", "clipboard", "-o", "-t", "TARGETS"},
+		stamp: []string{"xclip", "-selection", "clipboard", "-o", "-t", "TIMESTAMP"},
+		read:  func(mime string) []string { return []string{"xclip", "-selection", "clipboard", "-o", "-t", mime} },
+		write: func(mime string) []string { return []string{"xclip", "-selection", "clipboard", "-i", "-t", mime} },
+	}
+)
+
+// detectFormatTool 根据会话类型选择可用的工具
+func detectFormatTool() (formatTool, bool) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if _, err := exec.LookPath("wl-paste"); err == nil {
+			return wlTool, true
+		}
+	}
+	if _, err := exec.LookPath("xclip"); err == nil {
+		return xclipTool, true
+	}
+	return formatTool{}, false
+}
+
+// ReadFormats 读取剪贴板文本以及 HTML、RTF、PNG 图片和文件列表
+//
+// 每种格式都要启动一次命令行工具，因此只在剪贴板变化后才列出和读取其他格式：
+// 有文本时以文本判断（条目本身按文本去重），没有文本时以选区的获取时间判断。
+// 工具不支持获取时间（wl-paste）时无法判断，没有文本的内容每次都会重新读取。
+func (b *SystemBackend) ReadFormats() (map[string][]byte, error) {
+	formats := make(map[string][]byte)
+	text, textErr := clipboard.ReadAll()
+	if textErr == nil && text != "" {
+		formats[MIMEText] = []byte(text)
+	}
+
+	tool, ok := detectFormatTool()
+	if !ok {
+		return formats, textErr
+	}
+
+	key := "text:" + text
+	if text == "" {
+		key = ""
+		if tool.stamp != nil {
+			if stamp, err := exec.Command(tool.stamp[0], tool.stamp[1:]...).Output(); err == nil && len(stamp) > 0 {
+				key = "stamp:" + string(stamp)
+			}
+		}
+	}
+	if cached, ok := b.formats.get(key); ok {
+		return cached, nil
+	}
+
+	out, err := exec.Command(tool.list[0], tool.list[1:]...).Output()
+	if err != nil {
+		return formats, textErr
+	}
+	targets := strings.Fields(string(out))
+	for _, mime := range richFormats {
+		if !containsString(targets, mime) {
+			continue
+		}
+		args := tool.read(mime)
+		data, err := exec.Command(args[0], args[1:]...).Output()
+		if err == nil && len(data) > 0 {
+			formats[mime] = data
+		}
+	}
+	b.formats.put(key, formats)
+	return formats, nil
+}
+
+// WriteFormats 写回条目内容。命令行工具一次只能提供一种格式，
+// 因此优先写入纯文本，没有文本时依次尝试图片、文件列表、HTML 和 RTF，
+// 未写回的格式记录到日志。X11Backend 和 WaylandBackend 会同时写回全部格式。
+func (b *SystemBackend) WriteFormats(formats map[string][]byte) error {
+	if text, ok := formats[MIMEText]; ok {
+		if err := clipboard.WriteAll(string(text)); err != nil {
+			return err
+		}
+		logDropped(formats, MIMEText)
+		return nil
+	}
+
+	tool, ok := detectFormatTool()
+	if !ok {
+		return errors.New("no clipboard tool available for rich formats")
+	}
+	for _, mime := range []string{MIMEPNG, MIMEURIList, MIMEHTML, MIMERTF} {
+		data, ok := formats[mime]
+		if !ok {
+			continue
+		}
+		args := tool.write(mime)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = bytes.NewReader(data)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+		logDropped(formats, mime)
+		return nil
+	}
+	return errors.New("no supported format to write")
+}
@@ -0,0 +1,32 @@
+//go:build !linux && !windows
+
+package clipboard
+
+import (
+	"errors"
+
+	"github.com/atotto/clipboard"
+)
+
+// ReadFormats 读取剪贴板格式。macOS 等平台目前仅支持纯文本，
+// 富文本、图片和文件列表只在 Linux 和 Windows 上读取。
+func (b *SystemBackend) ReadFormats() (map[string][]byte, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{MIMEText: []byte(text)}, nil
+}
+
+// WriteFormats 写回条目内容（当前平台仅支持纯文本），其他格式记录到日志
+func (b *SystemBackend) WriteFormats(formats map[string][]byte) error {
+	text, ok := formats[MIMEText]
+	if !ok {
+		return errors.New("only plain text can be written on this platform")
+	}
+	if err := clipboard.WriteAll(string(text)); err != nil {
+		return err
+	}
+	logDropped(formats, MIMEText)
+	return nil
+}
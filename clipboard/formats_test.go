@@ -0,0 +1,114 @@
+package clipboard
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewEntry(t *testing.T) {
+	entry := NewEntry(map[string][]byte{
+		MIMEText: []byte("hello"),
+		MIMEHTML: []byte("<b>hello</b>"),
+		MIMEPNG:  nil,
+	})
+	if entry.Content != "hello" {
+		t.Errorf("Expected content 'hello', got '%s'", entry.Content)
+	}
+	if len(entry.Formats) != 1 || string(entry.Formats[MIMEHTML]) != "<b>hello</b>" {
+		t.Errorf("Unexpected formats: %v", entry.Formats)
+	}
+	if entry.Key() != "hello" {
+		t.Errorf("Text entries should be keyed by content, got %s", entry.Key())
+	}
+
+	files := NewEntry(map[string][]byte{
+		MIMEURIList: []byte("# comment\r\nfile:///home/user/a%20b.txt\r\nfile:///tmp/c\r\n"),
+	})
+	if files.Content != "/home/user/a b.txt\n/tmp/c" {
+		t.Errorf("Unexpected file list content: %q", files.Content)
+	}
+}
+
+func TestImageEntryKey(t *testing.T) {
+	a := NewEntry(map[string][]byte{MIMEPNG: []byte("image-a")})
+	b := NewEntry(map[string][]byte{MIMEPNG: []byte("image-b")})
+	if a.IsEmpty() {
+		t.Fatal("Image entry should not be empty")
+	}
+	if a.Key() == b.Key() {
+		t.Error("Different images should have different keys")
+	}
+	if a.Key() != NewEntry(map[string][]byte{MIMEPNG: []byte("image-a")}).Key() {
+		t.Error("Same image should have the same key")
+	}
+}
+
+func TestCaptureAndWriteRichFormats(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+
+	entries := make(chan ClipboardEntry, 10)
+	monitor.SetOnNewContent(func(entry ClipboardEntry) {
+		entries <- entry
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Start(ctx)
+
+	// 截图只有图片格式，不应被当作空内容跳过
+	backend.SetFormats(map[string][]byte{MIMEPNG: []byte("png data")})
+	image := waitForEntry(t, entries)
+	if string(image.Formats[MIMEPNG]) != "png data" {
+		t.Fatalf("Expected captured image, got %+v", image)
+	}
+
+	backend.SetFormats(map[string][]byte{
+		MIMEText: []byte("table"),
+		MIMEHTML: []byte("<table></table>"),
+	})
+	table := waitForEntry(t, entries)
+
+	if err := monitor.CopyEntryToClipboard(table); err != nil {
+		t.Fatal(err)
+	}
+	formats, _ := backend.ReadFormats()
+	if string(formats[MIMEText]) != "table" || string(formats[MIMEHTML]) != "<table></table>" {
+		t.Errorf("Expected all formats written back, got %v", formats)
+	}
+}
+
+func TestDroppedFormats(t *testing.T) {
+	formats := map[string][]byte{MIMEText: nil, MIMEHTML: nil, MIMEPNG: nil}
+	got := droppedFormats(formats, MIMEText)
+	if len(got) != 2 || got[0] != MIMEPNG || got[1] != MIMEHTML {
+		t.Errorf("Unexpected dropped formats: %v", got)
+	}
+	if got := droppedFormats(map[string][]byte{MIMEText: nil}, MIMEText); got != nil {
+		t.Errorf("Expected nothing dropped, got %v", got)
+	}
+	if got := droppedFormats(formats, MIMEText, MIMEPNG); len(got) != 1 || got[0] != MIMEHTML {
+		t.Errorf("Unexpected dropped formats: %v", got)
+	}
+}
+
+func TestFormatCache(t *testing.T) {
+	var c formatCache
+	c.put("1", map[string][]byte{MIMEText: []byte("a")})
+	got, ok := c.get("1")
+	if !ok || string(got[MIMEText]) != "a" {
+		t.Fatalf("Expected cached formats, got %v, %v", got, ok)
+	}
+	// 返回的是副本，修改不影响缓存
+	delete(got, MIMEText)
+	if again, _ := c.get("1"); len(again) != 1 {
+		t.Error("Cache modified through returned map")
+	}
+	if _, ok := c.get("2"); ok {
+		t.Error("Expected miss after clipboard change")
+	}
+	c.put("", map[string][]byte{MIMEText: []byte("b")})
+	if _, ok := c.get(""); ok {
+		t.Error("Empty key should never hit")
+	}
+}
@@ -0,0 +1,239 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	procOpenClipboard              = user32.NewProc("OpenClipboard")
+	procCloseClipboard             = user32.NewProc("CloseClipboard")
+	procEmptyClipboard             = user32.NewProc("EmptyClipboard")
+	procGetClipboardData           = user32.NewProc("GetClipboardData")
+	procSetClipboardData           = user32.NewProc("SetClipboardData")
+	procGetClipboardSequenceNumber = user32.NewProc("GetClipboardSequenceNumber")
+	procRegisterClipboardFormatW   = user32.NewProc("RegisterClipboardFormatW")
+	procGlobalAlloc                = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree                 = kernel32.NewProc("GlobalFree")
+	procGlobalLock                 = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock               = kernel32.NewProc("GlobalUnlock")
+	procGlobalSize                 = kernel32.NewProc("GlobalSize")
+)
+
+// 标准剪贴板格式和全局内存分配标志
+const (
+	CF_DIB         = 8
+	CF_UNICODETEXT = 13
+	CF_HDROP       = 15
+	GMEM_MOVEABLE  = 0x0002
+)
+
+// 由程序注册的剪贴板格式名称
+const (
+	formatNameHTML = "HTML Format"
+	formatNameRTF  = "Rich Text Format"
+	formatNamePNG  = "PNG"
+)
+
+// ReadFormats 读取剪贴板文本以及 HTML、RTF、图片和文件列表。
+// 图片优先读取浏览器等程序提供的 PNG，没有时把 CF_DIB 位图转换为 PNG；
+// 文件列表由 CF_HDROP 转换为 text/uri-list。
+// 剪贴板的变化计数不变时直接返回上次的结果，避免每次轮询都重新转换图片。
+func (b *SystemBackend) ReadFormats() (map[string][]byte, error) {
+	seq, _, _ := procGetClipboardSequenceNumber.Call()
+	key := ""
+	if seq != 0 {
+		key = strconv.FormatUint(uint64(seq), 10)
+	}
+	if cached, ok := b.formats.get(key); ok {
+		return cached, nil
+	}
+
+	// 打开和关闭剪贴板必须在同一个线程上
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return nil, err
+	}
+	defer procCloseClipboard.Call()
+
+	formats := make(map[string][]byte)
+	if data := clipboardData(CF_UNICODETEXT); data != nil {
+		if text := decodeUTF16(data, false); text != "" {
+			formats[MIMEText] = []byte(text)
+		}
+	}
+	if data := registeredData(formatNameHTML); data != nil {
+		if html, err := decodeCFHTML(data); err == nil && len(html) > 0 {
+			formats[MIMEHTML] = html
+		}
+	}
+	if data := registeredData(formatNameRTF); data != nil {
+		formats[MIMERTF] = []byte(strings.TrimRight(string(data), "\x00"))
+	}
+	if data := registeredData(formatNamePNG); data != nil {
+		formats[MIMEPNG] = data
+	} else if data := clipboardData(CF_DIB); data != nil {
+		if converted, err := dibToPNG(data); err == nil {
+			formats[MIMEPNG] = converted
+		} else {
+			log.Printf("无法转换剪贴板中的位图: %v", err)
+		}
+	}
+	if data := clipboardData(CF_HDROP); data != nil {
+		if paths, err := parseDropFiles(data); err == nil && len(paths) > 0 {
+			formats[MIMEURIList] = filesToURIList(paths)
+		}
+	}
+
+	b.formats.put(key, formats)
+	return formats, nil
+}
+
+// WriteFormats 同时写回条目的全部格式：文本、CF_HTML、RTF，
+// 图片同时以 PNG 和 CF_DIB 提供，文件列表以 CF_HDROP 提供
+func (b *SystemBackend) WriteFormats(formats map[string][]byte) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := openClipboard(); err != nil {
+		return err
+	}
+	defer procCloseClipboard.Call()
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("failed to empty clipboard: %v", err)
+	}
+
+	var written []string
+	for mime, data := range formats {
+		if len(data) == 0 {
+			continue
+		}
+		var err error
+		switch mime {
+		case MIMEText:
+			err = setClipboardData(CF_UNICODETEXT, encodeUTF16(string(data)))
+		case MIMEHTML:
+			err = setRegisteredData(formatNameHTML, encodeCFHTML(data))
+		case MIMERTF:
+			err = setRegisteredData(formatNameRTF, data)
+		case MIMEPNG:
+			err = setRegisteredData(formatNamePNG, data)
+			if err == nil {
+				// 画图等程序只识别位图
+				if dib, dibErr := pngToDIB(data); dibErr == nil {
+					err = setClipboardData(CF_DIB, dib)
+				}
+			}
+		case MIMEURIList:
+			paths := uriListToFiles(data)
+			if len(paths) == 0 {
+				continue
+			}
+			err = setClipboardData(CF_HDROP, buildDropFiles(paths))
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		written = append(written, mime)
+	}
+	if dropped := droppedFormats(formats, written...); len(dropped) > 0 {
+		log.Printf("剪贴板不支持以下格式，未写回: %s", strings.Join(dropped, ", "))
+	}
+	return nil
+}
+
+// openClipboard 打开剪贴板，其他程序正占用时稍后重试
+func openClipboard() error {
+	var err error
+	for i := 0; i < 10; i++ {
+		r, _, callErr := procOpenClipboard.Call(0)
+		if r != 0 {
+			return nil
+		}
+		err = callErr
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("failed to open clipboard: %v", err)
+}
+
+// registerFormat 返回注册格式的编号，名称相同的格式总是返回同一个编号
+func registerFormat(name string) (uintptr, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	format, _, err := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(p)))
+	if format == 0 {
+		return 0, fmt.Errorf("failed to register clipboard format %s: %v", name, err)
+	}
+	return format, nil
+}
+
+// clipboardData 复制剪贴板中指定格式的数据，剪贴板没有该格式时返回 nil
+func clipboardData(format uintptr) []byte {
+	h, _, _ := procGetClipboardData.Call(format)
+	if h == 0 {
+		return nil
+	}
+	p, _, _ := procGlobalLock.Call(h)
+	if p == 0 {
+		return nil
+	}
+	defer procGlobalUnlock.Call(h)
+	size, _, _ := procGlobalSize.Call(h)
+	return append([]byte(nil), globalMemory(p, int(size))...)
+}
+
+// registeredData 读取注册格式的数据
+func registeredData(name string) []byte {
+	format, err := registerFormat(name)
+	if err != nil {
+		return nil
+	}
+	return clipboardData(format)
+}
+
+// setClipboardData 把数据复制到新分配的全局内存并放入剪贴板，成功后内存归剪贴板所有
+func setClipboardData(format uintptr, data []byte) error {
+	h, _, err := procGlobalAlloc.Call(GMEM_MOVEABLE, uintptr(len(data)))
+	if h == 0 {
+		return fmt.Errorf("failed to allocate clipboard memory: %v", err)
+	}
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("failed to lock clipboard memory: %v", err)
+	}
+	copy(globalMemory(p, len(data)), data)
+	procGlobalUnlock.Call(h)
+
+	if r, _, err := procSetClipboardData.Call(format, h); r == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("failed to set clipboard data: %v", err)
+	}
+	return nil
+}
+
+// setRegisteredData 以注册格式写入数据
+func setRegisteredData(name string, data []byte) error {
+	format, err := registerFormat(name)
+	if err != nil {
+		return err
+	}
+	return setClipboardData(format, data)
+}
+
+// globalMemory 把 GlobalLock 返回的地址作为字节切片访问
+func globalMemory(p uintptr, size int) []byte {
+	return unsafe.Slice((*byte)(unsafe.Add(unsafe.Pointer(nil), p)), size)
+}
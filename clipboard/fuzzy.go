@@ -0,0 +1,317 @@
+package clipboard
+
+import (
+	"sort"
+	"unicode"
+
+	"clipboard-monitor/pinyin"
+)
+
+// 模糊匹配评分参数，与 fzf 的取值一致
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyScoreGapStart     = -3
+	fuzzyScoreGapExtension = -1
+
+	// 单词开头的匹配加分，空白之后的开头比标点之后的更高
+	fuzzyBonusBoundary      = fuzzyScoreMatch / 2
+	fuzzyBonusBoundaryWhite = fuzzyBonusBoundary + 2
+	// 驼峰（小写后的大写）或字母与数字交界处的加分
+	fuzzyBonusCamel = fuzzyBonusBoundary - 1
+	// 连续匹配至少获得的加分，抵消一次间隔的扣分
+	fuzzyBonusConsecutive = -(fuzzyScoreGapStart + fuzzyScoreGapExtension)
+	// 模式第一个字符的加分倍数
+	fuzzyBonusFirstCharMultiplier = 2
+
+	// 只在条目开头的这么多字符内匹配，避免超长条目拖慢快速选择
+	fuzzyMaxRunes = 4096
+)
+
+// FuzzyMatch 模糊匹配结果
+type FuzzyMatch struct {
+	Entry     ClipboardEntry `json:"entry"`
+	Score     int            `json:"score"`
+	Positions []int          `json:"positions"` // 匹配字符在 Content 中的字符（rune）偏移，升序
+}
+
+// charClass 字符类别，用于判断单词边界
+type charClass int
+
+const (
+	classWhite charClass = iota
+	classNonWord
+	classLower
+	classUpper
+	classLetter // 没有大小写的文字，如中文
+	classNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case unicode.IsSpace(r):
+		return classWhite
+	case unicode.IsLower(r):
+		return classLower
+	case unicode.IsUpper(r):
+		return classUpper
+	case unicode.IsLetter(r):
+		return classLetter
+	case unicode.IsDigit(r):
+		return classNumber
+	default:
+		return classNonWord
+	}
+}
+
+// bonusFor 根据前一个字符和当前字符的类别计算位置加分
+func bonusFor(prev, class charClass) int {
+	if class > classNonWord {
+		switch prev {
+		case classWhite:
+			return fuzzyBonusBoundaryWhite
+		case classNonWord:
+			return fuzzyBonusBoundary
+		}
+	}
+	if prev == classLower && class == classUpper ||
+		prev != classNumber && class == classNumber {
+		return fuzzyBonusCamel
+	}
+	return 0
+}
+
+// fuzzyPattern 预处理后的模式
+type fuzzyPattern struct {
+	runes  []rune
+	pinyin string // 模式只包含字母时用于按拼音匹配汉字，否则为空
+}
+
+// newFuzzyPattern 折叠大小写和全角半角，忽略空白
+func newFuzzyPattern(pattern string) fuzzyPattern {
+	var p fuzzyPattern
+	letters := true
+	for _, r := range pattern {
+		r = foldRune(r)
+		if !unicode.IsSpace(r) {
+			p.runes = append(p.runes, r)
+			letters = letters && r >= 'a' && r <= 'z'
+		}
+	}
+	if letters {
+		p.pinyin = string(p.runes)
+	}
+	return p
+}
+
+// FuzzyScore 计算 pattern 作为子序列在 text 中的匹配得分，不匹配时 ok 为 false
+//
+// 大小写和全角半角不敏感。连续匹配、单词开头和驼峰处的匹配加分，间隔扣分。
+// pattern 为拼音时也可以匹配汉字，如 jtb 匹配"剪贴板"，取两种匹配中得分较高的一个。
+func FuzzyScore(pattern, text string) (score int, positions []int, ok bool) {
+	return newFuzzyPattern(pattern).bestMatch(text)
+}
+
+// bestMatch 分别按字符和拼音匹配，返回得分较高的结果
+func (p fuzzyPattern) bestMatch(text string) (int, []int, bool) {
+	score, positions, ok := p.match(text)
+	if pscore, ppositions, pok := p.matchPinyin(text); pok && (!ok || pscore > score) {
+		return pscore, ppositions, true
+	}
+	return score, positions, ok
+}
+
+// matchPinyin 从左到右贪心匹配，汉字可以匹配模式中的一段拼音或拼音首字母，其他字符逐个匹配
+func (p fuzzyPattern) matchPinyin(text string) (int, []int, bool) {
+	if p.pinyin == "" {
+		return 0, nil, false
+	}
+
+	// 第一遍不分配内存，只有用到了汉字的匹配才需要计分；没有用到汉字时与 match 相同
+	end, usedHan := pinyinWalk(p.pinyin, text, nil)
+	if end < 0 || !usedHan {
+		return 0, nil, false
+	}
+
+	runes := make([]rune, 0, end)
+	for _, r := range text {
+		if len(runes) == end {
+			break
+		}
+		runes = append(runes, r)
+	}
+	positions := make([]int, 0, len(p.runes))
+	pinyinWalk(p.pinyin, text, &positions)
+	return scorePositions(runes, positions, positions[0], end), positions, true
+}
+
+// pinyinWalk 按拼音贪心匹配 query，返回最后一个匹配字符之后的字符偏移，不能完整匹配时返回 -1；
+// positions 不为 nil 时记录匹配的字符偏移
+func pinyinWalk(query, text string, positions *[]int) (end int, usedHan bool) {
+	n := 0
+	for _, r := range text {
+		if n == fuzzyMaxRunes {
+			break
+		}
+		consumed := 0
+		if f := foldRune(r); f >= 0x80 {
+			consumed = pinyin.Consume(query, r)
+			usedHan = usedHan || consumed > 0
+		} else if byte(f) == query[0] {
+			consumed = 1
+		}
+		if consumed > 0 {
+			if positions != nil {
+				*positions = append(*positions, n)
+			}
+			query = query[consumed:]
+			if query == "" {
+				return n + 1, usedHan
+			}
+		}
+		n++
+	}
+	return -1, false
+}
+
+// match 先正向找到包含完整子序列的最短结尾，再反向收缩开头，最后在该区间内计分
+func (p fuzzyPattern) match(text string) (int, []int, bool) {
+	if len(p.runes) == 0 {
+		return 0, nil, true
+	}
+
+	// 第一遍不分配内存，只确认是否匹配并找到最短的结尾，大部分条目在这里就被排除
+	pi, start, end, n := 0, -1, -1, 0
+	for _, r := range text {
+		if n == fuzzyMaxRunes {
+			break
+		}
+		if foldRune(r) == p.runes[pi] {
+			if start < 0 {
+				start = n
+			}
+			pi++
+			if pi == len(p.runes) {
+				end = n + 1
+				break
+			}
+		}
+		n++
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	runes := make([]rune, 0, end)
+	folded := make([]rune, 0, end)
+	for _, r := range text {
+		if len(runes) == end {
+			break
+		}
+		runes = append(runes, r)
+		folded = append(folded, foldRune(r))
+	}
+
+	pi = len(p.runes) - 1
+	for i := end - 1; i >= start; i-- {
+		if folded[i] == p.runes[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	score, positions := p.score(runes, folded, start, end)
+	return score, positions, true
+}
+
+// score 在 [start, end) 区间内按从左到右的贪心匹配计分
+func (p fuzzyPattern) score(runes, folded []rune, start, end int) (int, []int) {
+	positions := make([]int, 0, len(p.runes))
+	for i := start; i < end && len(positions) < len(p.runes); i++ {
+		if folded[i] == p.runes[len(positions)] {
+			positions = append(positions, i)
+		}
+	}
+	return scorePositions(runes, positions, start, end), positions
+}
+
+// scorePositions 计算在 [start, end) 区间内匹配 positions 中各字符的得分
+func scorePositions(runes []rune, positions []int, start, end int) int {
+	score, pi, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+	prevClass := classWhite
+	if start > 0 {
+		prevClass = classOf(runes[start-1])
+	}
+
+	for i := start; i < end; i++ {
+		class := classOf(runes[i])
+		if pi < len(positions) && positions[pi] == i {
+			score += fuzzyScoreMatch
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// 连续匹配沿用这一段开头的加分
+				if bonus >= fuzzyBonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, fuzzyBonusConsecutive)
+			}
+			if pi == 0 {
+				score += bonus * fuzzyBonusFirstCharMultiplier
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pi++
+		} else {
+			if inGap {
+				score += fuzzyScoreGapExtension
+			} else {
+				score += fuzzyScoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+		prevClass = class
+	}
+	return score
+}
+
+// FuzzySearch 对历史记录做模糊匹配，固定的条目排在最前面，其余按得分排序，得分相同时较新的在前；
+// pattern 为空时按历史顺序返回，limit <= 0 表示不限制数量
+func (m *Monitor) FuzzySearch(pattern string, limit int) []FuzzyMatch {
+	p := newFuzzyPattern(pattern)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]FuzzyMatch, 0)
+	for _, entry := range m.history {
+		score, positions, ok := p.bestMatch(entry.Content)
+		if !ok {
+			continue
+		}
+		results = append(results, FuzzyMatch{Entry: entry, Score: score, Positions: positions})
+		if len(p.runes) == 0 && limit > 0 && len(results) == limit {
+			break
+		}
+	}
+
+	// 稳定排序保持历史顺序（固定的在前，然后是新的在前）作为同分时的次序
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Entry.Pinned != results[j].Entry.Pinned {
+			return results[i].Entry.Pinned
+		}
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
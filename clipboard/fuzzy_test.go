@@ -0,0 +1,149 @@
+package clipboard
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	cases := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"abc", "a_b_c", true, []int{0, 2, 4}},
+		{"ABC", "xabcx", true, []int{1, 2, 3}},
+		{"ｆｏｏ", "FooBar", true, []int{0, 1, 2}},
+		{"fb", "FooBar", true, []int{0, 3}},
+		{"剪板", "剪贴板", true, []int{0, 2}},
+		{"cba", "abc", false, nil},
+		{"", "anything", true, nil},
+		// 反向收缩后取最短的匹配区间
+		{"ab", "a--a-b", true, []int{3, 5}},
+	}
+	for _, tc := range cases {
+		_, positions, ok := FuzzyScore(tc.pattern, tc.text)
+		if ok != tc.ok || !reflect.DeepEqual(positions, tc.positions) {
+			t.Errorf("FuzzyScore(%q, %q) = %v, %v; want %v, %v", tc.pattern, tc.text, positions, ok, tc.positions, tc.ok)
+		}
+	}
+}
+
+func TestFuzzyScoreBonuses(t *testing.T) {
+	better := []struct{ pattern, high, low string }{
+		{"cfg", "cfg.yaml", "c-f-g"},                   // 连续匹配优于分散匹配
+		{"fb", "foo bar", "afxbx"},                     // 单词开头加分
+		{"gh", "getHistory", "weighted"},               // 驼峰处加分
+		{"srv", "my server", "observer"},               // 空白后的开头优于单词中间
+		{"tmp", "/tmp/file", "attempt"},                // 标点后的开头
+		{"abc", "abc and more text", "a x b y c tail"}, // 间隔越多扣分越多
+	}
+	for _, tc := range better {
+		high, _, ok1 := FuzzyScore(tc.pattern, tc.high)
+		low, _, ok2 := FuzzyScore(tc.pattern, tc.low)
+		if !ok1 || !ok2 || high <= low {
+			t.Errorf("Expected %q to score higher on %q (%d) than %q (%d)", tc.pattern, tc.high, high, tc.low, low)
+		}
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	monitor := NewMonitor(100)
+	now := time.Now()
+	for i, content := range []string{"git status", "go test ./...", "kubectl get pods", "get together"} {
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+
+	results := monitor.FuzzySearch("gt", 0)
+	var contents []string
+	for _, r := range results {
+		contents = append(contents, r.Entry.Content)
+	}
+	if len(contents) != 4 || contents[0] != "go test ./..." {
+		t.Errorf("Unexpected fuzzy ranking: %v", contents)
+	}
+
+	if results := monitor.FuzzySearch("pods", 0); len(results) != 1 || !reflect.DeepEqual(results[0].Positions, []int{12, 13, 14, 15}) {
+		t.Errorf("Unexpected result for 'pods': %+v", results)
+	}
+
+	// 空模式按历史顺序返回
+	results = monitor.FuzzySearch("", 2)
+	if len(results) != 2 || results[0].Entry.Content != "get together" || results[1].Entry.Content != "kubectl get pods" {
+		t.Errorf("Expected newest entries for empty pattern, got %+v", results)
+	}
+}
+
+func TestFuzzyPinyin(t *testing.T) {
+	cases := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"jtb", "复制到剪贴板", true, []int{3, 4, 5}},
+		{"jiantieb", "剪贴板", true, []int{0, 1, 2}},
+		{"zgyh", "中国银行", true, []int{0, 1, 2, 3}},
+		{"xingdong", "行动", true, []int{0, 1}},
+		{"hang", "银行", true, []int{1}},
+		{"gojtb", "go 剪贴板", true, []int{0, 1, 3, 4, 5}},
+		{"jtb", "复制内容", false, nil},
+	}
+	for _, tc := range cases {
+		_, positions, ok := FuzzyScore(tc.pattern, tc.text)
+		if ok != tc.ok || !reflect.DeepEqual(positions, tc.positions) {
+			t.Errorf("FuzzyScore(%q, %q) = %v, %v; want %v, %v", tc.pattern, tc.text, positions, ok, tc.positions, tc.ok)
+		}
+	}
+
+	// 拼音匹配与字符匹配一起排序
+	monitor := NewMonitor(100)
+	now := time.Now()
+	for i, content := range []string{"just the basics", "剪贴板历史记录", "天气预报"} {
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+	results := monitor.FuzzySearch("jtb", 0)
+	if len(results) != 2 || results[0].Entry.Content != "剪贴板历史记录" {
+		t.Errorf("Expected pinyin match first, got %+v", results)
+	}
+}
+
+// benchmarkMonitor 创建包含 n 条内容各异的条目的 Monitor
+func benchmarkMonitor(n int) *Monitor {
+	monitor := NewMonitor(n)
+	samples := []string{
+		"SELECT id, name FROM users WHERE created_at > '%d'",
+		"ssh deploy@server-%d.example.com -p 2222",
+		"剪贴板历史记录第 %d 条，包含一些中文内容用于测试",
+		"https://github.com/example/repo/pull/%d/files",
+		"func handleRequest%d(w http.ResponseWriter, r *http.Request) {}",
+	}
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		content := fmt.Sprintf(samples[i%len(samples)], i)
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: now.Add(time.Duration(i) * time.Millisecond)})
+	}
+	return monitor
+}
+
+func BenchmarkFuzzySearch10k(b *testing.B) {
+	monitor := benchmarkMonitor(10000)
+	for _, pattern := range []string{"ssh", "selusr", "剪贴", "hdlreq"} {
+		b.Run(pattern, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				monitor.FuzzySearch(pattern, 9)
+			}
+		})
+	}
+}
+
+func BenchmarkFuzzyScore(b *testing.B) {
+	text := "func handleRequest(w http.ResponseWriter, r *http.Request) { log.Printf(\"处理请求\") }"
+	for i := 0; i < b.N; i++ {
+		FuzzyScore("hdlreq", text)
+	}
+}
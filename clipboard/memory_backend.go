@@ -0,0 +1,116 @@
+package clipboard
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBackend 内存剪贴板后端，用于测试和无桌面环境
+type MemoryBackend struct {
+	mu      sync.Mutex
+	formats map[string][]byte
+	source  string
+	err     error
+	changed chan struct{}
+}
+
+// NewMemoryBackend 创建内存剪贴板后端
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		formats: make(map[string][]byte),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// ReadAll 读取内存中的剪贴板文本
+func (b *MemoryBackend) ReadAll() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return "", b.err
+	}
+	return string(b.formats[MIMEText]), nil
+}
+
+// WriteAll 写入文本，相当于本程序写入剪贴板
+func (b *MemoryBackend) WriteAll(text string) error {
+	b.SetContent(text)
+	return nil
+}
+
+// ReadFormats 读取全部格式
+func (b *MemoryBackend) ReadFormats() (map[string][]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, b.err
+	}
+	formats := make(map[string][]byte, len(b.formats))
+	for mime, data := range b.formats {
+		formats[mime] = data
+	}
+	return formats, nil
+}
+
+// WriteFormats 写入多种格式
+func (b *MemoryBackend) WriteFormats(formats map[string][]byte) error {
+	b.SetFormats(formats)
+	return nil
+}
+
+// SetContent 模拟其他程序复制了新的文本
+func (b *MemoryBackend) SetContent(text string) {
+	b.SetFormats(map[string][]byte{MIMEText: []byte(text)})
+}
+
+// SetFormats 模拟其他程序以多种格式复制了新内容
+func (b *MemoryBackend) SetFormats(formats map[string][]byte) {
+	b.mu.Lock()
+	b.formats = make(map[string][]byte, len(formats))
+	for mime, data := range formats {
+		b.formats[mime] = data
+	}
+	b.mu.Unlock()
+
+	// 非阻塞通知，合并连续的变化
+	select {
+	case b.changed <- struct{}{}:
+	default:
+	}
+}
+
+// SetSourceApp 设置之后复制内容的来源应用程序，为空时表示无法识别
+func (b *MemoryBackend) SetSourceApp(app string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.source = app
+}
+
+// SourceApp 实现 SourceBackend 接口
+func (b *MemoryBackend) SourceApp() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.source == "" {
+		return "", ErrSourceUnknown
+	}
+	return b.source, nil
+}
+
+// SetError 设置读取时返回的错误，传入 nil 恢复正常
+func (b *MemoryBackend) SetError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = err
+}
+
+// Watch 实现 Watcher 接口，内容变化时通知 Monitor
+func (b *MemoryBackend) Watch(ctx context.Context, notify func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.changed:
+			notify()
+		}
+	}
+}
@@ -2,37 +2,187 @@ package clipboard
 
 import (
 	"context"
-	"fmt"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
 	"sync"
 	"time"
-
-	"github.com/atotto/clipboard"
 )
 
+// DefaultPollInterval 默认剪贴板轮询间隔
+const DefaultPollInterval = 500 * time.Millisecond
+
+// ErrEntryNotFound 指定 ID 的条目不存在（可能已被删除或淘汰）
+var ErrEntryNotFound = errors.New("entry not found")
+
+// ErrNotPinned 操作要求条目已被固定
+var ErrNotPinned = errors.New("entry is not pinned")
+
 // ClipboardEntry 表示剪贴板条目
 type ClipboardEntry struct {
-	Content   string
+	ID        string            // 捕获时分配的唯一标识，内容被再次复制时保持不变
+	Content   string            // 主要文本表示
+	Formats   map[string][]byte `json:",omitempty"` // 纯文本以外的格式（MIME 类型 -> 数据）
 	Timestamp time.Time
+	Pinned    bool      // 固定的条目排在历史记录最前面，不会被淘汰或清空
+	PinOrder  int       // 在固定条目中的顺序，从 0 开始，由 Monitor 维护
+	Tags      []string  `json:",omitempty"` // 用户添加的标签（集合），带有标签的条目不会被淘汰
+	ExpiresAt time.Time // 非零时条目到期后自动删除，且不会被持久化（见 PolicyTTL）
+	SourceApp string    // 复制该内容的应用程序进程名，后端无法识别时为空
+	Preview   *Preview  `json:",omitempty"` // 超过预览阈值的条目的摘要，由 Monitor 维护，不会被持久化（见 Brief）
 }
 
 // Monitor 剪贴板监听器
 type Monitor struct {
 	mu           sync.RWMutex
 	history      []ClipboardEntry
-	lastContent  string
-	maxHistory   int
+	lastKey      string
+	retention    RetentionPolicy
 	onNewContent func(entry ClipboardEntry)
+	backend      Backend
+	fallback     Backend // 事件监听中断后改用的后端
+	pollInterval time.Duration
+	pollReset    chan struct{} // 轮询间隔变更时通知 Start 重建定时器
+	store        Store
+	events       eventBus
+	index        *searchIndex
+	filter       *SensitiveFilter
+	appRules     []AppRule
+	paused       bool        // 暂停记录，见 Pause
+	pausedUntil  time.Time   // 隐身模式自动恢复的时间
+	resumeTimer  *time.Timer // 隐身模式到期时恢复记录
+
+	retentionInterval time.Duration // 定期执行保留策略的间隔
+	previewThreshold  int64         // 超过该大小的条目生成预览
+}
+
+// Option Monitor 配置选项
+type Option func(*Monitor)
+
+// WithBackend 指定剪贴板后端，默认使用系统剪贴板
+func WithBackend(backend Backend) Option {
+	return func(m *Monitor) {
+		m.backend = backend
+	}
+}
+
+// WithFallbackBackend 指定事件监听中断后改用的轮询后端，默认使用系统剪贴板
+func WithFallbackBackend(backend Backend) Option {
+	return func(m *Monitor) {
+		m.fallback = backend
+	}
+}
+
+// WithPollInterval 指定轮询间隔，仅在后端不支持 Watcher 时生效
+func WithPollInterval(interval time.Duration) Option {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.pollInterval = interval
+		}
+	}
+}
+
+// WithStore 指定历史记录持久化存储，创建时会从中加载历史记录
+func WithStore(store Store) Option {
+	return func(m *Monitor) {
+		m.store = store
+	}
+}
+
+// WithSensitiveFilter 指定敏感内容过滤器，为 nil 时不检测
+func WithSensitiveFilter(filter *SensitiveFilter) Option {
+	return func(m *Monitor) {
+		m.filter = filter
+	}
 }
 
-// NewMonitor 创建新的剪贴板监听器
-func NewMonitor(maxHistory int) *Monitor {
-	return &Monitor{
-		history:    make([]ClipboardEntry, 0),
-		maxHistory: maxHistory,
+// NewMonitor 创建新的剪贴板监听器，最多保留 maxHistory 条记录，其他限制见 WithRetention
+func NewMonitor(maxHistory int, opts ...Option) *Monitor {
+	m := &Monitor{
+		history:      make([]ClipboardEntry, 0),
+		retention:    RetentionPolicy{MaxEntries: maxHistory},
+		backend:      NewSystemBackend(),
+		fallback:     NewSystemBackend(),
+		pollInterval: DefaultPollInterval,
+		pollReset:    make(chan struct{}, 1),
+		index:        newSearchIndex(),
+
+		retentionInterval: DefaultRetentionInterval,
+		previewThreshold:  DefaultPreviewThreshold,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.store != nil {
+		store := m.store
+		m.store = nil
+		if err := m.AttachStore(store); err != nil {
+			log.Printf("加载历史记录失败: %v", err)
+		}
+	}
+	return m
+}
+
+// AttachStore 挂载持久化存储：加载其中的历史记录，并把当前内存中的条目合并写入
+func (m *Monitor) AttachStore(store Store) error {
+	_, err := m.SwapStore(func() (Store, error) { return store, nil })
+	return err
+}
+
+// SwapStore 在持有锁的情况下调用 open 打开新的存储并像 AttachStore 一样挂载，
+// 返回被替换的存储。open 执行期间旧的存储不会再收到写入，可以安全地改写同一份数据；
+// open 或加载失败时保留原来的存储。
+func (m *Monitor) SwapStore(open func() (Store, error)) (Store, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	store, err := open()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := m.store
+	pending := m.history
+	m.store = store
+	m.history = entries
+	for i := range m.history {
+		if m.history[i].ID == "" {
+			m.history[i].ID = NewEntryID()
+		}
+		m.history[i] = m.summarize(m.history[i])
 	}
+	m.sortPinned()
+	m.enforceRetention()
+
+	// 挂载前捕获的条目比存储中的更新，按从旧到新的顺序合并
+	for i := len(pending) - 1; i >= 0; i-- {
+		m.addToHistory(pending[i])
+	}
+	if len(m.history) > 0 && m.lastKey == "" {
+		m.lastKey = m.history[0].Key()
+	}
+	m.changed(EventReloaded, ClipboardEntry{})
+	return previous, nil
 }
 
-// SetOnNewContent 设置新内容回调函数
+// DetachStore 卸载持久化存储并清空内存中的历史记录，已持久化的数据不受影响
+func (m *Monitor) DetachStore() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = nil
+	m.history = make([]ClipboardEntry, 0)
+	m.changed(EventCleared, ClipboardEntry{})
+}
+
+// SetOnNewContent 设置新内容回调函数，每次从剪贴板捕获到变化的内容时调用
+//
+// 只能设置一个回调，需要观察全部历史记录变更时使用 Subscribe。
 func (m *Monitor) SetOnNewContent(callback func(entry ClipboardEntry)) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -41,73 +191,286 @@ func (m *Monitor) SetOnNewContent(callback func(entry ClipboardEntry)) {
 
 // Start 开始监听剪贴板
 func (m *Monitor) Start(ctx context.Context) error {
-	// 获取初始剪贴板内容
-	initialContent, err := clipboard.ReadAll()
-	if err == nil && initialContent != "" {
-		m.lastContent = initialContent
-		entry := ClipboardEntry{
-			Content:   initialContent,
-			Timestamp: time.Now(),
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// 定期删除超过保留时长的条目
+	go m.runRetention(ctx)
+
+	// 后端支持事件通知时优先使用
+	if watcher, ok := m.currentBackend().(Watcher); ok {
+		err := watcher.Watch(ctx, m.checkClipboard)
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
-		m.addToHistory(entry)
+		// 监听中断通常意味着后端的连接已断开（如 Wayland 合成器关闭了连接），
+		// 继续用它轮询也读不到内容，因此改用备用后端
+		log.Printf("剪贴板事件监听中断，改用系统剪贴板轮询: %v", err)
+		m.mu.Lock()
+		m.backend = m.fallback
+		m.mu.Unlock()
 	}
 
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(m.PollInterval())
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-m.pollReset:
+			ticker.Reset(m.PollInterval())
 		case <-ticker.C:
-			content, err := clipboard.ReadAll()
-			if err != nil {
-				continue
-			}
+			m.checkClipboard()
+		}
+	}
+}
 
-			m.mu.RLock()
-			lastContent := m.lastContent
-			m.mu.RUnlock()
+// currentBackend 返回当前使用的后端，事件监听中断后会被替换
+func (m *Monitor) currentBackend() Backend {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.backend
+}
 
-			if content != lastContent && content != "" {
-				entry := ClipboardEntry{
-					Content:   content,
-					Timestamp: time.Now(),
-				}
+// PollInterval 返回当前轮询间隔
+func (m *Monitor) PollInterval() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pollInterval
+}
 
-				m.mu.Lock()
-				m.lastContent = content
-				m.addToHistory(entry)
-				callback := m.onNewContent
-				m.mu.Unlock()
+// SetPollInterval 修改轮询间隔，正在运行的轮询会立即使用新的间隔
+func (m *Monitor) SetPollInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	m.mu.Lock()
+	m.pollInterval = interval
+	m.mu.Unlock()
 
-				if callback != nil {
-					callback(entry)
-				}
-			}
+	select {
+	case m.pollReset <- struct{}{}:
+	default:
+	}
+}
+
+// SetMaxHistory 修改最大历史记录数，超出的旧条目会被立即移除，其他保留策略不变
+func (m *Monitor) SetMaxHistory(maxHistory int) {
+	if maxHistory <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.retention.MaxEntries = maxHistory
+	m.enforceRetention()
+}
+
+// SetSensitiveFilter 替换敏感内容过滤器，只影响之后捕获的内容，为 nil 时不再检测
+func (m *Monitor) SetSensitiveFilter(filter *SensitiveFilter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filter = filter
+}
+
+// checkClipboard 读取剪贴板，内容变化且未暂停时经过来源应用程序规则和敏感内容过滤后加入历史记录并触发回调
+func (m *Monitor) checkClipboard() {
+	entry, err := m.readEntry()
+	if err != nil || entry.IsEmpty() {
+		return
+	}
+
+	key := entry.Key()
+	m.mu.Lock()
+	if key == m.lastKey {
+		m.mu.Unlock()
+		return
+	}
+	m.lastKey = key
+	// 暂停期间仍然更新 lastKey，恢复后不会补记暂停期间复制的内容
+	if m.paused {
+		m.mu.Unlock()
+		return
+	}
+	filter, rules := m.filter, m.appRules
+	tooLarge := m.retention.TooLarge(entry)
+	m.mu.Unlock()
+
+	if tooLarge {
+		log.Printf("剪贴板内容过大（%d 字节），未记录", entry.Size())
+		return
+	}
+
+	// 识别来源和检测内容可能较慢，不持有锁
+	entry.SourceApp = m.sourceApp()
+	if !AppAllowed(rules, entry.SourceApp) {
+		log.Printf("来自 %s 的剪贴板内容按规则排除，未记录", entry.SourceApp)
+		return
+	}
+	entry, ok := filter.Apply(entry)
+	if !ok {
+		log.Printf("剪贴板内容包含敏感信息，未记录")
+		return
+	}
+
+	m.mu.Lock()
+	if m.paused {
+		// 检测期间暂停了记录
+		m.mu.Unlock()
+		return
+	}
+	entry.Timestamp = time.Now()
+	entry = m.addToHistory(entry)
+	callback := m.onNewContent
+	m.mu.Unlock()
+
+	if callback != nil {
+		callback(entry)
+	}
+}
+
+// readEntry 从后端读取当前剪贴板内容，后端支持时读取全部格式
+func (m *Monitor) readEntry() (ClipboardEntry, error) {
+	backend := m.currentBackend()
+	if fb, ok := backend.(FormatBackend); ok {
+		formats, err := fb.ReadFormats()
+		if err != nil {
+			return ClipboardEntry{}, err
 		}
+		return NewEntry(formats), nil
 	}
+
+	content, err := backend.ReadAll()
+	if err != nil {
+		return ClipboardEntry{}, err
+	}
+	return ClipboardEntry{Content: content}, nil
 }
 
-// addToHistory 添加到历史记录（支持去重）
-func (m *Monitor) addToHistory(entry ClipboardEntry) {
+// addToHistory 添加到历史记录（支持去重），返回历史记录中的条目，调用方需持有锁
+func (m *Monitor) addToHistory(entry ClipboardEntry) ClipboardEntry {
 	// 查找是否已存在相同内容
+	key := entry.Key()
 	for i, existingEntry := range m.history {
-		if existingEntry.Content == entry.Content {
-			// 找到重复内容，更新时间戳并移动到顶部
+		if existingEntry.Key() == key {
+			// 找到重复内容，更新时间戳和格式数据并移动到顶部
 			m.history[i].Timestamp = entry.Timestamp
-			// 将该项移动到列表顶部
+			if len(entry.Formats) > 0 {
+				m.history[i].Formats = entry.Formats
+				m.history[i] = m.summarize(m.history[i])
+			}
+			if entry.SourceApp != "" {
+				m.history[i].SourceApp = entry.SourceApp
+			}
+			// 会过期的条目按最近一次捕获重新计时或转为普通条目；已持久化的条目保持不过期
+			persisted := m.history[i].ExpiresAt.IsZero()
+			if !persisted {
+				m.history[i].ExpiresAt = entry.ExpiresAt
+				m.scheduleExpiry(m.history[i])
+			}
 			updatedEntry := m.history[i]
+			if updatedEntry.Pinned {
+				// 固定的条目保持原来的位置，之前未持久化时需要新增
+				if persisted {
+					m.persistUpdate(updatedEntry)
+				} else {
+					m.persistPut(updatedEntry)
+				}
+				m.changed(EventUpdated, updatedEntry)
+				return updatedEntry
+			}
+			// 将该项移动到未固定条目的顶部
 			m.history = append(m.history[:i], m.history[i+1:]...)
-			m.history = append([]ClipboardEntry{updatedEntry}, m.history...)
-			return
+			m.insert(m.pinnedCount(), updatedEntry)
+			m.persistPut(updatedEntry)
+			m.changed(EventPromoted, updatedEntry)
+			// 新的格式数据可能改变了总大小
+			m.enforceRetention()
+			return updatedEntry
 		}
 	}
 
-	// 没有找到重复内容，添加新项
-	m.history = append([]ClipboardEntry{entry}, m.history...)
-	if len(m.history) > m.maxHistory {
-		m.history = m.history[:m.maxHistory]
+	// 没有找到重复内容，添加到固定条目之后；本身是固定的条目添加到固定条目的末尾
+	if entry.ID == "" {
+		entry.ID = NewEntryID()
+	}
+	pos := m.pinnedCount()
+	if entry.Pinned {
+		entry.PinOrder = pos
+	}
+	entry = m.summarize(entry)
+	m.insert(pos, entry)
+	m.persistPut(entry)
+	m.changed(EventAdded, entry)
+	m.scheduleExpiry(entry)
+	m.enforceRetention()
+	return entry
+}
+
+// scheduleExpiry 为会过期的条目设置定时删除，调用方需持有锁
+func (m *Monitor) scheduleExpiry(entry ClipboardEntry) {
+	if entry.ExpiresAt.IsZero() {
+		return
+	}
+	time.AfterFunc(time.Until(entry.ExpiresAt), func() {
+		m.expire(entry.ID, entry.ExpiresAt)
+	})
+}
+
+// expire 删除到期的条目；条目被再次捕获而重新计时后，旧的定时器不做任何操作
+func (m *Monitor) expire(id string, expiresAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 || !m.history[i].ExpiresAt.Equal(expiresAt) {
+		return
+	}
+	m.removeAt(i)
+}
+
+// changed 在历史记录变更后更新搜索索引并发布事件，调用方需持有写锁
+func (m *Monitor) changed(eventType EventType, entry ClipboardEntry) {
+	m.index.apply(eventType, entry, m.history)
+	m.publish(eventType, entry)
+}
+
+// insert 在历史记录的 pos 处插入条目，调用方需持有锁
+func (m *Monitor) insert(pos int, entry ClipboardEntry) {
+	m.history = append(m.history, ClipboardEntry{})
+	copy(m.history[pos+1:], m.history[pos:])
+	m.history[pos] = entry
+}
+
+// persistPut 持久化新增或更新的条目，会过期的条目不写入存储，调用方需持有锁
+func (m *Monitor) persistPut(entry ClipboardEntry) {
+	if m.store == nil || !entry.ExpiresAt.IsZero() {
+		return
+	}
+	if err := m.store.Put(entry); err != nil {
+		log.Printf("保存历史记录失败: %v", err)
+	}
+}
+
+// persistUpdate 持久化原位修改的条目，调用方需持有锁
+func (m *Monitor) persistUpdate(entry ClipboardEntry) {
+	if m.store == nil || !entry.ExpiresAt.IsZero() {
+		return
+	}
+	if err := m.store.Update(entry); err != nil {
+		log.Printf("更新历史记录失败: %v", err)
+	}
+}
+
+// persistDelete 持久化删除条目，调用方需持有锁
+func (m *Monitor) persistDelete(entry ClipboardEntry) {
+	if m.store == nil || !entry.ExpiresAt.IsZero() {
+		return
+	}
+	if err := m.store.Delete(entry); err != nil {
+		log.Printf("删除历史记录失败: %v", err)
 	}
 }
 
@@ -122,28 +485,155 @@ func (m *Monitor) GetHistory() []ClipboardEntry {
 	return history
 }
 
-// ClearHistory 清空历史记录
+// ClearHistory 清空历史记录，固定的条目保留
 func (m *Monitor) ClearHistory() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.history = make([]ClipboardEntry, 0)
+	pinned := m.history[:m.pinnedCount()]
+	m.history = append(make([]ClipboardEntry, 0, len(pinned)), pinned...)
+	if m.store != nil {
+		if err := m.store.Clear(); err != nil {
+			log.Printf("清空历史记录存储失败: %v", err)
+		}
+		// 存储只支持整体清空，再把固定的条目写回
+		for i := len(m.history) - 1; i >= 0; i-- {
+			m.persistPut(m.history[i])
+		}
+	}
+	m.changed(EventCleared, ClipboardEntry{})
 }
 
 // CopyToClipboard 复制内容到剪贴板
 func (m *Monitor) CopyToClipboard(content string) error {
-	return clipboard.WriteAll(content)
+	return m.currentBackend().WriteAll(content)
+}
+
+// CopyEntryToClipboard 将条目的全部格式写回剪贴板，后端不支持多格式时仅写入文本
+func (m *Monitor) CopyEntryToClipboard(entry ClipboardEntry) error {
+	backend := m.currentBackend()
+	if fb, ok := backend.(FormatBackend); ok && len(entry.Formats) > 0 {
+		return fb.WriteFormats(entry.AllFormats())
+	}
+	return backend.WriteAll(entry.Content)
+}
+
+// Entry 按 ID 查找条目
+func (m *Monitor) Entry(id string) (ClipboardEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if i := m.indexOf(id); i >= 0 {
+		return m.history[i], true
+	}
+	return ClipboardEntry{}, false
+}
+
+// Previous 返回当前剪贴板内容之前复制的条目：不是当前内容的未固定条目中时间最新的一条
+//
+// 固定的条目排在历史记录最前面，因此不能简单地取第二条。
+func (m *Monitor) Previous() (ClipboardEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var previous ClipboardEntry
+	found := false
+	for _, entry := range m.history {
+		if entry.Pinned || entry.Key() == m.lastKey {
+			continue
+		}
+		if !found || entry.Timestamp.After(previous.Timestamp) {
+			previous, found = entry, true
+		}
+	}
+	return previous, found
+}
+
+// CopyEntry 将指定 ID 的条目写回剪贴板
+func (m *Monitor) CopyEntry(id string) error {
+	entry, ok := m.Entry(id)
+	if !ok {
+		return ErrEntryNotFound
+	}
+	return m.CopyEntryToClipboard(entry)
 }
 
-// DeleteHistoryItem 删除指定索引的历史记录项
-func (m *Monitor) DeleteHistoryItem(index int) error {
+// UpdateEntry 原位修改指定 ID 的条目，ID、固定状态、过期时间和在历史记录中的位置保持不变，返回修改后的条目
+//
+// update 在持有锁时调用，不能再调用 Monitor 的方法。修改后的内容与其他条目重复时返回错误。
+func (m *Monitor) UpdateEntry(id string, update func(entry *ClipboardEntry)) (ClipboardEntry, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if index < 0 || index >= len(m.history) {
-		return fmt.Errorf("index out of range: %d", index)
+	i := m.indexOf(id)
+	if i < 0 {
+		return ClipboardEntry{}, ErrEntryNotFound
 	}
 
-	// 删除指定索引的项目
-	m.history = append(m.history[:index], m.history[index+1:]...)
+	updated := m.history[i]
+	update(&updated)
+	updated.ID = id
+	updated.Pinned, updated.PinOrder = m.history[i].Pinned, m.history[i].PinOrder
+	updated.ExpiresAt = m.history[i].ExpiresAt
+	updated = m.summarize(updated)
+	if updated.IsEmpty() {
+		return ClipboardEntry{}, errors.New("entry content is empty")
+	}
+	key := updated.Key()
+	for j, existing := range m.history {
+		if j != i && existing.Key() == key {
+			return ClipboardEntry{}, errors.New("entry with the same content already exists")
+		}
+	}
+
+	m.history[i] = updated
+	m.persistUpdate(updated)
+	m.changed(EventUpdated, updated)
+	return updated, nil
+}
+
+// DeleteEntry 删除指定 ID 的条目
+func (m *Monitor) DeleteEntry(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return ErrEntryNotFound
+	}
+	m.removeAt(i)
 	return nil
 }
+
+// removeAt 删除第 i 个条目，调用方需持有写锁
+func (m *Monitor) removeAt(i int) {
+	removed := m.history[i]
+	m.history = append(m.history[:i], m.history[i+1:]...)
+	m.persistDelete(removed)
+	m.changed(EventDeleted, removed)
+	if removed.Pinned {
+		m.renumberPinned()
+	}
+}
+
+// indexOf 返回指定 ID 的条目在历史记录中的位置，不存在时返回 -1，调用方需持有锁
+func (m *Monitor) indexOf(id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, entry := range m.history {
+		if entry.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewEntryID 生成新的条目 ID
+func NewEntryID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// 系统随机数不可用时退化为时间戳，仍能保证单进程内基本唯一
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -2,6 +2,11 @@ package clipboard
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -12,8 +17,8 @@ func TestNewMonitor(t *testing.T) {
 		t.Fatal("NewMonitor returned nil")
 	}
 
-	if monitor.maxHistory != 10 {
-		t.Errorf("Expected maxHistory to be 10, got %d", monitor.maxHistory)
+	if monitor.Retention().MaxEntries != 10 {
+		t.Errorf("Expected maxHistory to be 10, got %d", monitor.Retention().MaxEntries)
 	}
 
 	if len(monitor.history) != 0 {
@@ -160,3 +165,424 @@ func TestMonitorContext(t *testing.T) {
 		t.Errorf("Expected context.Canceled error, got %v", err)
 	}
 }
+
+// waitForEntry 等待回调收到新条目
+func waitForEntry(t *testing.T, ch <-chan ClipboardEntry) ClipboardEntry {
+	t.Helper()
+	select {
+	case entry := <-ch:
+		return entry
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for clipboard entry")
+		return ClipboardEntry{}
+	}
+}
+
+// pollingBackend 不实现 Watcher 的后端，用于测试轮询路径
+type pollingBackend struct {
+	mem *MemoryBackend
+}
+
+func (b pollingBackend) ReadAll() (string, error)   { return b.mem.ReadAll() }
+func (b pollingBackend) WriteAll(text string) error { return b.mem.WriteAll(text) }
+
+// brokenWatcher 事件监听立即失败的后端，模拟连接已断开
+type brokenWatcher struct {
+	pollingBackend
+}
+
+func (b brokenWatcher) Watch(ctx context.Context, notify func()) error {
+	return errors.New("connection closed")
+}
+
+func TestStartFallsBackAfterWatchFails(t *testing.T) {
+	fallback := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(brokenWatcher{pollingBackend{NewMemoryBackend()}}),
+		WithFallbackBackend(fallback), WithPollInterval(5*time.Millisecond))
+
+	entries := make(chan ClipboardEntry, 10)
+	monitor.SetOnNewContent(func(entry ClipboardEntry) {
+		entries <- entry
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Start(ctx)
+
+	// 监听失败后从备用后端轮询
+	fallback.SetContent("after failure")
+	if got := waitForEntry(t, entries).Content; got != "after failure" {
+		t.Errorf("Expected 'after failure', got '%s'", got)
+	}
+	if err := monitor.CopyToClipboard("written"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := fallback.ReadAll(); got != "written" {
+		t.Errorf("Expected writes to go to the fallback backend, got %q", got)
+	}
+}
+
+func TestStartWithMemoryBackend(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+
+	entries := make(chan ClipboardEntry, 10)
+	monitor.SetOnNewContent(func(entry ClipboardEntry) {
+		entries <- entry
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- monitor.Start(ctx) }()
+
+	// 每次变化后等待回调，确认检查已完成再复制下一项，连续的变化不会被合并成一次检查
+	for _, content := range []string{"initial", "second", "initial", "third"} {
+		backend.SetContent(content)
+		if got := waitForEntry(t, entries).Content; got != content {
+			t.Errorf("Expected '%s', got '%s'", content, got)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled error, got %v", err)
+	}
+
+	// 重复内容被去重并移动到顶部
+	history := monitor.GetHistory()
+	if got := contentsOf(history); !reflect.DeepEqual(got, []string{"third", "initial", "second"}) {
+		t.Errorf("Unexpected history: %v", got)
+	}
+}
+
+func TestStartPollingFallback(t *testing.T) {
+	backend := pollingBackend{NewMemoryBackend()}
+	monitor := NewMonitor(10, WithBackend(backend), WithPollInterval(5*time.Millisecond))
+
+	entries := make(chan ClipboardEntry, 10)
+	monitor.SetOnNewContent(func(entry ClipboardEntry) {
+		entries <- entry
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Start(ctx)
+
+	backend.mem.SetContent("polled")
+	if got := waitForEntry(t, entries).Content; got != "polled" {
+		t.Errorf("Expected 'polled', got '%s'", got)
+	}
+}
+
+func TestSetPollInterval(t *testing.T) {
+	backend := pollingBackend{NewMemoryBackend()}
+	monitor := NewMonitor(10, WithBackend(backend), WithPollInterval(time.Hour))
+
+	entries := make(chan ClipboardEntry, 10)
+	monitor.SetOnNewContent(func(entry ClipboardEntry) {
+		entries <- entry
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go monitor.Start(ctx)
+
+	// 运行中修改间隔后应立即按新间隔轮询
+	monitor.SetPollInterval(5 * time.Millisecond)
+	if got := monitor.PollInterval(); got != 5*time.Millisecond {
+		t.Errorf("Expected 5ms, got %v", got)
+	}
+	backend.mem.SetContent("after change")
+	if got := waitForEntry(t, entries).Content; got != "after change" {
+		t.Errorf("Expected 'after change', got '%s'", got)
+	}
+}
+
+func TestSetMaxHistory(t *testing.T) {
+	store := &memoryStore{}
+	monitor := NewMonitor(5, WithBackend(NewMemoryBackend()), WithStore(store))
+	for _, content := range []string{"a", "b", "c", "d", "e"} {
+		monitor.mu.Lock()
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: time.Now()})
+		monitor.mu.Unlock()
+	}
+
+	monitor.SetMaxHistory(2)
+	history := monitor.GetHistory()
+	if len(history) != 2 || history[0].Content != "e" || history[1].Content != "d" {
+		t.Errorf("Unexpected history after shrinking: %+v", history)
+	}
+	if len(store.entries) != 2 {
+		t.Errorf("Expected evicted entries to be deleted from store, got %d", len(store.entries))
+	}
+
+	// 扩大上限后可以保存更多条目
+	monitor.SetMaxHistory(3)
+	monitor.mu.Lock()
+	monitor.addToHistory(ClipboardEntry{Content: "f", Timestamp: time.Now()})
+	monitor.mu.Unlock()
+	if got := len(monitor.GetHistory()); got != 3 {
+		t.Errorf("Expected 3 entries, got %d", got)
+	}
+}
+
+func TestCopyToClipboardUsesBackend(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+
+	if err := monitor.CopyToClipboard("copied"); err != nil {
+		t.Fatalf("CopyToClipboard failed: %v", err)
+	}
+
+	content, err := backend.ReadAll()
+	if err != nil || content != "copied" {
+		t.Errorf("Expected backend to contain 'copied', got '%s' (%v)", content, err)
+	}
+}
+
+// memoryStore 测试用的内存 Store 实现
+type memoryStore struct {
+	entries []ClipboardEntry
+}
+
+func (s *memoryStore) Load() ([]ClipboardEntry, error) {
+	return append([]ClipboardEntry(nil), s.entries...), nil
+}
+
+func (s *memoryStore) Put(entry ClipboardEntry) error {
+	s.Delete(entry)
+	s.entries = append([]ClipboardEntry{entry}, s.entries...)
+	return nil
+}
+
+func (s *memoryStore) Update(entry ClipboardEntry) error {
+	for i, e := range s.entries {
+		if e.ID == entry.ID {
+			s.entries[i] = entry
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Delete(entry ClipboardEntry) error {
+	for i, e := range s.entries {
+		if e.ID == entry.ID {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Clear() error {
+	s.entries = nil
+	return nil
+}
+
+func TestAttachAndDetachStore(t *testing.T) {
+	store := &memoryStore{}
+	store.Put(ClipboardEntry{ID: "stored-id", Content: "stored", Timestamp: time.Now()})
+
+	monitor := NewMonitor(10)
+	monitor.addToHistory(ClipboardEntry{Content: "captured while locked", Timestamp: time.Now()})
+
+	if err := monitor.AttachStore(store); err != nil {
+		t.Fatalf("AttachStore failed: %v", err)
+	}
+
+	history := monitor.GetHistory()
+	if len(history) != 2 || history[0].Content != "captured while locked" || history[1].Content != "stored" {
+		t.Fatalf("Unexpected merged history: %+v", history)
+	}
+	if len(store.entries) != 2 {
+		t.Errorf("Expected pending entry to be persisted, store has %d entries", len(store.entries))
+	}
+
+	monitor.DetachStore()
+	if len(monitor.GetHistory()) != 0 {
+		t.Error("History should be empty after DetachStore")
+	}
+	if len(store.entries) != 2 {
+		t.Error("DetachStore should not modify persisted entries")
+	}
+}
+
+func TestSwapStore(t *testing.T) {
+	old := &memoryStore{}
+	monitor := NewMonitor(10, WithStore(old))
+	monitor.addToHistory(ClipboardEntry{Content: "kept", Timestamp: time.Now()})
+
+	// 打开失败时保留原来的存储
+	if _, err := monitor.SwapStore(func() (Store, error) { return nil, errors.New("open failed") }); err == nil {
+		t.Fatal("Expected open error")
+	}
+	monitor.addToHistory(ClipboardEntry{Content: "still persisted", Timestamp: time.Now()})
+	if len(old.entries) != 2 {
+		t.Fatalf("Expected old store to stay attached, it has %d entries", len(old.entries))
+	}
+
+	replacement := &memoryStore{}
+	previous, err := monitor.SwapStore(func() (Store, error) { return replacement, nil })
+	if err != nil {
+		t.Fatalf("SwapStore failed: %v", err)
+	}
+	if previous != old {
+		t.Error("Expected the replaced store to be returned")
+	}
+	if len(replacement.entries) != 2 {
+		t.Errorf("Expected history to be merged into the new store, got %d entries", len(replacement.entries))
+	}
+	monitor.addToHistory(ClipboardEntry{Content: "new", Timestamp: time.Now()})
+	if len(old.entries) != 2 || len(replacement.entries) != 3 {
+		t.Errorf("Expected writes to go to the new store only: old %d, new %d", len(old.entries), len(replacement.entries))
+	}
+}
+
+func TestEntryIDs(t *testing.T) {
+	monitor := NewMonitor(10)
+	first := monitor.addToHistory(ClipboardEntry{Content: "first", Timestamp: time.Now()})
+	second := monitor.addToHistory(ClipboardEntry{Content: "second", Timestamp: time.Now()})
+	if first.ID == "" || second.ID == "" || first.ID == second.ID {
+		t.Fatalf("Expected distinct IDs, got %q and %q", first.ID, second.ID)
+	}
+
+	// 再次复制相同内容时保留原来的 ID
+	promoted := monitor.addToHistory(ClipboardEntry{Content: "first", Timestamp: time.Now()})
+	if promoted.ID != first.ID {
+		t.Errorf("Expected promoted entry to keep ID %q, got %q", first.ID, promoted.ID)
+	}
+	if history := monitor.GetHistory(); history[0].ID != first.ID {
+		t.Errorf("Expected promoted entry at the top, got %+v", history)
+	}
+
+	entry, ok := monitor.Entry(second.ID)
+	if !ok || entry.Content != "second" {
+		t.Errorf("Entry(%q) = %+v, %v", second.ID, entry, ok)
+	}
+	if _, ok := monitor.Entry("missing"); ok {
+		t.Error("Expected unknown ID to be missing")
+	}
+}
+
+func TestDeleteEntry(t *testing.T) {
+	store := &memoryStore{}
+	monitor := NewMonitor(10, WithStore(store))
+	a := monitor.addToHistory(ClipboardEntry{Content: "a", Timestamp: time.Now()})
+	b := monitor.addToHistory(ClipboardEntry{Content: "b", Timestamp: time.Now()})
+
+	if err := monitor.DeleteEntry(a.ID); err != nil {
+		t.Fatalf("DeleteEntry failed: %v", err)
+	}
+	history := monitor.GetHistory()
+	if len(history) != 1 || history[0].ID != b.ID {
+		t.Errorf("Unexpected history after delete: %+v", history)
+	}
+	if len(store.entries) != 1 || store.entries[0].ID != b.ID {
+		t.Errorf("Expected delete to be persisted, store has %+v", store.entries)
+	}
+	if err := monitor.DeleteEntry(a.ID); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestUpdateEntry(t *testing.T) {
+	store := &memoryStore{}
+	monitor := NewMonitor(10, WithStore(store))
+	a := monitor.addToHistory(ClipboardEntry{Content: "a", Timestamp: time.Now()})
+	monitor.addToHistory(ClipboardEntry{Content: "b", Timestamp: time.Now()})
+
+	updated, err := monitor.UpdateEntry(a.ID, func(entry *ClipboardEntry) {
+		entry.Content = "edited"
+		entry.ID = "ignored"
+	})
+	if err != nil {
+		t.Fatalf("UpdateEntry failed: %v", err)
+	}
+	if updated.ID != a.ID || updated.Content != "edited" {
+		t.Errorf("Unexpected updated entry: %+v", updated)
+	}
+
+	// 修改不改变顺序
+	history := monitor.GetHistory()
+	if history[1].ID != a.ID || history[1].Content != "edited" {
+		t.Errorf("Expected entry updated in place, got %+v", history)
+	}
+	if store.entries[1].Content != "edited" {
+		t.Errorf("Expected update to be persisted, store has %+v", store.entries)
+	}
+
+	_, err = monitor.UpdateEntry(a.ID, func(entry *ClipboardEntry) { entry.Content = "b" })
+	if err == nil {
+		t.Error("Expected error when update duplicates another entry")
+	}
+	if _, err := monitor.UpdateEntry("missing", func(*ClipboardEntry) {}); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestCopyEntry(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+	entry := monitor.addToHistory(ClipboardEntry{Content: "by id", Timestamp: time.Now()})
+
+	if err := monitor.CopyEntry(entry.ID); err != nil {
+		t.Fatalf("CopyEntry failed: %v", err)
+	}
+	if content, _ := backend.ReadAll(); content != "by id" {
+		t.Errorf("Expected backend to contain 'by id', got '%s'", content)
+	}
+	if err := monitor.CopyEntry("missing"); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+// TestDeleteDuringCaptures 捕获新内容把条目不断移动位置的同时按 ID 删除，
+// 每次删除都必须命中目标条目
+func TestDeleteDuringCaptures(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(1000, WithBackend(backend))
+
+	targets := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		content := fmt.Sprintf("target %d", i)
+		entry := monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: time.Now()})
+		targets[entry.ID] = content
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			// 交替写入新内容和已有目标内容，后者会把目标条目移动到顶部
+			if i%2 == 0 {
+				backend.SetContent(fmt.Sprintf("capture %d", i))
+			} else {
+				backend.SetContent(fmt.Sprintf("target %d", i%50))
+			}
+			monitor.checkClipboard()
+		}
+	}()
+
+	for id := range targets {
+		if err := monitor.DeleteEntry(id); err != nil {
+			t.Errorf("DeleteEntry(%q) failed: %v", id, err)
+		}
+	}
+	wg.Wait()
+
+	captures := 0
+	for _, entry := range monitor.GetHistory() {
+		if content, ok := targets[entry.ID]; ok {
+			t.Errorf("Deleted entry %q (%s) is still in history", entry.ID, content)
+		}
+		if strings.HasPrefix(entry.Content, "capture") {
+			captures++
+		}
+	}
+	// 删除不能误伤其他条目
+	if captures != 250 {
+		t.Errorf("Expected 250 captured entries to survive, got %d", captures)
+	}
+}
@@ -0,0 +1,99 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/res"
+	"github.com/jezek/xgb/xproto"
+)
+
+// SourceApp 实现 SourceBackend 接口：查找最近变化的选区的所有者窗口，
+// 依次通过窗口及其 WM_CLIENT_LEADER 的 _NET_WM_PID 属性和 X-Resource 扩展确定进程
+func (b *X11Backend) SourceApp() (string, error) {
+	conn, err := xgb.NewConnDisplay(b.display)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to X display: %v", err)
+	}
+	defer conn.Close()
+
+	selection, err := internAtom(conn, b.currentSelection())
+	if err != nil {
+		return "", err
+	}
+	reply, err := xproto.GetSelectionOwner(conn, selection).Reply()
+	if err != nil {
+		return "", fmt.Errorf("failed to get selection owner: %v", err)
+	}
+	if reply.Owner == xproto.WindowNone {
+		return "", ErrSourceUnknown
+	}
+
+	pid, err := windowPID(conn, reply.Owner)
+	if err != nil {
+		return "", err
+	}
+	return processName(pid)
+}
+
+// windowPID 返回创建窗口的进程 ID
+//
+// 剪贴板的所有者通常是工具包创建的隐藏窗口，不一定设置了 _NET_WM_PID，
+// 此时尝试客户端主窗口（WM_CLIENT_LEADER），最后使用 X-Resource 查询连接对应的本地进程。
+func windowPID(conn *xgb.Conn, win xproto.Window) (uint32, error) {
+	if pid, ok := cardinalProperty(conn, win, "_NET_WM_PID"); ok && pid != 0 {
+		return pid, nil
+	}
+	if leader, ok := cardinalProperty(conn, win, "WM_CLIENT_LEADER"); ok && leader != 0 {
+		if pid, ok := cardinalProperty(conn, xproto.Window(leader), "_NET_WM_PID"); ok && pid != 0 {
+			return pid, nil
+		}
+	}
+
+	if err := res.Init(conn); err != nil {
+		return 0, ErrSourceUnknown
+	}
+	spec := res.ClientIdSpec{Client: uint32(win), Mask: res.ClientIdMaskLocalClientPID}
+	reply, err := res.QueryClientIds(conn, 1, []res.ClientIdSpec{spec}).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query client ID: %v", err)
+	}
+	for _, id := range reply.Ids {
+		if id.Spec.Mask&res.ClientIdMaskLocalClientPID != 0 && len(id.Value) > 0 {
+			return id.Value[0], nil
+		}
+	}
+	return 0, ErrSourceUnknown
+}
+
+// cardinalProperty 读取窗口上 32 位整数或窗口类型的属性
+func cardinalProperty(conn *xgb.Conn, win xproto.Window, name string) (uint32, bool) {
+	atom, err := internAtom(conn, name)
+	if err != nil {
+		return 0, false
+	}
+	reply, err := xproto.GetProperty(conn, false, win, atom, xproto.GetPropertyTypeAny, 0, 1).Reply()
+	if err != nil || reply.Format != 32 || len(reply.Value) < 4 {
+		return 0, false
+	}
+	return xgb.Get32(reply.Value), true
+}
+
+// processName 返回进程的可执行文件名，无法读取时使用 /proc/<pid>/comm（最多 15 个字符）
+func processName(pid uint32) (string, error) {
+	dir := filepath.Join("/proc", strconv.FormatUint(uint64(pid), 10))
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		return filepath.Base(strings.TrimSuffix(exe, " (deleted)")), nil
+	}
+	comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read process name of %d: %v", pid, err)
+	}
+	return strings.TrimSpace(string(comm)), nil
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestProcessName(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	name, err := processName(uint32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("processName failed: %v", err)
+	}
+	if name != filepath.Base(exe) {
+		t.Errorf("Expected %s, got %s", filepath.Base(exe), name)
+	}
+
+	if _, err := processName(0); err == nil {
+		t.Error("Expected error for invalid PID")
+	}
+}
+
+func TestX11SourceApp(t *testing.T) {
+	display := startXvfb(t)
+	backend, err := NewX11Backend(display, false)
+	if err != nil {
+		t.Fatalf("NewX11Backend failed: %v", err)
+	}
+
+	if _, err := backend.SourceApp(); err != ErrSourceUnknown {
+		t.Errorf("Expected ErrSourceUnknown without owner, got %v", err)
+	}
+
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	want, err := processName(uint32(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有 _NET_WM_PID 时通过 X-Resource 识别，本测试进程就是所有者
+	takeSelection(t, conn, SelectionClipboard)
+	if got, err := backend.SourceApp(); err != nil || got != want {
+		t.Errorf("Expected %s from X-Resource, got %q (%v)", want, got, err)
+	}
+
+	// 设置了 _NET_WM_PID 的窗口
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.CreateWindow(conn, 0, win, root, 0, 0, 1, 1, 0, xproto.WindowClassInputOnly, 0, 0, nil)
+	pidAtom, _ := internAtom(conn, "_NET_WM_PID")
+	pid := make([]byte, 4)
+	xgb.Put32(pid, uint32(os.Getpid()))
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, win, pidAtom, xproto.AtomCardinal, 32, 1, pid)
+	clipboardAtom, _ := internAtom(conn, SelectionClipboard)
+	if err := xproto.SetSelectionOwnerChecked(conn, win, clipboardAtom, xproto.TimeCurrentTime).Check(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := backend.SourceApp(); err != nil || got != want {
+		t.Errorf("Expected %s from _NET_WM_PID, got %q (%v)", want, got, err)
+	}
+}
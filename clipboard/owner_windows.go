@@ -0,0 +1,51 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                         = syscall.NewLazyDLL("user32.dll")
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procGetClipboardOwner          = user32.NewProc("GetClipboardOwner")
+	procGetWindowThreadProcessId   = user32.NewProc("GetWindowThreadProcessId")
+	procQueryFullProcessImageNameW = kernel32.NewProc("QueryFullProcessImageNameW")
+)
+
+// PROCESS_QUERY_LIMITED_INFORMATION 查询进程映像名所需的最小权限
+const PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+
+// SourceApp 实现 SourceBackend 接口：通过 GetClipboardOwner 找到所有者窗口，
+// 再由窗口所属的进程得到可执行文件名
+func (b *SystemBackend) SourceApp() (string, error) {
+	hwnd, _, _ := procGetClipboardOwner.Call()
+	if hwnd == 0 {
+		return "", ErrSourceUnknown
+	}
+
+	var pid uint32
+	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if pid == 0 {
+		return "", ErrSourceUnknown
+	}
+
+	process, err := syscall.OpenProcess(PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return "", fmt.Errorf("failed to open process %d: %v", pid, err)
+	}
+	defer syscall.CloseHandle(process)
+
+	buf := make([]uint16, syscall.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	ret, _, err := procQueryFullProcessImageNameW.Call(uintptr(process), 0,
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if ret == 0 {
+		return "", fmt.Errorf("failed to query image name of process %d: %v", pid, err)
+	}
+	return filepath.Base(syscall.UTF16ToString(buf[:size])), nil
+}
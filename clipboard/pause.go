@@ -0,0 +1,100 @@
+package clipboard
+
+import (
+	"log"
+	"time"
+)
+
+// PauseState 暂停记录的状态
+type PauseState struct {
+	Paused bool      `json:"paused"`
+	Until  time.Time `json:"until"` // 隐身模式自动恢复的时间，无限期暂停时为零值
+}
+
+// Pause 无限期暂停记录，直到调用 Resume
+//
+// 暂停期间 Monitor 仍然跟踪剪贴板内容，但不会加入历史记录；
+// 恢复后也不会补记暂停期间复制的内容。
+func (m *Monitor) Pause() {
+	m.pauseUntil(time.Time{})
+}
+
+// PauseFor 暂停记录一段时间（隐身模式），到期后自动恢复，d <= 0 时无限期暂停
+func (m *Monitor) PauseFor(d time.Duration) {
+	if d <= 0 {
+		m.Pause()
+		return
+	}
+	m.pauseUntil(time.Now().Add(d))
+}
+
+// Resume 恢复记录，未暂停时不做任何事
+func (m *Monitor) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused {
+		m.resume()
+	}
+}
+
+// IsPaused 是否暂停了记录
+func (m *Monitor) IsPaused() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.paused
+}
+
+// PauseState 返回当前的暂停状态
+func (m *Monitor) PauseState() PauseState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pauseState()
+}
+
+// pauseUntil 暂停记录，until 非零时到期后自动恢复；重复调用会替换之前的恢复时间
+func (m *Monitor) pauseUntil(until time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.resumeTimer != nil {
+		m.resumeTimer.Stop()
+		m.resumeTimer = nil
+	}
+	m.paused = true
+	m.pausedUntil = until
+	if !until.IsZero() {
+		m.resumeTimer = time.AfterFunc(time.Until(until), func() {
+			m.resumeAt(until)
+		})
+		log.Printf("已暂停记录剪贴板，将在 %s 恢复", until.Format("15:04:05"))
+	} else {
+		log.Printf("已暂停记录剪贴板")
+	}
+	m.publishState(EventPaused, m.pauseState())
+}
+
+// resumeAt 隐身模式到期时恢复记录；期间被手动恢复或重新暂停时不做任何事
+func (m *Monitor) resumeAt(until time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.paused && m.pausedUntil.Equal(until) {
+		m.resume()
+	}
+}
+
+// resume 恢复记录并发布事件，调用方需持有写锁
+func (m *Monitor) resume() {
+	if m.resumeTimer != nil {
+		m.resumeTimer.Stop()
+		m.resumeTimer = nil
+	}
+	m.paused = false
+	m.pausedUntil = time.Time{}
+	log.Printf("已恢复记录剪贴板")
+	m.publishState(EventResumed, m.pauseState())
+}
+
+// pauseState 返回当前的暂停状态，调用方需持有锁
+func (m *Monitor) pauseState() PauseState {
+	return PauseState{Paused: m.paused, Until: m.pausedUntil}
+}
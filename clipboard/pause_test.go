@@ -0,0 +1,110 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+)
+
+// expectState 读取下一个事件并检查是否为指定的暂停状态事件
+func expectState(t *testing.T, sub *Subscription, eventType EventType, paused bool) PauseState {
+	t.Helper()
+	event := nextEvent(t, sub)
+	if event.Type != eventType || event.Pause == nil || event.Pause.Paused != paused {
+		t.Fatalf("Expected %s with paused=%v, got %s %+v", eventType, paused, event.Type, event.Pause)
+	}
+	return *event.Pause
+}
+
+func TestPauseSkipsCaptures(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	backend.SetContent("before")
+	monitor.checkClipboard()
+	expectEvent(t, sub, EventAdded, "before")
+
+	monitor.Pause()
+	if !monitor.IsPaused() {
+		t.Fatal("Expected monitor to be paused")
+	}
+	if state := expectState(t, sub, EventPaused, true); !state.Until.IsZero() {
+		t.Errorf("Expected indefinite pause, got until %v", state.Until)
+	}
+
+	backend.SetContent("secret")
+	monitor.checkClipboard()
+
+	monitor.Resume()
+	expectState(t, sub, EventResumed, false)
+	if monitor.IsPaused() {
+		t.Fatal("Expected monitor to be resumed")
+	}
+
+	// 恢复后不会补记暂停期间复制的内容
+	monitor.checkClipboard()
+	backend.SetContent("after")
+	monitor.checkClipboard()
+	expectEvent(t, sub, EventAdded, "after")
+
+	history := monitor.GetHistory()
+	if len(history) != 2 || history[0].Content != "after" || history[1].Content != "before" {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+
+	// 未暂停时 Resume 不发布事件
+	monitor.Resume()
+	select {
+	case event := <-sub.C:
+		t.Errorf("Unexpected event %s", event.Type)
+	default:
+	}
+}
+
+func TestPauseForResumesAutomatically(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	monitor.PauseFor(50 * time.Millisecond)
+	state := expectState(t, sub, EventPaused, true)
+	if state.Until.IsZero() || monitor.PauseState() != state {
+		t.Errorf("Expected timed pause, got %+v", monitor.PauseState())
+	}
+
+	expectState(t, sub, EventResumed, false)
+	if monitor.IsPaused() {
+		t.Error("Expected monitor to resume after the incognito period")
+	}
+}
+
+func TestPauseReplacesIncognitoTimer(t *testing.T) {
+	monitor := NewMonitor(10, WithBackend(NewMemoryBackend()))
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	// 隐身模式期间改为无限期暂停，原来的恢复时间不再生效
+	monitor.PauseFor(30 * time.Millisecond)
+	expectState(t, sub, EventPaused, true)
+	monitor.Pause()
+	expectState(t, sub, EventPaused, true)
+
+	time.Sleep(100 * time.Millisecond)
+	if !monitor.IsPaused() {
+		t.Fatal("Expected indefinite pause to cancel the incognito timer")
+	}
+
+	// 手动恢复后旧的定时器同样不会再次触发事件
+	monitor.PauseFor(30 * time.Millisecond)
+	expectState(t, sub, EventPaused, true)
+	monitor.Resume()
+	expectState(t, sub, EventResumed, false)
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case event := <-sub.C:
+		t.Errorf("Unexpected event %s", event.Type)
+	default:
+	}
+}
@@ -0,0 +1,123 @@
+package clipboard
+
+import (
+	"sort"
+)
+
+// pinnedCount 返回历史记录开头固定条目的数量，调用方需持有锁
+func (m *Monitor) pinnedCount() int {
+	n := 0
+	for n < len(m.history) && m.history[n].Pinned {
+		n++
+	}
+	return n
+}
+
+// sortPinned 将固定的条目按 PinOrder 移到历史记录最前面，其余条目保持原有顺序，调用方需持有锁
+func (m *Monitor) sortPinned() {
+	sort.SliceStable(m.history, func(i, j int) bool {
+		a, b := m.history[i], m.history[j]
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		return a.Pinned && a.PinOrder < b.PinOrder
+	})
+	for i := 0; i < m.pinnedCount(); i++ {
+		m.history[i].PinOrder = i
+	}
+}
+
+// renumberPinned 按当前位置更新固定条目的 PinOrder 并持久化有变化的条目，调用方需持有锁
+func (m *Monitor) renumberPinned() {
+	for i := 0; i < m.pinnedCount(); i++ {
+		if m.history[i].PinOrder != i {
+			m.history[i].PinOrder = i
+			m.persistUpdate(m.history[i])
+		}
+	}
+}
+
+// PinnedEntries 返回固定的条目，按固定顺序排列
+func (m *Monitor) PinnedEntries() []ClipboardEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pinned := make([]ClipboardEntry, m.pinnedCount())
+	copy(pinned, m.history)
+	return pinned
+}
+
+// Pin 固定指定 ID 的条目，新固定的条目排在固定条目的末尾，返回修改后的条目
+//
+// 固定的条目不计入历史记录上限，也不会被 ClearHistory 清除，只能通过 Unpin 或 DeleteEntry 移除。
+func (m *Monitor) Pin(id string) (ClipboardEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return ClipboardEntry{}, ErrEntryNotFound
+	}
+	entry := m.history[i]
+	if entry.Pinned {
+		return entry, nil
+	}
+
+	m.history = append(m.history[:i], m.history[i+1:]...)
+	entry.Pinned = true
+	entry.PinOrder = m.pinnedCount()
+	m.insert(entry.PinOrder, entry)
+	m.persistUpdate(entry)
+	m.changed(EventPinned, entry)
+	return entry, nil
+}
+
+// Unpin 取消固定指定 ID 的条目，条目回到未固定条目的顶部，返回修改后的条目
+//
+// 条目重新计入历史记录上限，超出上限时会淘汰最旧的未固定条目。
+func (m *Monitor) Unpin(id string) (ClipboardEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return ClipboardEntry{}, ErrEntryNotFound
+	}
+	entry := m.history[i]
+	if !entry.Pinned {
+		return entry, nil
+	}
+
+	m.history = append(m.history[:i], m.history[i+1:]...)
+	entry.Pinned = false
+	entry.PinOrder = 0
+	m.insert(m.pinnedCount(), entry)
+	m.renumberPinned()
+	m.persistPut(entry)
+	m.changed(EventPinned, entry)
+	m.enforceRetention()
+	return entry, nil
+}
+
+// MovePinned 将固定的条目移动到固定条目中的第 to 个位置（从 0 开始，超出范围时移到末尾），返回修改后的条目
+func (m *Monitor) MovePinned(id string, to int) (ClipboardEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return ClipboardEntry{}, ErrEntryNotFound
+	}
+	entry := m.history[i]
+	if !entry.Pinned {
+		return ClipboardEntry{}, ErrNotPinned
+	}
+
+	m.history = append(m.history[:i], m.history[i+1:]...)
+	to = max(0, min(to, m.pinnedCount()))
+	m.insert(to, entry)
+	m.renumberPinned()
+	entry = m.history[to]
+	m.changed(EventPinned, entry)
+	return entry, nil
+}
@@ -0,0 +1,195 @@
+package clipboard
+
+import (
+	"testing"
+	"time"
+)
+
+// pinMonitor 创建依次复制了 contents 的 Monitor，最后一个在最前面
+func pinMonitor(maxHistory int, store Store, contents ...string) *Monitor {
+	opts := []Option{WithBackend(NewMemoryBackend())}
+	if store != nil {
+		opts = append(opts, WithStore(store))
+	}
+	monitor := NewMonitor(maxHistory, opts...)
+	now := time.Now()
+	monitor.mu.Lock()
+	for i, content := range contents {
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+	monitor.mu.Unlock()
+	return monitor
+}
+
+// contentsOf 返回条目的内容
+func contentsOf(entries []ClipboardEntry) []string {
+	contents := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		contents = append(contents, entry.Content)
+	}
+	return contents
+}
+
+// expectContents 检查条目的内容和顺序
+func expectContents(t *testing.T, entries []ClipboardEntry, want ...string) {
+	t.Helper()
+	got := contentsOf(entries)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+// findEntry 按内容查找条目
+func findEntry(t *testing.T, m *Monitor, content string) ClipboardEntry {
+	t.Helper()
+	for _, entry := range m.GetHistory() {
+		if entry.Content == content {
+			return entry
+		}
+	}
+	t.Fatalf("Entry %q not found", content)
+	return ClipboardEntry{}
+}
+
+func TestPinSurvivesEviction(t *testing.T) {
+	monitor := pinMonitor(2, nil, "a", "b")
+	if _, err := monitor.Pin(findEntry(t, monitor, "a").ID); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	expectContents(t, monitor.GetHistory(), "a", "b")
+
+	// 固定的条目不计入上限，新条目排在固定条目之后
+	monitor.mu.Lock()
+	for _, content := range []string{"c", "d", "e"} {
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: time.Now()})
+	}
+	monitor.mu.Unlock()
+	expectContents(t, monitor.GetHistory(), "a", "e", "d")
+
+	// 再次复制固定的条目时保持原来的位置
+	monitor.mu.Lock()
+	monitor.addToHistory(ClipboardEntry{Content: "a", Timestamp: time.Now()})
+	monitor.mu.Unlock()
+	expectContents(t, monitor.GetHistory(), "a", "e", "d")
+
+	monitor.ClearHistory()
+	expectContents(t, monitor.GetHistory(), "a")
+	if !monitor.GetHistory()[0].Pinned {
+		t.Error("Expected entry to stay pinned after ClearHistory")
+	}
+}
+
+func TestUnpinAndMovePinned(t *testing.T) {
+	monitor := pinMonitor(10, nil, "a", "b", "c", "d")
+	for _, content := range []string{"a", "b", "c"} {
+		if _, err := monitor.Pin(findEntry(t, monitor, content).ID); err != nil {
+			t.Fatalf("Pin failed: %v", err)
+		}
+	}
+	expectContents(t, monitor.PinnedEntries(), "a", "b", "c")
+
+	c := findEntry(t, monitor, "c")
+	if _, err := monitor.MovePinned(c.ID, 0); err != nil {
+		t.Fatalf("MovePinned failed: %v", err)
+	}
+	expectContents(t, monitor.PinnedEntries(), "c", "a", "b")
+	if _, err := monitor.MovePinned(c.ID, 99); err != nil {
+		t.Fatalf("MovePinned failed: %v", err)
+	}
+	expectContents(t, monitor.PinnedEntries(), "a", "b", "c")
+	for i, entry := range monitor.PinnedEntries() {
+		if entry.PinOrder != i {
+			t.Errorf("Expected PinOrder %d for %q, got %d", i, entry.Content, entry.PinOrder)
+		}
+	}
+
+	// 取消固定后回到未固定条目的顶部
+	if _, err := monitor.Unpin(findEntry(t, monitor, "a").ID); err != nil {
+		t.Fatalf("Unpin failed: %v", err)
+	}
+	expectContents(t, monitor.GetHistory(), "b", "c", "a", "d")
+	if _, err := monitor.MovePinned(findEntry(t, monitor, "d").ID, 0); err != ErrNotPinned {
+		t.Errorf("Expected ErrNotPinned, got %v", err)
+	}
+	if _, err := monitor.Pin("missing"); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestPinnedPersisted(t *testing.T) {
+	store := &memoryStore{}
+	monitor := pinMonitor(10, store, "a", "b", "c")
+	for _, content := range []string{"a", "c"} {
+		if _, err := monitor.Pin(findEntry(t, monitor, content).ID); err != nil {
+			t.Fatalf("Pin failed: %v", err)
+		}
+	}
+	if _, err := monitor.MovePinned(findEntry(t, monitor, "c").ID, 0); err != nil {
+		t.Fatalf("MovePinned failed: %v", err)
+	}
+	monitor.ClearHistory()
+
+	reloaded := NewMonitor(10, WithBackend(NewMemoryBackend()), WithStore(store))
+	expectContents(t, reloaded.GetHistory(), "c", "a")
+	for _, entry := range reloaded.GetHistory() {
+		if !entry.Pinned {
+			t.Errorf("Expected %q to be pinned after reload", entry.Content)
+		}
+	}
+}
+
+func TestFuzzySearchPinnedFirst(t *testing.T) {
+	monitor := pinMonitor(10, nil, "git status", "go test", "grep todo")
+	if _, err := monitor.Pin(findEntry(t, monitor, "git status").ID); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+
+	var entries []ClipboardEntry
+	for _, match := range monitor.FuzzySearch("gt", 0) {
+		entries = append(entries, match.Entry)
+	}
+	if len(entries) == 0 || entries[0].Content != "git status" {
+		t.Errorf("Expected pinned entry first, got %v", contentsOf(entries))
+	}
+
+	entries = entries[:0]
+	for _, match := range monitor.FuzzySearch("", 2) {
+		entries = append(entries, match.Entry)
+	}
+	expectContents(t, entries, "git status", "grep todo")
+}
+
+func TestPreviousSkipsPinnedEntries(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend))
+	if _, ok := monitor.Previous(); ok {
+		t.Error("Expected no previous entry in empty history")
+	}
+
+	for _, content := range []string{"pinned", "older", "previous", "current"} {
+		backend.SetContent(content)
+		monitor.checkClipboard()
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := monitor.Pin(findEntry(t, monitor, "pinned").ID); err != nil {
+		t.Fatal(err)
+	}
+	expectContents(t, monitor.GetHistory(), "pinned", "current", "previous", "older")
+
+	previous, ok := monitor.Previous()
+	if !ok || previous.Content != "previous" {
+		t.Fatalf("Expected 'previous', got %q (%v)", previous.Content, ok)
+	}
+
+	// 当前剪贴板内容是固定的条目时，上一条是最新的未固定条目
+	backend.SetContent("pinned")
+	monitor.checkClipboard()
+	if previous, _ := monitor.Previous(); previous.Content != "current" {
+		t.Errorf("Expected 'current', got %q", previous.Content)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"log"
+	"os"
+)
+
+// DefaultBackend 返回当前平台推荐的后端。
+// Wayland 会话下优先使用 data-control 协议，X11 会话下使用 XFixes 事件监听，
+// 都不可用时使用轮询的系统后端。
+func DefaultBackend() Backend {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		backend, err := NewWaylandBackend("")
+		if err == nil {
+			return backend
+		}
+		log.Printf("Wayland data-control 不可用，使用轮询方式监听剪贴板: %v", err)
+	}
+
+	display := os.Getenv("DISPLAY")
+	if display != "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		backend, err := NewX11Backend(display, false)
+		if err == nil {
+			return backend
+		}
+		log.Printf("XFixes 不可用，使用轮询方式监听剪贴板: %v", err)
+	}
+	return NewSystemBackend()
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package clipboard
+
+// DefaultBackend 返回当前平台推荐的后端
+func DefaultBackend() Backend {
+	return NewSystemBackend()
+}
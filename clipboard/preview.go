@@ -0,0 +1,114 @@
+package clipboard
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 大条目的预览
+const (
+	DefaultPreviewThreshold = 64 << 10 // 超过该大小（字节）的条目在列表中只显示预览
+	PreviewMaxLines         = 10       // 预览最多包含的行数
+	PreviewMaxChars         = 1000     // 预览最多包含的字符数
+	previewMaxImage         = 1 << 20  // 不超过该大小的图片保留在预览中，用于显示缩略图
+)
+
+// Preview 大条目的摘要，在列表中代替完整内容，完整内容需要按 ID 另外获取
+type Preview struct {
+	Size    int64    `json:"size"`              // 完整条目的大小（字节）
+	Lines   int      `json:"lines"`             // 文本的行数
+	Formats []string `json:"formats,omitempty"` // 纯文本以外的格式（MIME 类型）
+}
+
+// NewPreview 计算条目的摘要
+func NewPreview(entry ClipboardEntry) Preview {
+	preview := Preview{Size: entry.Size(), Lines: lineCount(entry.Content)}
+	for mime := range entry.Formats {
+		preview.Formats = append(preview.Formats, mime)
+	}
+	sort.Strings(preview.Formats)
+	return preview
+}
+
+// Brief 返回在列表中显示的条目：有预览的条目只保留文本的前 PreviewMaxLines 行
+// （最多 PreviewMaxChars 个字符）和不太大的图片，其他条目原样返回
+func (e ClipboardEntry) Brief() ClipboardEntry {
+	if e.Preview == nil {
+		return e
+	}
+	brief := e
+	brief.Content = previewText(e.Content)
+	brief.Formats = nil
+	if png, ok := e.Formats[MIMEPNG]; ok && len(png) <= previewMaxImage {
+		brief.Formats = map[string][]byte{MIMEPNG: png}
+	}
+	return brief
+}
+
+// Brief 返回条目截断为预览后的搜索结果，超出预览的匹配位置被丢弃或截短
+func (r SearchResult) Brief() SearchResult {
+	r.Entry = r.Entry.Brief()
+	n := utf8.RuneCountInString(r.Entry.Content)
+	matches := make([]Span, 0, len(r.Matches))
+	for _, span := range r.Matches {
+		if span.Start >= n {
+			break
+		}
+		span.End = min(span.End, n)
+		matches = append(matches, span)
+	}
+	r.Matches = matches
+	return r
+}
+
+// Brief 返回条目截断为预览后的模糊匹配结果，超出预览的匹配位置被丢弃
+func (m FuzzyMatch) Brief() FuzzyMatch {
+	m.Entry = m.Entry.Brief()
+	n := utf8.RuneCountInString(m.Entry.Content)
+	end := sort.SearchInts(m.Positions, n)
+	m.Positions = m.Positions[:end:end]
+	return m
+}
+
+// WithPreviewThreshold 指定显示预览的条目大小，<= 0 时所有条目都显示完整内容
+func WithPreviewThreshold(threshold int64) Option {
+	return func(m *Monitor) {
+		m.previewThreshold = threshold
+	}
+}
+
+// summarize 按条目大小设置或清除预览，调用方需持有锁
+func (m *Monitor) summarize(entry ClipboardEntry) ClipboardEntry {
+	entry.Preview = nil
+	if m.previewThreshold > 0 && entry.Size() > m.previewThreshold {
+		preview := NewPreview(entry)
+		entry.Preview = &preview
+	}
+	return entry
+}
+
+// lineCount 返回文本的行数，末尾的换行不算作新的一行
+func lineCount(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+}
+
+// previewText 截取文本的前 PreviewMaxLines 行，最多 PreviewMaxChars 个字符
+func previewText(text string) string {
+	end, lines, chars := 0, 0, 0
+	for end < len(text) && chars < PreviewMaxChars {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if r == '\n' {
+			lines++
+			if lines == PreviewMaxLines {
+				break
+			}
+		}
+		end += size
+		chars++
+	}
+	return text[:end]
+}
@@ -0,0 +1,119 @@
+package clipboard
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPreviewText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"short", "a\nb", "a\nb"},
+		{"line limit", strings.Repeat("line\n", 20), strings.TrimSuffix(strings.Repeat("line\n", PreviewMaxLines), "\n")},
+		{"char limit", strings.Repeat("长", PreviewMaxChars+10), strings.Repeat("长", PreviewMaxChars)},
+	}
+	for _, tt := range tests {
+		if got := previewText(tt.text); got != tt.want {
+			t.Errorf("%s: expected %d chars, got %d", tt.name, utf8.RuneCountInString(tt.want), utf8.RuneCountInString(got))
+		}
+	}
+}
+
+func TestLineCount(t *testing.T) {
+	tests := map[string]int{"": 0, "a": 1, "a\n": 1, "a\nb": 2, "a\nb\n\n": 3}
+	for text, want := range tests {
+		if got := lineCount(text); got != want {
+			t.Errorf("lineCount(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestMonitorPreviewsLargeEntries(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend), WithPreviewThreshold(1000))
+
+	log := strings.Repeat("0123456789\n", 200)
+	backend.SetFormats(map[string][]byte{MIMEText: []byte(log), MIMEHTML: []byte("<pre>" + log + "</pre>")})
+	monitor.checkClipboard()
+	backend.SetContent("small")
+	monitor.checkClipboard()
+
+	history := monitor.GetHistory()
+	if history[0].Preview != nil {
+		t.Errorf("Expected no preview for small entry, got %+v", history[0].Preview)
+	}
+	if brief := history[0].Brief(); !reflect.DeepEqual(brief, history[0]) {
+		t.Errorf("Expected small entry to be returned unchanged")
+	}
+
+	large := history[1]
+	want := Preview{Size: large.Size(), Lines: 200, Formats: []string{MIMEHTML}}
+	if large.Preview == nil || !reflect.DeepEqual(*large.Preview, want) {
+		t.Fatalf("Expected preview %+v, got %+v", want, large.Preview)
+	}
+	// 历史记录中仍然是完整内容，只有 Brief 截断
+	if large.Content != log {
+		t.Error("Expected full content in history")
+	}
+	brief := large.Brief()
+	if brief.Content != strings.Repeat("0123456789\n", PreviewMaxLines-1)+"0123456789" || brief.Formats != nil || brief.ID != large.ID {
+		t.Errorf("Unexpected brief entry: %q, %v", brief.Content, brief.Formats)
+	}
+
+	// 修改为较短的内容后不再需要预览
+	updated, err := monitor.UpdateEntry(large.ID, func(entry *ClipboardEntry) {
+		entry.Content = "short"
+		entry.Formats = nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Preview != nil {
+		t.Errorf("Expected preview to be cleared, got %+v", updated.Preview)
+	}
+}
+
+func TestBriefKeepsSmallImages(t *testing.T) {
+	image := NewEntry(map[string][]byte{MIMEPNG: make([]byte, 2000), MIMEHTML: make([]byte, 2000)})
+	preview := NewPreview(image)
+	image.Preview = &preview
+
+	brief := image.Brief()
+	if len(brief.Formats) != 1 || len(brief.Formats[MIMEPNG]) != 2000 {
+		t.Errorf("Expected only the image to be kept, got %d formats", len(brief.Formats))
+	}
+
+	image.Formats[MIMEPNG] = make([]byte, previewMaxImage+1)
+	if brief := image.Brief(); brief.Formats != nil {
+		t.Error("Expected large image to be dropped from the brief entry")
+	}
+}
+
+func TestBriefSearchResults(t *testing.T) {
+	content := strings.Repeat("x", PreviewMaxChars-2) + "needle" + strings.Repeat("x", 10)
+	entry := ClipboardEntry{Content: content, Preview: &Preview{}}
+
+	result := SearchResult{Entry: entry, Matches: []Span{{0, 1}, {PreviewMaxChars - 2, PreviewMaxChars + 4}, {PreviewMaxChars + 5, PreviewMaxChars + 6}}}.Brief()
+	if want := []Span{{0, 1}, {PreviewMaxChars - 2, PreviewMaxChars}}; !reflect.DeepEqual(result.Matches, want) {
+		t.Errorf("Expected spans clipped to the preview %v, got %v", want, result.Matches)
+	}
+	if utf8.RuneCountInString(result.Entry.Content) != PreviewMaxChars {
+		t.Errorf("Expected brief content, got %d chars", utf8.RuneCountInString(result.Entry.Content))
+	}
+
+	match := FuzzyMatch{Entry: entry, Positions: []int{3, PreviewMaxChars - 1, PreviewMaxChars, PreviewMaxChars + 3}}.Brief()
+	if want := []int{3, PreviewMaxChars - 1}; !reflect.DeepEqual(match.Positions, want) {
+		t.Errorf("Expected positions inside the preview %v, got %v", want, match.Positions)
+	}
+
+	// 没有预览的条目保留全部位置
+	full := SearchResult{Entry: ClipboardEntry{Content: content}, Matches: []Span{{PreviewMaxChars + 5, PreviewMaxChars + 6}}}.Brief()
+	if len(full.Matches) != 1 {
+		t.Errorf("Expected matches of a full entry to be kept, got %v", full.Matches)
+	}
+}
@@ -0,0 +1,134 @@
+package clipboard
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultRetentionInterval 定期检查保留策略（删除过期条目）的默认间隔
+const DefaultRetentionInterval = time.Minute
+
+// RetentionPolicy 历史记录保留策略，各项限制为零时不生效
+//
+// 固定和带有标签的条目不受任何限制，也不计入条目数和总大小。
+// 其余条目按从新到旧的顺序累计，超出条目数或总大小后更旧的条目全部删除，
+// 这样保留下来的总是最近复制的一段连续的记录。
+type RetentionPolicy struct {
+	MaxEntries    int           // 最多保留的条目数
+	MaxAge        time.Duration // 最后一次复制超过该时长的条目被删除
+	MaxTotalBytes int64         // 全部条目的总大小上限
+	MaxEntryBytes int64         // 单个条目的大小上限，超出的内容不记录
+}
+
+// Size 条目占用的大小：文本和全部格式数据的字节数
+func (e ClipboardEntry) Size() int64 {
+	size := int64(len(e.Content))
+	for _, data := range e.Formats {
+		size += int64(len(data))
+	}
+	return size
+}
+
+// exempt 条目是否不受保留策略限制
+func (e ClipboardEntry) exempt() bool {
+	return e.Pinned || len(e.Tags) > 0
+}
+
+// TooLarge 条目是否超出单个条目的大小上限
+func (p RetentionPolicy) TooLarge(entry ClipboardEntry) bool {
+	return p.MaxEntryBytes > 0 && entry.Size() > p.MaxEntryBytes
+}
+
+// expired 条目在 now 时是否已超过保留时长
+func (p RetentionPolicy) expired(entry ClipboardEntry, now time.Time) bool {
+	return p.MaxAge > 0 && now.Sub(entry.Timestamp) > p.MaxAge
+}
+
+// WithRetention 指定保留策略，覆盖 NewMonitor 的 maxHistory
+func WithRetention(policy RetentionPolicy) Option {
+	return func(m *Monitor) {
+		m.retention = policy
+	}
+}
+
+// WithRetentionInterval 指定定期检查保留策略的间隔，默认为 DefaultRetentionInterval
+func WithRetentionInterval(interval time.Duration) Option {
+	return func(m *Monitor) {
+		if interval > 0 {
+			m.retentionInterval = interval
+		}
+	}
+}
+
+// Retention 返回当前的保留策略
+func (m *Monitor) Retention() RetentionPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.retention
+}
+
+// SetRetention 替换保留策略并立即执行，不符合新策略的条目会被删除
+func (m *Monitor) SetRetention(policy RetentionPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retention = policy
+	m.enforceRetention()
+}
+
+// EnforceRetention 立即按保留策略删除条目，通常由 Start 定期调用
+func (m *Monitor) EnforceRetention() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enforceRetention()
+}
+
+// runRetention 定期执行保留策略，直到 ctx 被取消
+func (m *Monitor) runRetention(ctx context.Context) {
+	ticker := time.NewTicker(m.retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.EnforceRetention()
+		}
+	}
+}
+
+// enforceRetention 删除不符合保留策略的条目，调用方需持有写锁
+func (m *Monitor) enforceRetention() {
+	policy := m.retention
+	now := time.Now()
+
+	var evicted []ClipboardEntry
+	kept, count := 0, 0
+	var total int64
+	overflow := false
+	for _, entry := range m.history {
+		if !entry.exempt() {
+			size := entry.Size()
+			// 过大或过期的条目直接删除，不影响更旧的条目
+			drop := policy.TooLarge(entry) || policy.expired(entry, now)
+			if !drop && !overflow {
+				overflow = policy.MaxEntries > 0 && count >= policy.MaxEntries ||
+					policy.MaxTotalBytes > 0 && total+size > policy.MaxTotalBytes
+			}
+			if drop || overflow {
+				evicted = append(evicted, entry)
+				continue
+			}
+			count++
+			total += size
+		}
+		m.history[kept] = entry
+		kept++
+	}
+	m.history = m.history[:kept]
+
+	for _, entry := range evicted {
+		m.persistDelete(entry)
+		m.changed(EventDeleted, entry)
+	}
+}
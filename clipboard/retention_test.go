@@ -0,0 +1,141 @@
+package clipboard
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// retentionMonitor 创建依次复制了 contents 的 Monitor，第 i 个条目在 ages[i] 之前复制
+func retentionMonitor(store Store, contents []string, ages []time.Duration) *Monitor {
+	opts := []Option{WithBackend(NewMemoryBackend()), WithRetention(RetentionPolicy{})}
+	if store != nil {
+		opts = append(opts, WithStore(store))
+	}
+	monitor := NewMonitor(0, opts...)
+	now := time.Now()
+	monitor.mu.Lock()
+	for i, content := range contents {
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: now.Add(-ages[i])})
+	}
+	monitor.mu.Unlock()
+	return monitor
+}
+
+func TestEntrySize(t *testing.T) {
+	entry := ClipboardEntry{Content: "hello", Formats: map[string][]byte{MIMEHTML: []byte("<b>hello</b>")}}
+	if got := entry.Size(); got != 17 {
+		t.Errorf("Expected size 17, got %d", got)
+	}
+}
+
+func TestRetentionLimits(t *testing.T) {
+	hour := time.Hour
+	contents := []string{"old", "aaaaaaaaaa", "b", "c", "new"}
+	ages := []time.Duration{5 * hour, 4 * hour, 3 * hour, 2 * hour, hour}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{
+		{"unlimited", RetentionPolicy{}, []string{"new", "c", "b", "aaaaaaaaaa", "old"}},
+		{"max entries", RetentionPolicy{MaxEntries: 2}, []string{"new", "c"}},
+		{"max age", RetentionPolicy{MaxAge: 3*hour + time.Minute}, []string{"new", "c", "b"}},
+		// 总大小按从新到旧累计，超出后更旧的条目全部删除
+		{"max total bytes", RetentionPolicy{MaxTotalBytes: 12}, []string{"new", "c", "b"}},
+		{"max entry bytes", RetentionPolicy{MaxEntryBytes: 5}, []string{"new", "c", "b", "old"}},
+		// 过大的条目不占用总大小
+		{"combined", RetentionPolicy{MaxEntryBytes: 5, MaxTotalBytes: 8}, []string{"new", "c", "b", "old"}},
+		{"combined with count", RetentionPolicy{MaxEntries: 3, MaxEntryBytes: 5}, []string{"new", "c", "b"}},
+	}
+	for _, tt := range tests {
+		monitor := retentionMonitor(nil, contents, ages)
+		monitor.SetRetention(tt.policy)
+		if got := contentsOf(monitor.GetHistory()); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+		if monitor.Retention() != tt.policy {
+			t.Errorf("%s: policy not stored", tt.name)
+		}
+	}
+}
+
+func TestRetentionExemptsPinnedAndTagged(t *testing.T) {
+	store := &memoryStore{}
+	hour := time.Hour
+	monitor := retentionMonitor(store, []string{"pinned", "tagged", "plain", "new"}, []time.Duration{4 * hour, 3 * hour, 2 * hour, 0})
+
+	history := monitor.GetHistory()
+	if _, err := monitor.Pin(history[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monitor.TagEntry(history[2].ID, "keep"); err != nil {
+		t.Fatal(err)
+	}
+
+	monitor.SetRetention(RetentionPolicy{MaxEntries: 1, MaxAge: hour, MaxTotalBytes: 3, MaxEntryBytes: 3})
+	if got := contentsOf(monitor.GetHistory()); !reflect.DeepEqual(got, []string{"pinned", "new", "tagged"}) {
+		t.Errorf("Unexpected history: %v", got)
+	}
+	if len(store.entries) != 3 {
+		t.Errorf("Expected removed entries to be deleted from store, got %d", len(store.entries))
+	}
+}
+
+func TestRetentionOnInsert(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(0, WithBackend(backend), WithRetention(RetentionPolicy{MaxTotalBytes: 10, MaxEntryBytes: 8}))
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	backend.SetContent("12345")
+	monitor.checkClipboard()
+	backend.SetContent("678")
+	monitor.checkClipboard()
+	expectEvent(t, sub, EventAdded, "12345")
+	expectEvent(t, sub, EventAdded, "678")
+
+	// 超出单个条目上限的内容不记录，也不会产生事件
+	backend.SetContent(strings.Repeat("x", 9))
+	monitor.checkClipboard()
+
+	backend.SetContent("abcd")
+	monitor.checkClipboard()
+	expectEvent(t, sub, EventAdded, "abcd")
+	expectEvent(t, sub, EventDeleted, "12345")
+
+	if got := contentsOf(monitor.GetHistory()); !reflect.DeepEqual(got, []string{"abcd", "678"}) {
+		t.Errorf("Unexpected history: %v", got)
+	}
+}
+
+func TestRetentionRunsOnSchedule(t *testing.T) {
+	backend := NewMemoryBackend()
+	monitor := NewMonitor(10, WithBackend(backend),
+		WithRetention(RetentionPolicy{MaxAge: 100 * time.Millisecond}),
+		WithRetentionInterval(20*time.Millisecond))
+	sub := monitor.Subscribe(0)
+	defer monitor.Unsubscribe(sub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backend.SetContent("short-lived")
+	go monitor.Start(ctx)
+
+	expectEvent(t, sub, EventAdded, "short-lived")
+	expectEvent(t, sub, EventDeleted, "short-lived")
+	if got := len(monitor.GetHistory()); got != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", got)
+	}
+}
+
+func TestSetMaxHistoryKeepsOtherLimits(t *testing.T) {
+	monitor := NewMonitor(10, WithRetention(RetentionPolicy{MaxEntries: 10, MaxAge: time.Hour}))
+	monitor.SetMaxHistory(5)
+	if got := monitor.Retention(); got.MaxEntries != 5 || got.MaxAge != time.Hour {
+		t.Errorf("Unexpected policy: %+v", got)
+	}
+}
@@ -0,0 +1,542 @@
+package clipboard
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"clipboard-monitor/pinyin"
+)
+
+// 排序参数
+const (
+	bm25K1         = 1.2
+	bm25B          = 0.75
+	recencyWeight  = 0.5            // 时间因素在排序中的权重
+	recencyHalfAge = 24 * time.Hour // 时间因素衰减一半所需的时长
+	pinyinMinLen   = 2              // 按拼音匹配汉字所需的最短查询词，避免单个字母匹配大量汉字
+)
+
+// Span 匹配位置，Start/End 为在条目 Content 中的字符（rune）偏移，左闭右开
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Entry   ClipboardEntry `json:"entry"`
+	Score   float64        `json:"score"`
+	Matches []Span         `json:"matches"` // 按位置排序且互不重叠，用于高亮
+}
+
+// searchDoc 已索引的条目
+type searchDoc struct {
+	tokens []token
+	han    []hanRun // 连续的汉字，用于拼音匹配
+}
+
+// hanRun 条目中一段连续的汉字
+type hanRun struct {
+	start int    // 第一个字在 tokens 中的下标
+	chars []rune // 各个汉字
+}
+
+// hanRuns 找出 tokens 中相邻且有拼音读音的汉字
+func hanRuns(tokens []token) []hanRun {
+	var runs []hanRun
+	for i, tok := range tokens {
+		r := []rune(tok.Text)
+		if len(r) != 1 || !unicode.Is(unicode.Han, r[0]) || pinyin.Readings(r[0]) == nil {
+			continue
+		}
+		if n := len(runs); n > 0 {
+			last := &runs[n-1]
+			end := last.start + len(last.chars)
+			if end == i && tokens[i-1].End == tok.Start {
+				last.chars = append(last.chars, r[0])
+				continue
+			}
+		}
+		runs = append(runs, hanRun{start: i, chars: []rune{r[0]}})
+	}
+	return runs
+}
+
+// hanAt 返回 tokens 中下标为 pos 的汉字所在的一段及其在该段中的位置
+func (d *searchDoc) hanAt(pos int) (hanRun, int) {
+	i := sort.Search(len(d.han), func(i int) bool { return d.han[i].start > pos }) - 1
+	run := d.han[i]
+	return run, pos - run.start
+}
+
+// pinyinKeys 汉字在拼音索引中的键：每个读音的完整拼音、首字母以及 zh、ch、sh 声母
+func pinyinKeys(r rune) []string {
+	var keys []string
+	for _, reading := range pinyin.Readings(r) {
+		first, consonant := pinyin.Initials(reading)
+		for _, key := range [...]string{reading, first, consonant} {
+			if key != "" && !containsString(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// hit 匹配在 tokens 中的区间，左闭右开
+type hit struct {
+	start, end int
+}
+
+// searchIndex 条目内容的倒排索引，由 Monitor 在持有锁时维护
+type searchIndex struct {
+	docs     map[string]*searchDoc       // 条目 ID -> 分词结果
+	postings map[string]map[string][]int // 词 -> 条目 ID -> 在 tokens 中的下标
+	pinyin   map[string]map[string][]int // 拼音或首字母 -> 条目 ID -> 汉字在 tokens 中的下标
+	totalLen int
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     make(map[string]*searchDoc),
+		postings: make(map[string]map[string][]int),
+		pinyin:   make(map[string]map[string][]int),
+	}
+}
+
+// add 索引条目，已存在时先移除旧的索引
+func (idx *searchIndex) add(entry ClipboardEntry) {
+	idx.remove(entry.ID)
+	tokens := tokenize(entry.Content)
+	doc := &searchDoc{tokens: tokens, han: hanRuns(tokens)}
+	idx.docs[entry.ID] = doc
+	idx.totalLen += len(doc.tokens)
+	for i, tok := range doc.tokens {
+		ids := idx.postings[tok.Text]
+		if ids == nil {
+			ids = make(map[string][]int)
+			idx.postings[tok.Text] = ids
+		}
+		ids[entry.ID] = append(ids[entry.ID], i)
+	}
+	for _, run := range doc.han {
+		for i, r := range run.chars {
+			for _, key := range pinyinKeys(r) {
+				ids := idx.pinyin[key]
+				if ids == nil {
+					ids = make(map[string][]int)
+					idx.pinyin[key] = ids
+				}
+				ids[entry.ID] = append(ids[entry.ID], run.start+i)
+			}
+		}
+	}
+}
+
+// remove 移除条目的索引
+func (idx *searchIndex) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, tok := range doc.tokens {
+		if ids := idx.postings[tok.Text]; ids != nil {
+			delete(ids, id)
+			if len(ids) == 0 {
+				delete(idx.postings, tok.Text)
+			}
+		}
+	}
+	for _, run := range doc.han {
+		for _, r := range run.chars {
+			for _, key := range pinyinKeys(r) {
+				if ids := idx.pinyin[key]; ids != nil {
+					delete(ids, id)
+					if len(ids) == 0 {
+						delete(idx.pinyin, key)
+					}
+				}
+			}
+		}
+	}
+	idx.totalLen -= len(doc.tokens)
+	delete(idx.docs, id)
+}
+
+// rebuild 根据完整的历史记录重建索引
+func (idx *searchIndex) rebuild(history []ClipboardEntry) {
+	*idx = *newSearchIndex()
+	for _, entry := range history {
+		idx.add(entry)
+	}
+}
+
+// apply 根据历史记录事件更新索引
+func (idx *searchIndex) apply(eventType EventType, entry ClipboardEntry, history []ClipboardEntry) {
+	switch eventType {
+	case EventAdded, EventUpdated:
+		idx.add(entry)
+	case EventPromoted, EventPinned:
+		// 内容不变，只有时间戳或位置更新，排序时从历史记录读取最新时间
+		if _, ok := idx.docs[entry.ID]; !ok {
+			idx.add(entry)
+		}
+	case EventDeleted:
+		idx.remove(entry.ID)
+	case EventCleared, EventReloaded:
+		idx.rebuild(history)
+	}
+}
+
+// clauseKind 查询子句类型
+type clauseKind int
+
+const (
+	clauseTerm   clauseKind = iota // 单个词
+	clausePrefix                   // 前缀，如 conf*
+	clausePhrase                   // 相邻的多个词，如 "hello world" 或 剪贴板
+)
+
+// clause 查询子句
+type clause struct {
+	kind  clauseKind
+	terms []string
+}
+
+// query 解析后的查询：groups 中每组至少匹配一个子句（组内为 OR，组间为 AND），
+// 并且不能匹配 excludes 中的任何子句
+type query struct {
+	groups   [][]clause
+	excludes []clause
+}
+
+// parseQuery 解析查询语句
+//
+// 支持的语法：空格分隔的词默认为 AND；"双引号" 表示短语；词尾 * 表示前缀；
+// 大写 OR 连接两侧的子句；-词 或 NOT 词 表示排除。连续的中文按短语匹配。
+func parseQuery(text string) query {
+	var q query
+	pendingOr, pendingNot := false, false
+
+	for _, item := range splitQuery(text) {
+		if !item.quoted {
+			switch item.text {
+			case "OR":
+				pendingOr = len(q.groups) > 0
+				continue
+			case "NOT", "AND":
+				pendingNot = item.text == "NOT"
+				continue
+			}
+		}
+
+		negate := pendingNot
+		raw := item.text
+		if !item.quoted && strings.HasPrefix(raw, "-") && len(raw) > 1 {
+			negate = true
+			raw = raw[1:]
+		}
+		c, ok := parseClause(raw, item.quoted)
+		if !ok {
+			pendingOr, pendingNot = false, false
+			continue
+		}
+
+		switch {
+		case negate:
+			q.excludes = append(q.excludes, c)
+		case pendingOr:
+			last := len(q.groups) - 1
+			q.groups[last] = append(q.groups[last], c)
+		default:
+			q.groups = append(q.groups, []clause{c})
+		}
+		pendingOr, pendingNot = false, false
+	}
+	return q
+}
+
+// parseClause 将查询中的一项转换为子句，没有可搜索的内容时返回 false
+func parseClause(raw string, quoted bool) (clause, bool) {
+	prefix := !quoted && strings.HasSuffix(raw, "*")
+	tokens := tokenize(strings.TrimSuffix(raw, "*"))
+	if len(tokens) == 0 {
+		return clause{}, false
+	}
+	terms := make([]string, len(tokens))
+	for i, tok := range tokens {
+		terms[i] = tok.Text
+	}
+
+	switch {
+	case len(terms) > 1:
+		return clause{kind: clausePhrase, terms: terms}, true
+	case prefix:
+		return clause{kind: clausePrefix, terms: terms}, true
+	default:
+		return clause{kind: clauseTerm, terms: terms}, true
+	}
+}
+
+// queryItem 查询语句中以空白分隔的一项
+type queryItem struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery 按空白切分查询语句，双引号内的空白保留，未闭合的引号延续到末尾
+func splitQuery(text string) []queryItem {
+	var items []queryItem
+	var current strings.Builder
+	quoted := false
+
+	flush := func(wasQuoted bool) {
+		if current.Len() > 0 {
+			items = append(items, queryItem{text: current.String(), quoted: wasQuoted})
+			current.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			flush(quoted)
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == 0x3000):
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush(quoted)
+	return items
+}
+
+// clauseMatch 子句在某个条目中的匹配结果
+type clauseMatch struct {
+	score float64
+	spans []Span
+}
+
+// match 计算子句在各条目中的匹配，返回条目 ID -> 匹配结果
+func (idx *searchIndex) match(c clause) map[string]clauseMatch {
+	switch c.kind {
+	case clausePrefix:
+		// 合并所有以该前缀开头的词
+		hits := make(map[string][]hit)
+		for term, ids := range idx.postings {
+			if strings.HasPrefix(term, c.terms[0]) {
+				addHits(hits, ids, 1)
+			}
+		}
+		idx.pinyinHits(hits, c.terms[0])
+		return idx.score(hits, 1)
+	case clausePhrase:
+		hits := make(map[string][]hit)
+		addHits(hits, idx.phrasePositions(c.terms), len(c.terms))
+		return idx.score(hits, float64(len(c.terms)))
+	default:
+		hits := make(map[string][]hit)
+		addHits(hits, idx.postings[c.terms[0]], 1)
+		idx.pinyinHits(hits, c.terms[0])
+		return idx.score(hits, 1)
+	}
+}
+
+// addHits 将从 positions 中各下标开始、包含 width 个词的匹配加入 hits
+func addHits(hits map[string][]hit, positions map[string][]int, width int) {
+	for id, starts := range positions {
+		for _, start := range starts {
+			hits[id] = append(hits[id], hit{start: start, end: start + width})
+		}
+	}
+}
+
+// pinyinHits 查找拼音或拼音首字母能拼成 term 的连续汉字，如 jtb 匹配"剪贴板"，结果加入 hits
+//
+// 第一个字用到的完整拼音或首字母一定是 term 的开头部分，因此只需检查拼音索引中
+// 以这些开头部分为键的汉字。
+func (idx *searchIndex) pinyinHits(hits map[string][]hit, term string) {
+	if len(term) < pinyinMinLen {
+		return
+	}
+	for _, c := range term {
+		if c < 'a' || c > 'z' {
+			return
+		}
+	}
+	checked := make(map[string]map[int]bool)
+	for n := 1; n <= len(term); n++ {
+		for id, positions := range idx.pinyin[term[:n]] {
+			doc := idx.docs[id]
+			for _, pos := range positions {
+				if checked[id] == nil {
+					checked[id] = make(map[int]bool)
+				}
+				if checked[id][pos] {
+					continue
+				}
+				checked[id][pos] = true
+				run, i := doc.hanAt(pos)
+				if width := pinyin.MatchPrefix(term, run.chars[i:]); width > 0 {
+					hits[id] = append(hits[id], hit{start: pos, end: pos + width})
+				}
+			}
+		}
+	}
+}
+
+// phrasePositions 查找短语在各条目中出现的起始下标
+func (idx *searchIndex) phrasePositions(terms []string) map[string][]int {
+	result := make(map[string][]int)
+	first := idx.postings[terms[0]]
+	for id, starts := range first {
+		tokens := idx.docs[id].tokens
+		for _, start := range starts {
+			if start+len(terms) > len(tokens) {
+				continue
+			}
+			matched := true
+			for i := 1; i < len(terms); i++ {
+				if tokens[start+i].Text != terms[i] {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				result[id] = append(result[id], start)
+			}
+		}
+	}
+	return result
+}
+
+// score 按 BM25 计算相关度，hits 为各条目中的匹配区间，weight 为子句的权重（短语按词数计）
+func (idx *searchIndex) score(hits map[string][]hit, weight float64) map[string]clauseMatch {
+	n := float64(len(idx.docs))
+	df := float64(len(hits))
+	if df == 0 {
+		return nil
+	}
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	avgLen := float64(idx.totalLen) / math.Max(n, 1)
+
+	result := make(map[string]clauseMatch, len(hits))
+	for id, docHits := range hits {
+		tokens := idx.docs[id].tokens
+		tf := float64(len(docHits))
+		norm := 1 - bm25B + bm25B*float64(len(tokens))/math.Max(avgLen, 1)
+		m := clauseMatch{score: weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)}
+		for _, h := range docHits {
+			m.spans = append(m.spans, Span{Start: tokens[h.start].Start, End: tokens[h.end-1].End})
+		}
+		result[id] = m
+	}
+	return result
+}
+
+// Search 全文搜索历史记录，按相关度和时间综合排序，limit <= 0 表示不限制数量
+func (m *Monitor) Search(text string, limit int) []SearchResult {
+	q := parseQuery(text)
+	if len(q.groups) == 0 {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// 每组取并集，组间取交集
+	var candidates map[string]clauseMatch
+	for _, group := range q.groups {
+		union := make(map[string]clauseMatch)
+		for _, c := range group {
+			for id, cm := range m.index.match(c) {
+				merged := union[id]
+				merged.score += cm.score
+				merged.spans = append(merged.spans, cm.spans...)
+				union[id] = merged
+			}
+		}
+		if candidates == nil {
+			candidates = union
+			continue
+		}
+		for id, cm := range candidates {
+			other, ok := union[id]
+			if !ok {
+				delete(candidates, id)
+				continue
+			}
+			cm.score += other.score
+			cm.spans = append(cm.spans, other.spans...)
+			candidates[id] = cm
+		}
+	}
+	for _, c := range q.excludes {
+		for id := range m.index.match(c) {
+			delete(candidates, id)
+		}
+	}
+
+	now := time.Now()
+	results := make([]SearchResult, 0, len(candidates))
+	order := make(map[string]int, len(candidates))
+	for i, entry := range m.history {
+		cm, ok := candidates[entry.ID]
+		if !ok {
+			continue
+		}
+		order[entry.ID] = i
+		results = append(results, SearchResult{
+			Entry:   entry,
+			Score:   cm.score * (1 + recencyWeight*recency(now, entry.Timestamp)),
+			Matches: mergeSpans(cm.spans),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return order[results[i].Entry.ID] < order[results[j].Entry.ID]
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// recency 时间因素，刚复制的条目为 1，每过 recencyHalfAge 减半
+func recency(now, timestamp time.Time) float64 {
+	if timestamp.IsZero() {
+		return 0
+	}
+	age := now.Sub(timestamp)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(recencyHalfAge))
+}
+
+// mergeSpans 排序并合并重叠或相邻的匹配位置
+func mergeSpans(spans []Span) []Span {
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	merged := []Span{spans[0]}
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span.Start <= last.End {
+			if span.End > last.End {
+				last.End = span.End
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
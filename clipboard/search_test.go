@@ -0,0 +1,213 @@
+package clipboard
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := tokenize("Hello, 剪贴板 ＡＢＣ-42")
+	var texts []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.Text)
+	}
+	want := []string{"hello", "剪", "贴", "板", "abc", "42"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Fatalf("Expected %v, got %v", want, texts)
+	}
+	// 偏移按字符计算
+	if tokens[1].Start != 7 || tokens[3].End != 10 || tokens[4].Start != 11 {
+		t.Errorf("Unexpected offsets: %+v", tokens)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q := parseQuery(`foo OR bar "exact phrase" conf* -secret NOT 密码 剪贴板`)
+	if len(q.groups) != 4 {
+		t.Fatalf("Expected 4 groups, got %+v", q.groups)
+	}
+	if len(q.groups[0]) != 2 || q.groups[0][1].terms[0] != "bar" {
+		t.Errorf("Expected foo OR bar group, got %+v", q.groups[0])
+	}
+	if q.groups[1][0].kind != clausePhrase || q.groups[2][0].kind != clausePrefix {
+		t.Errorf("Unexpected clause kinds: %+v", q.groups)
+	}
+	if q.groups[3][0].kind != clausePhrase || len(q.groups[3][0].terms) != 3 {
+		t.Errorf("Expected CJK run to be a phrase, got %+v", q.groups[3])
+	}
+	if len(q.excludes) != 2 {
+		t.Errorf("Expected 2 excludes, got %+v", q.excludes)
+	}
+}
+
+// searchMonitor 创建包含给定内容的 Monitor，第一个参数为最新的条目
+func searchMonitor(contents ...string) *Monitor {
+	monitor := NewMonitor(100)
+	now := time.Now()
+	for i := len(contents) - 1; i >= 0; i-- {
+		monitor.addToHistory(ClipboardEntry{Content: contents[i], Timestamp: now.Add(-time.Duration(i) * time.Minute)})
+	}
+	return monitor
+}
+
+// resultContents 返回搜索结果的内容
+func resultContents(results []SearchResult) []string {
+	contents := make([]string, 0, len(results))
+	for _, r := range results {
+		contents = append(contents, r.Entry.Content)
+	}
+	return contents
+}
+
+func TestSearchBoolean(t *testing.T) {
+	monitor := searchMonitor(
+		"select * from users",
+		"ssh root@server",
+		"剪贴板监控工具",
+		"监控板卡",
+		"config.yaml",
+	)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"users", []string{"select * from users"}},
+		{"SELECT users", []string{"select * from users"}},
+		{"users OR ssh", []string{"select * from users", "ssh root@server"}},
+		{"剪贴板", []string{"剪贴板监控工具"}},
+		{"监控 -工具", []string{"监控板卡"}},
+		{"conf*", []string{"config.yaml"}},
+		{`"root server"`, []string{"ssh root@server"}},
+		{`"server root"`, nil},
+		{"板 NOT 卡", []string{"剪贴板监控工具"}},
+		{"", nil},
+	}
+	for _, tc := range cases {
+		got := resultContents(monitor.Search(tc.query, 0))
+		if len(got) != len(tc.want) {
+			t.Errorf("Search(%q) = %v, want %v", tc.query, got, tc.want)
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, c := range got {
+			seen[c] = true
+		}
+		for _, c := range tc.want {
+			if !seen[c] {
+				t.Errorf("Search(%q) = %v, want %v", tc.query, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	monitor := searchMonitor("复制 Go 代码到剪贴板，再从剪贴板粘贴")
+	results := monitor.Search("剪贴板 go", 0)
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	want := []Span{{3, 5}, {9, 12}, {15, 18}}
+	if !reflect.DeepEqual(results[0].Matches, want) {
+		t.Errorf("Expected matches %v, got %v", want, results[0].Matches)
+	}
+}
+
+func TestSearchPinyin(t *testing.T) {
+	monitor := searchMonitor("剪贴板历史", "银行账号", "长度限制", "jtb release notes")
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"jtb", []string{"jtb release notes", "剪贴板历史"}},
+		{"jiantieban", []string{"剪贴板历史"}},
+		{"jiantb", []string{"剪贴板历史"}},
+		{"yinhang", []string{"银行账号"}},
+		{"yh", []string{"银行账号"}},
+		{"changdu OR zhangdu", []string{"长度限制"}},
+		{"jtb -release", []string{"剪贴板历史"}},
+		{"lish*", []string{"剪贴板历史"}},
+	}
+	for _, tc := range cases {
+		got := resultContents(monitor.Search(tc.query, 0))
+		sort.Strings(got)
+		sort.Strings(tc.want)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Search(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	// 高亮对应的汉字
+	results := monitor.Search("jtbls", 0)
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Matches, []Span{{0, 5}}) {
+		t.Errorf("Unexpected pinyin matches: %+v", results)
+	}
+
+	// 拼音索引随条目删除
+	for _, entry := range monitor.GetHistory() {
+		monitor.DeleteEntry(entry.ID)
+	}
+	if len(monitor.index.pinyin) != 0 {
+		t.Errorf("Expected empty pinyin index, got %d keys", len(monitor.index.pinyin))
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	monitor := NewMonitor(100)
+	now := time.Now()
+	// 较旧但多次出现关键词的条目相关度更高
+	monitor.addToHistory(ClipboardEntry{Content: "deploy deploy deploy", Timestamp: now.Add(-time.Hour)})
+	monitor.addToHistory(ClipboardEntry{Content: "deploy the service to production now", Timestamp: now})
+	results := monitor.Search("deploy", 0)
+	if len(results) != 2 || results[0].Entry.Content != "deploy deploy deploy" {
+		t.Fatalf("Expected term frequency to win, got %v", resultContents(results))
+	}
+
+	// 相关度相同时较新的条目在前
+	monitor = NewMonitor(100)
+	monitor.addToHistory(ClipboardEntry{Content: "token abc", Timestamp: now.Add(-48 * time.Hour)})
+	monitor.addToHistory(ClipboardEntry{Content: "token xyz", Timestamp: now})
+	results = monitor.Search("token", 1)
+	if len(results) != 1 || results[0].Entry.Content != "token xyz" {
+		t.Errorf("Expected newest entry first with limit, got %v", resultContents(results))
+	}
+}
+
+func TestSearchIndexFollowsChanges(t *testing.T) {
+	monitor := searchMonitor("alpha", "beta")
+	alpha := monitor.GetHistory()[0]
+
+	monitor.UpdateEntry(alpha.ID, func(entry *ClipboardEntry) { entry.Content = "gamma" })
+	if len(monitor.Search("alpha", 0)) != 0 || len(monitor.Search("gamma", 0)) != 1 {
+		t.Error("Expected index to follow UpdateEntry")
+	}
+
+	monitor.DeleteEntry(alpha.ID)
+	if len(monitor.Search("gamma", 0)) != 0 {
+		t.Error("Expected index to follow DeleteEntry")
+	}
+
+	monitor.SetMaxHistory(1)
+	monitor.addToHistory(ClipboardEntry{Content: "delta", Timestamp: time.Now()})
+	if len(monitor.Search("beta", 0)) != 0 {
+		t.Error("Expected evicted entries to leave the index")
+	}
+
+	monitor.ClearHistory()
+	if len(monitor.Search("delta", 0)) != 0 {
+		t.Error("Expected index to be empty after ClearHistory")
+	}
+	if len(monitor.index.postings) != 0 || monitor.index.totalLen != 0 {
+		t.Errorf("Expected empty index, got %+v", monitor.index)
+	}
+
+	store := &memoryStore{}
+	store.Put(ClipboardEntry{ID: "stored", Content: "from store"})
+	monitor.AttachStore(store)
+	if len(monitor.Search("store", 0)) != 1 {
+		t.Error("Expected entries loaded from store to be indexed")
+	}
+}
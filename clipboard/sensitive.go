@@ -0,0 +1,462 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Policy 检测到敏感内容时的处理策略
+type Policy string
+
+// 处理策略
+const (
+	PolicyAllow Policy = "allow" // 照常记录
+	PolicySkip  Policy = "skip"  // 不记录
+	PolicyMask  Policy = "mask"  // 记录遮盖了敏感部分的内容，不保留其他格式
+	PolicyTTL   Policy = "ttl"   // 记录原内容，但不持久化，并在短时间后自动删除
+)
+
+// 内置检测器名称
+const (
+	DetectorPrivateKey = "private_key" // PEM 格式的私钥
+	DetectorToken      = "token"       // 带有已知前缀的访问令牌和 API 密钥
+	DetectorJWT        = "jwt"         // JSON Web Token
+	DetectorCard       = "card"        // 通过 Luhn 校验的银行卡号
+	DetectorPassword   = "password"    // 看起来像随机生成的密码
+)
+
+// DefaultSensitiveTTL 策略为 PolicyTTL 的条目默认保留时间
+const DefaultSensitiveTTL = 2 * time.Minute
+
+// maskText 遮盖敏感部分使用的文本
+const maskText = "••••••••"
+
+// ParsePolicy 解析策略名称
+func ParsePolicy(name string) (Policy, error) {
+	switch p := Policy(name); p {
+	case PolicyAllow, PolicySkip, PolicyMask, PolicyTTL:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown sensitive content policy: %s", name)
+}
+
+// Detector 敏感内容检测器
+type Detector interface {
+	// Name 检测器名称，用于配置策略
+	Name() string
+	// Detect 返回文本中敏感内容的字节区间 [start, end)，没有时返回 nil
+	Detect(text string) [][]int
+}
+
+// DefaultDetectors 返回全部内置检测器
+func DefaultDetectors() []Detector {
+	return []Detector{
+		privateKeyDetector{},
+		tokenDetector{},
+		jwtDetector{},
+		cardDetector{},
+		passwordDetector{},
+	}
+}
+
+// DefaultPolicies 返回内置检测器的默认策略：私钥和令牌不记录，
+// 银行卡号遮盖后记录，JWT 和疑似密码短时间保留
+func DefaultPolicies() map[string]Policy {
+	return map[string]Policy{
+		DetectorPrivateKey: PolicySkip,
+		DetectorToken:      PolicySkip,
+		DetectorJWT:        PolicyTTL,
+		DetectorCard:       PolicyMask,
+		DetectorPassword:   PolicyTTL,
+	}
+}
+
+// Finding 一个检测器的检测结果
+type Finding struct {
+	Detector string
+	Spans    [][]int
+}
+
+// SensitiveFilter 在条目加入历史记录前运行检测器，按检测器的策略决定如何记录
+type SensitiveFilter struct {
+	detectors []Detector
+	policies  map[string]Policy
+	ttl       time.Duration
+}
+
+// NewSensitiveFilter 创建使用内置检测器的过滤器
+//
+// policies 中没有列出的检测器使用 DefaultPolicies 中的策略；ttl 不大于 0 时使用 DefaultSensitiveTTL。
+func NewSensitiveFilter(policies map[string]Policy, ttl time.Duration) *SensitiveFilter {
+	merged := DefaultPolicies()
+	for name, policy := range policies {
+		merged[name] = policy
+	}
+	if ttl <= 0 {
+		ttl = DefaultSensitiveTTL
+	}
+	return &SensitiveFilter{detectors: DefaultDetectors(), policies: merged, ttl: ttl}
+}
+
+// AddDetector 添加自定义检测器及其策略
+func (f *SensitiveFilter) AddDetector(detector Detector, policy Policy) {
+	f.detectors = append(f.detectors, detector)
+	f.policies[detector.Name()] = policy
+}
+
+// Policy 返回检测器的策略，未知的检测器返回 PolicyAllow
+func (f *SensitiveFilter) Policy(name string) Policy {
+	if policy, ok := f.policies[name]; ok {
+		return policy
+	}
+	return PolicyAllow
+}
+
+// Scan 运行全部检测器，返回有结果的检测器
+func (f *SensitiveFilter) Scan(text string) []Finding {
+	var findings []Finding
+	for _, detector := range f.detectors {
+		if spans := detector.Detect(text); len(spans) > 0 {
+			findings = append(findings, Finding{Detector: detector.Name(), Spans: spans})
+		}
+	}
+	return findings
+}
+
+// Apply 按策略处理条目，返回处理后的条目以及是否应该记录
+//
+// 多个检测器有结果时取最严格的策略：任一为 PolicySkip 则不记录；
+// PolicyMask 的区间全部遮盖；任一为 PolicyTTL 则设置 ExpiresAt。过滤器为 nil 时原样返回。
+func (f *SensitiveFilter) Apply(entry ClipboardEntry) (ClipboardEntry, bool) {
+	if f == nil || entry.Content == "" {
+		return entry, true
+	}
+
+	var masked [][]int
+	ttl := false
+	for _, finding := range f.Scan(entry.Content) {
+		switch f.Policy(finding.Detector) {
+		case PolicySkip:
+			return ClipboardEntry{}, false
+		case PolicyMask:
+			masked = append(masked, finding.Spans...)
+		case PolicyTTL:
+			ttl = true
+		}
+	}
+
+	if len(masked) > 0 {
+		// 其他格式（如 HTML）中同样包含敏感内容，只保留遮盖后的文本
+		entry.Content = maskSpans(entry.Content, masked)
+		entry.Formats = nil
+	}
+	if ttl {
+		entry.ExpiresAt = time.Now().Add(f.ttl)
+	}
+	return entry, true
+}
+
+// maskSpans 将文本中的区间替换为 maskText，重叠的区间会被合并
+func maskSpans(text string, spans [][]int) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		start, end := span[0], span[1]
+		if end <= last {
+			continue
+		}
+		if start < last {
+			start = last
+		}
+		b.WriteString(text[last:start])
+		if start == span[0] {
+			b.WriteString(maskText)
+		}
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// privateKeyDetector 检测 PEM 格式的私钥，缺少结束标记时遮盖到文本末尾
+type privateKeyDetector struct{}
+
+var privateKeyPattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----(?:.*?-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----|.*)`)
+
+func (privateKeyDetector) Name() string { return DetectorPrivateKey }
+
+func (privateKeyDetector) Detect(text string) [][]int {
+	if !strings.Contains(text, "PRIVATE KEY") {
+		return nil
+	}
+	return privateKeyPattern.FindAllStringIndex(text, -1)
+}
+
+// tokenDetector 检测带有已知前缀的访问令牌和 API 密钥
+type tokenDetector struct{}
+
+var tokenPattern = regexp.MustCompile(`\b(?:` + strings.Join([]string{
+	`gh[pousr]_[A-Za-z0-9]{36,255}`,            // GitHub
+	`github_pat_[A-Za-z0-9_]{22,255}`,          // GitHub 细粒度令牌
+	`glpat-[A-Za-z0-9_-]{20,}`,                 // GitLab
+	`xox[abposr]-[A-Za-z0-9-]{10,}`,            // Slack
+	`(?:AKIA|ASIA)[A-Z0-9]{16}\b`,              // AWS 访问密钥 ID
+	`[sr]k_(?:live|test)_[A-Za-z0-9]{16,}`,     // Stripe
+	`sk-(?:[A-Za-z0-9]+-)*[A-Za-z0-9_-]{32,}`,  // OpenAI、Anthropic 等
+	`AIza[A-Za-z0-9_-]{35}`,                    // Google API
+	`npm_[A-Za-z0-9]{36}\b`,                    // npm
+	`SG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43}`, // SendGrid
+	`hf_[A-Za-z0-9]{34,}`,                      // Hugging Face
+}, "|") + `)`)
+
+func (tokenDetector) Name() string { return DetectorToken }
+
+func (tokenDetector) Detect(text string) [][]int {
+	return tokenPattern.FindAllStringIndex(text, -1)
+}
+
+// jwtDetector 检测 JWT：三段 base64url，头部解码后是带有 alg 字段的 JSON 对象
+type jwtDetector struct{}
+
+var jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`)
+
+func (jwtDetector) Name() string { return DetectorJWT }
+
+func (jwtDetector) Detect(text string) [][]int {
+	var spans [][]int
+	for _, span := range jwtPattern.FindAllStringIndex(text, -1) {
+		header := text[span[0] : span[0]+strings.IndexByte(text[span[0]:], '.')]
+		data, err := base64.RawURLEncoding.DecodeString(header)
+		if err != nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if json.Unmarshal(data, &fields) != nil {
+			continue
+		}
+		if _, ok := fields["alg"]; ok {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// cardDetector 检测银行卡号：13-19 位数字（可用空格或连字符分组），
+// 发卡行前缀和长度匹配且通过 Luhn 校验
+type cardDetector struct{}
+
+var cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+func (cardDetector) Name() string { return DetectorCard }
+
+func (cardDetector) Detect(text string) [][]int {
+	var spans [][]int
+	for _, span := range cardPattern.FindAllStringIndex(text, -1) {
+		digits := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '-' {
+				return -1
+			}
+			return r
+		}, text[span[0]:span[1]])
+		if cardIssuer(digits) && luhnValid(digits) {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// cardIssuer 卡号的前缀和长度是否属于常见的卡组织
+func cardIssuer(digits string) bool {
+	n := len(digits)
+	prefix := func(k int) int {
+		v := 0
+		for _, c := range digits[:k] {
+			v = v*10 + int(c-'0')
+		}
+		return v
+	}
+	switch {
+	case digits[0] == '4': // Visa
+		return n == 13 || n == 16 || n == 19
+	case prefix(2) >= 51 && prefix(2) <= 55, prefix(4) >= 2221 && prefix(4) <= 2720: // Mastercard
+		return n == 16
+	case prefix(2) == 34 || prefix(2) == 37: // American Express
+		return n == 15
+	case prefix(2) == 36, prefix(3) >= 300 && prefix(3) <= 305, prefix(2) == 38 || prefix(2) == 39: // Diners Club
+		return n >= 14 && n <= 19
+	case prefix(4) >= 3528 && prefix(4) <= 3589: // JCB
+		return n >= 16 && n <= 19
+	case prefix(4) == 6011, prefix(2) == 65, prefix(3) >= 644 && prefix(3) <= 649, prefix(2) == 62: // Discover、银联
+		// 18 位的数字更可能是身份证号
+		return n == 16 || n == 17 || n == 19
+	}
+	return false
+}
+
+// luhnValid Luhn 校验
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// passwordDetector 检测整段内容是否像随机生成的密码
+//
+// 只检查不含空白的单个词：长度 10-64 的可打印 ASCII，同时包含大小写字母以及数字或符号，
+// 字符分布的熵足够高，并且字母大多不构成可读的单词或字符类别频繁切换（排除驼峰命名的标识符、文件名等）。
+type passwordDetector struct{}
+
+var (
+	emailPattern    = regexp.MustCompile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
+	uuidPattern     = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+	fileNamePattern = regexp.MustCompile(`\.[A-Za-z][A-Za-z0-9]{0,4}$`)
+)
+
+func (passwordDetector) Name() string { return DetectorPassword }
+
+func (passwordDetector) Detect(text string) [][]int {
+	word := strings.TrimSpace(text)
+	if len(word) < 10 || len(word) > 64 || !looksRandom(word) {
+		return nil
+	}
+	start := strings.Index(text, word)
+	return [][]int{{start, start + len(word)}}
+}
+
+// looksRandom 单个词是否像随机生成的密码
+func looksRandom(word string) bool {
+	var lower, upper, digit, symbol bool
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= '0' && c <= '9':
+			digit = true
+		case c > ' ' && c < utf8.RuneSelf && c != 0x7f:
+			symbol = true
+		default:
+			// 空白、控制字符和非 ASCII 字符
+			return false
+		}
+	}
+	if !lower || !upper || !(digit || symbol) {
+		return false
+	}
+	if strings.Contains(word, "://") || strings.HasPrefix(word, "www.") ||
+		emailPattern.MatchString(word) || uuidPattern.MatchString(word) ||
+		fileNamePattern.MatchString(word) || strings.Count(word, "/")+strings.Count(word, `\`) > 1 {
+		return false
+	}
+	if shannonEntropy(word) < 3 {
+		return false
+	}
+	// 随机字符串中偶尔会出现像单词的片段，字符类别频繁切换时放宽要求
+	words := wordLikeRatio(word)
+	return words < 0.4 || words < 0.6 && classSwitchRate(word) >= 0.5
+}
+
+// classSwitchRate 相邻字符属于不同类别（小写、大写、数字、符号）的比例，
+// 大写字母后接小写字母（首字母大写的单词）不计入
+func classSwitchRate(s string) float64 {
+	class := func(c byte) int {
+		switch {
+		case c >= 'a' && c <= 'z':
+			return 0
+		case c >= 'A' && c <= 'Z':
+			return 1
+		case c >= '0' && c <= '9':
+			return 2
+		}
+		return 3
+	}
+	switches := 0
+	for i := 1; i < len(s); i++ {
+		prev, cur := class(s[i-1]), class(s[i])
+		if prev != cur && !(prev == 1 && cur == 0) {
+			switches++
+		}
+	}
+	return float64(switches) / float64(len(s)-1)
+}
+
+// shannonEntropy 字符分布的香农熵（比特/字符）
+func shannonEntropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(s))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// wordLikeRatio 按驼峰规则把字母切分成片段，返回属于可读片段（至少 3 个字母且含有元音）的字母比例
+func wordLikeRatio(s string) float64 {
+	letters, wordLetters := 0, 0
+	segment, vowel := 0, false
+	flush := func() {
+		if segment >= 3 && vowel {
+			wordLetters += segment
+		}
+		segment, vowel = 0, false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		isUpper := c >= 'A' && c <= 'Z'
+		isLower := c >= 'a' && c <= 'z'
+		if !isUpper && !isLower {
+			flush()
+			continue
+		}
+		// 大写字母在小写字母之后，或在连续大写之后且后接小写时开始新的片段，
+		// 如 getUser 切分为 get、User，XMLHttp 切分为 XML、Http
+		if isUpper && i > 0 {
+			prevLower := s[i-1] >= 'a' && s[i-1] <= 'z'
+			prevUpper := s[i-1] >= 'A' && s[i-1] <= 'Z'
+			nextLower := i+1 < len(s) && s[i+1] >= 'a' && s[i+1] <= 'z'
+			if prevLower || prevUpper && nextLower {
+				flush()
+			}
+		}
+		letters++
+		segment++
+		if strings.IndexByte("aeiouyAEIOUY", c) >= 0 {
+			vowel = true
+		}
+	}
+	flush()
+
+	if letters == 0 {
+		return 0
+	}
+	return float64(wordLetters) / float64(letters)
+}
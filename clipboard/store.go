@@ -0,0 +1,14 @@
+package clipboard
+
+// Store 历史记录持久化接口
+//
+// Load 返回按最新优先排序的条目；Put 表示新增条目或将已有条目移动到顶部；
+// Update 原位修改条目而不改变顺序；Delete 与 Clear 分别对应删除单个条目和清空历史。
+// 条目按 ID 识别，实现需要原样保存 ID。
+type Store interface {
+	Load() ([]ClipboardEntry, error)
+	Put(entry ClipboardEntry) error
+	Update(entry ClipboardEntry) error
+	Delete(entry ClipboardEntry) error
+	Clear() error
+}
@@ -0,0 +1,204 @@
+package clipboard
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidTag 标签名为空
+var ErrInvalidTag = errors.New("invalid tag name")
+
+// TagInfo 标签及使用该标签的条目数
+type TagInfo struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// normalizeTag 去掉标签名首尾的空白，结果为空时返回 ErrInvalidTag
+func normalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", ErrInvalidTag
+	}
+	return tag, nil
+}
+
+// normalizeTags 规范化并去重标签名
+func normalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !hasTag(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+// hasTag 标签列表中是否包含 tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag 条目是否带有指定标签
+func (e ClipboardEntry) HasTag(tag string) bool {
+	return hasTag(e.Tags, tag)
+}
+
+// setTags 修改第 i 个条目的标签，有变化时持久化并发布 EventUpdated，调用方需持有写锁
+func (m *Monitor) setTags(i int, tags []string) bool {
+	old := m.history[i].Tags
+	if len(old) == len(tags) {
+		same := true
+		for j := range old {
+			if old[j] != tags[j] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return false
+		}
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	m.history[i].Tags = tags
+	m.persistUpdate(m.history[i])
+	m.changed(EventUpdated, m.history[i])
+	return true
+}
+
+// TagEntry 为指定 ID 的条目添加标签，返回修改后的条目
+//
+// 带有标签的条目不计入历史记录上限，不会被自动淘汰。
+func (m *Monitor) TagEntry(id string, tags ...string) (ClipboardEntry, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return ClipboardEntry{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return ClipboardEntry{}, ErrEntryNotFound
+	}
+	merged := append([]string(nil), m.history[i].Tags...)
+	for _, tag := range tags {
+		if !hasTag(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	m.setTags(i, merged)
+	return m.history[i], nil
+}
+
+// UntagEntry 移除指定 ID 的条目的标签，返回修改后的条目；不再带有标签的条目重新计入历史记录上限
+func (m *Monitor) UntagEntry(id string, tags ...string) (ClipboardEntry, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return ClipboardEntry{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.indexOf(id)
+	if i < 0 {
+		return ClipboardEntry{}, ErrEntryNotFound
+	}
+	var kept []string
+	for _, tag := range m.history[i].Tags {
+		if !hasTag(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	m.setTags(i, kept)
+	entry := m.history[i]
+	m.enforceRetention()
+	return entry, nil
+}
+
+// EntriesByTag 返回带有指定标签的条目，按历史记录顺序排列
+func (m *Monitor) EntriesByTag(tag string) []ClipboardEntry {
+	tag = strings.TrimSpace(tag)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]ClipboardEntry, 0)
+	for _, entry := range m.history {
+		if entry.HasTag(tag) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Tags 返回所有标签及其条目数，按名称排序
+func (m *Monitor) Tags() []TagInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, entry := range m.history {
+		for _, tag := range entry.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]TagInfo, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, TagInfo{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// RenameTag 将标签 from 重命名为 to，to 已存在时两个标签合并，返回修改的条目数
+func (m *Monitor) RenameTag(from, to string) (int, error) {
+	return m.MergeTags(to, from)
+}
+
+// MergeTags 将 sources 中的标签合并到 target：带有任一来源标签的条目改为带有 target，
+// 标签在条目中的位置保持不变，返回修改的条目数
+func (m *Monitor) MergeTags(target string, sources ...string) (int, error) {
+	target, err := normalizeTag(target)
+	if err != nil {
+		return 0, err
+	}
+	sources, err = normalizeTags(sources)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changed := 0
+	for i, entry := range m.history {
+		var tags []string
+		for _, tag := range entry.Tags {
+			if hasTag(sources, tag) {
+				tag = target
+			}
+			if !hasTag(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if m.setTags(i, tags) {
+			changed++
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,98 @@
+package clipboard
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTagEntry(t *testing.T) {
+	monitor := pinMonitor(10, nil, "select 1", "ssh prod", "thanks!")
+	sql := findEntry(t, monitor, "select 1")
+
+	entry, err := monitor.TagEntry(sql.ID, " SQL ", "work", "SQL")
+	if err != nil {
+		t.Fatalf("TagEntry failed: %v", err)
+	}
+	if !reflect.DeepEqual(entry.Tags, []string{"SQL", "work"}) {
+		t.Errorf("Unexpected tags: %v", entry.Tags)
+	}
+	if _, err := monitor.TagEntry(findEntry(t, monitor, "ssh prod").ID, "work"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monitor.TagEntry(sql.ID, "  "); err != ErrInvalidTag {
+		t.Errorf("Expected ErrInvalidTag, got %v", err)
+	}
+	if _, err := monitor.TagEntry("missing", "work"); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+
+	expectContents(t, monitor.EntriesByTag("work"), "ssh prod", "select 1")
+	want := []TagInfo{{Name: "SQL", Count: 1}, {Name: "work", Count: 2}}
+	if got := monitor.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected tags %v, got %v", want, got)
+	}
+
+	entry, err = monitor.UntagEntry(sql.ID, "work")
+	if err != nil {
+		t.Fatalf("UntagEntry failed: %v", err)
+	}
+	if !reflect.DeepEqual(entry.Tags, []string{"SQL"}) {
+		t.Errorf("Unexpected tags after untag: %v", entry.Tags)
+	}
+	expectContents(t, monitor.EntriesByTag("work"), "ssh prod")
+}
+
+func TestRenameAndMergeTags(t *testing.T) {
+	monitor := pinMonitor(10, nil, "a", "b", "c")
+	a, b, c := findEntry(t, monitor, "a"), findEntry(t, monitor, "b"), findEntry(t, monitor, "c")
+	monitor.TagEntry(a.ID, "srv", "ops")
+	monitor.TagEntry(b.ID, "servers")
+	monitor.TagEntry(c.ID, "misc")
+
+	if n, err := monitor.RenameTag("misc", "replies"); err != nil || n != 1 {
+		t.Errorf("RenameTag = %d, %v", n, err)
+	}
+	// 合并后条目中的重复标签只保留一个，位置不变
+	monitor.TagEntry(b.ID, "srv")
+	if n, err := monitor.MergeTags("servers", "srv"); err != nil || n != 2 {
+		t.Errorf("MergeTags = %d, %v", n, err)
+	}
+	if got := findEntry(t, monitor, "a").Tags; !reflect.DeepEqual(got, []string{"servers", "ops"}) {
+		t.Errorf("Unexpected tags for a: %v", got)
+	}
+	if got := findEntry(t, monitor, "b").Tags; !reflect.DeepEqual(got, []string{"servers"}) {
+		t.Errorf("Unexpected tags for b: %v", got)
+	}
+	want := []TagInfo{{Name: "ops", Count: 1}, {Name: "replies", Count: 1}, {Name: "servers", Count: 2}}
+	if got := monitor.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected tags %v, got %v", want, got)
+	}
+	if _, err := monitor.RenameTag("ops", ""); err != ErrInvalidTag {
+		t.Errorf("Expected ErrInvalidTag, got %v", err)
+	}
+}
+
+func TestTaggedEntriesSurviveEviction(t *testing.T) {
+	store := &memoryStore{}
+	monitor := pinMonitor(2, store, "a", "b")
+	a := findEntry(t, monitor, "a")
+	monitor.TagEntry(a.ID, "keep")
+
+	monitor.mu.Lock()
+	for _, content := range []string{"c", "d", "e"} {
+		monitor.addToHistory(ClipboardEntry{Content: content, Timestamp: time.Now()})
+	}
+	monitor.mu.Unlock()
+	// 带有标签的条目保持原来的位置，只淘汰超出上限的未标记条目
+	expectContents(t, monitor.GetHistory(), "e", "d", "a")
+
+	reloaded := NewMonitor(2, WithBackend(NewMemoryBackend()), WithStore(store))
+	expectContents(t, reloaded.EntriesByTag("keep"), "a")
+
+	// 移除标签后重新计入上限
+	if _, err := monitor.UntagEntry(a.ID, "keep"); err != nil {
+		t.Fatal(err)
+	}
+	expectContents(t, monitor.GetHistory(), "e", "d")
+}
@@ -0,0 +1,65 @@
+package clipboard
+
+import (
+	"unicode"
+)
+
+// token 分词结果中的一个词，Start/End 为在原文中的字符（rune）偏移，左闭右开
+type token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// tokenize 对文本分词并规范化（大小写、全角半角折叠）
+//
+// 拉丁字母和数字按连续的单词切分；中日韩文字没有空格分隔，按单字切分，
+// 多字词的匹配依靠相邻位置的短语查询完成。
+func tokenize(text string) []token {
+	var tokens []token
+	var word []rune
+	wordStart := 0
+
+	flush := func(end int) {
+		if len(word) > 0 {
+			tokens = append(tokens, token{Text: string(word), Start: wordStart, End: end})
+			word = word[:0]
+		}
+	}
+
+	pos := 0
+	for _, r := range text {
+		r = foldRune(r)
+		switch {
+		case isCJK(r):
+			flush(pos)
+			tokens = append(tokens, token{Text: string(r), Start: pos, End: pos + 1})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			if len(word) == 0 {
+				wordStart = pos
+			}
+			word = append(word, r)
+		default:
+			flush(pos)
+		}
+		pos++
+	}
+	flush(pos)
+	return tokens
+}
+
+// foldRune 将全角 ASCII 字符和全角空格转换为半角，并转换为小写
+func foldRune(r rune) rune {
+	switch {
+	case r >= 0xff01 && r <= 0xff5e:
+		r -= 0xfee0
+	case r == 0x3000:
+		r = ' '
+	}
+	return unicode.ToLower(r)
+}
+
+// isCJK 是否为按单字切分的中日韩文字
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
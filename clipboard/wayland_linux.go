@@ -0,0 +1,103 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"clipboard-monitor/wayland"
+	"context"
+)
+
+// textMIMETypes Wayland 客户端常用的纯文本类型，按优先级排列
+var textMIMETypes = []string{"text/plain;charset=utf-8", "UTF8_STRING", "text/plain", "TEXT", "STRING"}
+
+// WaylandBackend 基于 data-control 协议的原生 Wayland 后端，
+// 在后台也能收到选区变化事件，不需要调用 wl-paste
+type WaylandBackend struct {
+	client *wayland.Clipboard
+}
+
+// NewWaylandBackend 连接合成器，display 为空时使用 WAYLAND_DISPLAY。
+// 合成器不支持 data-control 协议时返回 wayland.ErrUnsupported。
+func NewWaylandBackend(display string) (*WaylandBackend, error) {
+	client, err := wayland.Connect(display)
+	if err != nil {
+		return nil, err
+	}
+	return &WaylandBackend{client: client}, nil
+}
+
+// ReadAll 读取剪贴板文本
+func (b *WaylandBackend) ReadAll() (string, error) {
+	formats, err := b.ReadFormats()
+	if err != nil {
+		return "", err
+	}
+	return string(formats[MIMEText]), nil
+}
+
+// WriteAll 写入文本到剪贴板
+func (b *WaylandBackend) WriteAll(text string) error {
+	return b.WriteFormats(map[string][]byte{MIMEText: []byte(text)})
+}
+
+// ReadFormats 读取当前选区提供的文本、HTML、RTF、PNG 图片和文件列表
+func (b *WaylandBackend) ReadFormats() (map[string][]byte, error) {
+	offered := b.client.MimeTypes()
+	formats := make(map[string][]byte)
+
+	for _, mime := range textMIMETypes {
+		if !containsString(offered, mime) {
+			continue
+		}
+		data, err := b.client.Receive(mime)
+		if err != nil {
+			return nil, err
+		}
+		formats[MIMEText] = data
+		break
+	}
+
+	for _, mime := range richFormats {
+		if !containsString(offered, mime) {
+			continue
+		}
+		if data, err := b.client.Receive(mime); err == nil && len(data) > 0 {
+			formats[mime] = data
+		}
+	}
+	return formats, nil
+}
+
+// WriteFormats 以全部格式提供数据，纯文本同时以常见的别名提供
+func (b *WaylandBackend) WriteFormats(formats map[string][]byte) error {
+	offered := make(map[string][]byte, len(formats)+len(textMIMETypes))
+	for mime, data := range formats {
+		if mime == MIMEText {
+			for _, alias := range textMIMETypes {
+				offered[alias] = data
+			}
+			continue
+		}
+		offered[mime] = data
+	}
+	return b.client.SetSelection(offered)
+}
+
+// Watch 实现 Watcher 接口，等待合成器的选区事件
+func (b *WaylandBackend) Watch(ctx context.Context, notify func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.client.Done():
+			return b.client.Err()
+		case <-b.client.Changed():
+			notify()
+		}
+	}
+}
+
+// Close 断开与合成器的连接
+func (b *WaylandBackend) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,299 @@
+package clipboard
+
+// Windows 剪贴板格式与 MIME 数据之间的转换。转换本身不依赖系统调用，
+// 放在这里以便在所有平台上测试，读写剪贴板见 formats_windows.go。
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// cfHTMLHeader CF_HTML 的描述头，偏移量是从数据开头起算的字节数，固定宽度以便先计算头部长度
+const cfHTMLHeader = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\nStartFragment:%010d\r\nEndFragment:%010d\r\n"
+
+// encodeCFHTML 把 HTML 片段包装为 CF_HTML 格式
+func encodeCFHTML(fragment []byte) []byte {
+	const (
+		prefix = "<html><body>\r\n<!--StartFragment-->"
+		suffix = "<!--EndFragment-->\r\n</body></html>"
+	)
+	startHTML := len(fmt.Sprintf(cfHTMLHeader, 0, 0, 0, 0))
+	startFragment := startHTML + len(prefix)
+	endFragment := startFragment + len(fragment)
+	endHTML := endFragment + len(suffix)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, cfHTMLHeader, startHTML, endHTML, startFragment, endFragment)
+	buf.WriteString(prefix)
+	buf.Write(fragment)
+	buf.WriteString(suffix)
+	return buf.Bytes()
+}
+
+// decodeCFHTML 从 CF_HTML 数据中取出复制的 HTML 片段，没有片段标记时返回整个文档
+func decodeCFHTML(data []byte) ([]byte, error) {
+	data = bytes.TrimRight(data, "\x00")
+
+	// 描述头是若干 "名称:值" 行，遇到 HTML 内容或无法识别的行时结束
+	offsets := make(map[string]int)
+	for rest := data; len(rest) > 0 && rest[0] != '<'; {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+		name, value, ok := strings.Cut(strings.TrimSpace(string(line)), ":")
+		if !ok {
+			break
+		}
+		if n, err := strconv.Atoi(value); err == nil {
+			offsets[name] = n
+		}
+	}
+
+	for _, names := range [][2]string{{"StartFragment", "EndFragment"}, {"StartHTML", "EndHTML"}} {
+		start, okStart := offsets[names[0]]
+		end, okEnd := offsets[names[1]]
+		if okStart && okEnd && 0 <= start && start <= end && end <= len(data) {
+			return data[start:end], nil
+		}
+	}
+	return nil, errors.New("invalid CF_HTML header")
+}
+
+// BITMAPINFOHEADER 的大小和用到的压缩方式
+const (
+	bitmapInfoHeaderSize = 40
+	biRGB                = 0
+	biBitfields          = 3
+)
+
+// dibToPNG 把 CF_DIB 位图转换为 PNG，支持截图常见的 24 位和 32 位格式
+func dibToPNG(dib []byte) ([]byte, error) {
+	img, err := decodeDIB(dib)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeDIB 解析 BITMAPINFOHEADER（或更新的 V4/V5 头）及其后的像素数据
+func decodeDIB(dib []byte) (image.Image, error) {
+	if len(dib) < bitmapInfoHeaderSize {
+		return nil, errors.New("DIB too short")
+	}
+	le := binary.LittleEndian
+	headerSize := int(le.Uint32(dib[0:]))
+	width := int(int32(le.Uint32(dib[4:])))
+	height := int(int32(le.Uint32(dib[8:])))
+	bitCount := int(le.Uint16(dib[14:]))
+	compression := le.Uint32(dib[16:])
+
+	if headerSize < bitmapInfoHeaderSize || headerSize > len(dib) {
+		return nil, fmt.Errorf("invalid DIB header size %d", headerSize)
+	}
+	if bitCount != 24 && bitCount != 32 {
+		return nil, fmt.Errorf("unsupported DIB bit count %d", bitCount)
+	}
+	offset := headerSize
+	switch {
+	case compression == biRGB:
+	case compression == biBitfields && bitCount == 32:
+		// 旧的头部之后跟着三个颜色掩码，V4/V5 头部已包含掩码，只接受常见的 BGRA 排列
+		masks := dib[40:]
+		if headerSize == bitmapInfoHeaderSize {
+			offset += 12
+		}
+		if len(masks) < 12 || le.Uint32(masks[0:]) != 0xff0000 ||
+			le.Uint32(masks[4:]) != 0xff00 || le.Uint32(masks[8:]) != 0xff {
+			return nil, errors.New("unsupported DIB color masks")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported DIB compression %d", compression)
+	}
+
+	// 高度为正时像素自下而上存储
+	bottomUp := height > 0
+	if !bottomUp {
+		height = -height
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid DIB size %dx%d", width, height)
+	}
+	stride := (width*bitCount + 31) / 32 * 4
+	if offset > len(dib) || (len(dib)-offset)/stride < height {
+		return nil, errors.New("DIB pixel data truncated")
+	}
+	pixels := dib[offset:]
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := y
+		if bottomUp {
+			row = height - 1 - y
+		}
+		src := pixels[row*stride:]
+		for x := 0; x < width; x++ {
+			p := src[x*bitCount/8:]
+			c := color.NRGBA{R: p[2], G: p[1], B: p[0], A: 0xff}
+			if bitCount == 32 {
+				c.A = p[3]
+				hasAlpha = hasAlpha || p[3] != 0
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	// 32 位位图的第四个字节通常不使用，全为 0 时视为不透明
+	if bitCount == 32 && !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img, nil
+}
+
+// pngToDIB 把 PNG 转换为 32 位自下而上的 CF_DIB 位图，供只识别 CF_DIB 的程序粘贴
+func pngToDIB(data []byte) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dib := make([]byte, bitmapInfoHeaderSize+width*height*4)
+	le := binary.LittleEndian
+	le.PutUint32(dib[0:], bitmapInfoHeaderSize)
+	le.PutUint32(dib[4:], uint32(width))
+	le.PutUint32(dib[8:], uint32(height))
+	le.PutUint16(dib[12:], 1) // 位面数
+	le.PutUint16(dib[14:], 32)
+	le.PutUint32(dib[16:], biRGB)
+	le.PutUint32(dib[20:], uint32(width*height*4))
+
+	pixels := dib[bitmapInfoHeaderSize:]
+	for y := 0; y < height; y++ {
+		row := pixels[(height-1-y)*width*4:]
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			copy(row[x*4:], []byte{c.B, c.G, c.R, c.A})
+		}
+	}
+	return dib, nil
+}
+
+// dropFilesSize DROPFILES 结构的大小：文件列表偏移、放置坐标、是否非客户区、是否为宽字符
+const dropFilesSize = 20
+
+// parseDropFiles 解析 CF_HDROP 的 DROPFILES 结构，返回其中的文件路径
+func parseDropFiles(data []byte) ([]string, error) {
+	if len(data) < dropFilesSize {
+		return nil, errors.New("DROPFILES too short")
+	}
+	offset := int(binary.LittleEndian.Uint32(data[0:]))
+	wide := binary.LittleEndian.Uint32(data[16:]) != 0
+	if offset < dropFilesSize || offset > len(data) {
+		return nil, fmt.Errorf("invalid DROPFILES offset %d", offset)
+	}
+
+	// 文件名以 NUL 分隔，以连续两个 NUL 结束
+	var names []string
+	if wide {
+		names = strings.Split(decodeUTF16(data[offset:], true), "\x00")
+	} else {
+		names = strings.Split(string(data[offset:]), "\x00")
+	}
+	var paths []string
+	for _, name := range names {
+		if name == "" {
+			break
+		}
+		paths = append(paths, name)
+	}
+	return paths, nil
+}
+
+// buildDropFiles 生成以宽字符保存文件路径的 DROPFILES 结构
+func buildDropFiles(paths []string) []byte {
+	var list []uint16
+	for _, path := range paths {
+		list = append(list, utf16.Encode([]rune(path))...)
+		list = append(list, 0)
+	}
+	list = append(list, 0)
+
+	data := make([]byte, dropFilesSize+len(list)*2)
+	binary.LittleEndian.PutUint32(data[0:], dropFilesSize)
+	binary.LittleEndian.PutUint32(data[16:], 1)
+	for i, c := range list {
+		binary.LittleEndian.PutUint16(data[dropFilesSize+i*2:], c)
+	}
+	return data
+}
+
+// filesToURIList 把 Windows 路径转换为 text/uri-list，UNC 路径的服务器名作为主机名
+func filesToURIList(paths []string) []byte {
+	var lines []string
+	for _, path := range paths {
+		u := url.URL{Scheme: "file"}
+		slashed := strings.ReplaceAll(path, `\`, "/")
+		if strings.HasPrefix(slashed, "//") {
+			u.Host, u.Path, _ = strings.Cut(slashed[2:], "/")
+			u.Path = "/" + u.Path
+		} else {
+			u.Path = "/" + slashed
+		}
+		lines = append(lines, u.String())
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// uriListToFiles 取出 text/uri-list 中的本地文件路径，其他地址无法放入 CF_HDROP，直接忽略
+func uriListToFiles(data []byte) []string {
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		u, err := url.Parse(strings.TrimSpace(line))
+		if err == nil && u.Scheme == "file" {
+			paths = append(paths, fileURIPath(u))
+		}
+	}
+	return paths
+}
+
+// encodeUTF16 把文本编码为以 NUL 结尾的 UTF-16LE，用于 CF_UNICODETEXT
+func encodeUTF16(s string) []byte {
+	units := append(utf16.Encode([]rune(s)), 0)
+	data := make([]byte, len(units)*2)
+	for i, c := range units {
+		binary.LittleEndian.PutUint16(data[i*2:], c)
+	}
+	return data
+}
+
+// decodeUTF16 解码 UTF-16LE 数据；keepNUL 为 false 时在第一个 NUL 处截断
+func decodeUTF16(data []byte, keepNUL bool) string {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		c := binary.LittleEndian.Uint16(data[i:])
+		if c == 0 && !keepNUL {
+			break
+		}
+		units = append(units, c)
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,138 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestCFHTMLRoundtrip(t *testing.T) {
+	fragment := []byte("<table><tr><td>表格</td></tr></table>")
+	got, err := decodeCFHTML(encodeCFHTML(fragment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, fragment) {
+		t.Errorf("Expected %q, got %q", fragment, got)
+	}
+}
+
+func TestDecodeCFHTMLFromBrowser(t *testing.T) {
+	// 浏览器写入的数据带有 SourceURL，并以 NUL 结尾
+	const format = "Version:0.9\r\nStartHTML:%010d\r\nEndHTML:%010d\r\n" +
+		"StartFragment:%010d\r\nEndFragment:%010d\r\nSourceURL:https://example.com/\r\n"
+	const prefix, fragment, suffix = "<html><body><!--StartFragment-->", "<b>hi</b>", "<!--EndFragment--></body></html>"
+	start := len(fmt.Sprintf(format, 0, 0, 0, 0))
+	data := fmt.Sprintf(format, start, start+len(prefix+fragment+suffix), start+len(prefix), start+len(prefix+fragment)) +
+		prefix + fragment + suffix + "\x00"
+
+	got, err := decodeCFHTML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != fragment {
+		t.Errorf("Expected fragment, got %q", got)
+	}
+
+	if _, err := decodeCFHTML([]byte("<b>no header</b>")); err == nil {
+		t.Error("Expected error for data without header")
+	}
+}
+
+func TestDIBRoundtrip(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(2, 1, color.NRGBA{B: 255, A: 128})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	dib, err := pngToDIB(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := dibToPNG(dib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []image.Point{{0, 0}, {2, 1}, {1, 1}} {
+		want := img.NRGBAAt(p.X, p.Y)
+		if got := color.NRGBAModel.Convert(decoded.At(p.X, p.Y)); got != want {
+			t.Errorf("Pixel %v: expected %v, got %v", p, want, got)
+		}
+	}
+}
+
+func TestDecode24BitDIB(t *testing.T) {
+	// 2x2 自下而上的 24 位位图，每行补齐到 4 字节：第一行数据是图片的最后一行
+	dib := make([]byte, bitmapInfoHeaderSize, bitmapInfoHeaderSize+16)
+	le := binary.LittleEndian
+	le.PutUint32(dib[0:], bitmapInfoHeaderSize)
+	le.PutUint32(dib[4:], 2)
+	le.PutUint32(dib[8:], 2)
+	le.PutUint16(dib[12:], 1)
+	le.PutUint16(dib[14:], 24)
+	dib = append(dib,
+		0, 0, 255, 0, 255, 0, 0, 0, // 底行：红、绿
+		255, 0, 0, 255, 255, 255, 0, 0, // 顶行：蓝、白
+	)
+
+	img, err := decodeDIB(dib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[image.Point]color.NRGBA{
+		{0, 0}: {B: 255, A: 255},
+		{1, 0}: {R: 255, G: 255, B: 255, A: 255},
+		{0, 1}: {R: 255, A: 255},
+		{1, 1}: {G: 255, A: 255},
+	}
+	for p, c := range want {
+		if got := img.At(p.X, p.Y); got != c {
+			t.Errorf("Pixel %v: expected %v, got %v", p, c, got)
+		}
+	}
+
+	if _, err := decodeDIB(dib[:bitmapInfoHeaderSize+8]); err == nil {
+		t.Error("Expected error for truncated pixel data")
+	}
+}
+
+func TestDropFiles(t *testing.T) {
+	paths := []string{`C:\Users\me\报告 1.docx`, `\\server\share\a.txt`}
+	got, err := parseDropFiles(buildDropFiles(paths))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, paths) {
+		t.Errorf("Expected %v, got %v", paths, got)
+	}
+
+	uris := filesToURIList(paths)
+	if want := "file:///C:/Users/me/%E6%8A%A5%E5%91%8A%201.docx\r\nfile://server/share/a.txt\r\n"; string(uris) != want {
+		t.Errorf("Unexpected uri-list %q", uris)
+	}
+	if got := uriListToFiles(append(uris, "https://example.com/\r\n"...)); !reflect.DeepEqual(got, paths) {
+		t.Errorf("Expected %v, got %v", paths, got)
+	}
+	if got := ParseURIList(uris); !reflect.DeepEqual(got, paths) {
+		t.Errorf("Expected %v, got %v", paths, got)
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	data := encodeUTF16("剪贴板 😀")
+	if got := decodeUTF16(data, false); got != "剪贴板 😀" {
+		t.Errorf("Unexpected text %q", got)
+	}
+}
@@ -0,0 +1,214 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xfixes"
+	"github.com/jezek/xgb/xproto"
+)
+
+// 选区名称
+const (
+	SelectionClipboard = "CLIPBOARD"
+	SelectionPrimary   = "PRIMARY"
+)
+
+// X11Backend 通过 XFixes 扩展订阅选区所有者变化，取代定时轮询。
+// 读取仍委托给 SystemBackend，只在收到变化通知时才读取；
+// 写入时由本程序持有 CLIPBOARD 选区，一次提供条目的全部格式。
+type X11Backend struct {
+	*SystemBackend
+	display string
+	primary bool
+
+	mu        sync.Mutex
+	selection string    // 最近一次发生变化的选区
+	owner     *x11Owner // 本程序最近一次写入时持有的选区
+}
+
+// NewX11Backend 连接指定的 X 显示并检查 XFixes 扩展，watchPrimary 为 true 时同时记录 PRIMARY 选区
+func NewX11Backend(display string, watchPrimary bool) (*X11Backend, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to X display: %v", err)
+	}
+	defer conn.Close()
+
+	if err := initXFixes(conn); err != nil {
+		return nil, err
+	}
+
+	return &X11Backend{
+		SystemBackend: NewSystemBackend(),
+		display:       display,
+		primary:       watchPrimary,
+		selection:     SelectionClipboard,
+	}, nil
+}
+
+// initXFixes 初始化 XFixes 扩展，SelectSelectionInput 需要 2.0 以上版本
+func initXFixes(conn *xgb.Conn) error {
+	if err := xfixes.Init(conn); err != nil {
+		return fmt.Errorf("XFixes extension unavailable: %v", err)
+	}
+	reply, err := xfixes.QueryVersion(conn, 5, 0).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to query XFixes version: %v", err)
+	}
+	if reply.MajorVersion < 2 {
+		return fmt.Errorf("XFixes %d.%d is too old", reply.MajorVersion, reply.MinorVersion)
+	}
+	return nil
+}
+
+// Watch 实现 Watcher 接口，阻塞等待 XFixesSelectionNotify 事件
+func (b *X11Backend) Watch(ctx context.Context, notify func()) error {
+	conn, err := xgb.NewConnDisplay(b.display)
+	if err != nil {
+		return fmt.Errorf("failed to connect to X display: %v", err)
+	}
+	defer conn.Close()
+
+	if err := initXFixes(conn); err != nil {
+		return err
+	}
+
+	// 创建一个不可见窗口用于接收事件
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		return err
+	}
+	err = xproto.CreateWindowChecked(conn, 0, win, root, 0, 0, 1, 1, 0,
+		xproto.WindowClassInputOnly, 0, 0, nil).Check()
+	if err != nil {
+		return fmt.Errorf("failed to create window: %v", err)
+	}
+
+	names := []string{SelectionClipboard}
+	if b.primary {
+		names = append(names, SelectionPrimary)
+	}
+	selections := make(map[xproto.Atom]string, len(names))
+	mask := uint32(xfixes.SelectionEventMaskSetSelectionOwner |
+		xfixes.SelectionEventMaskSelectionWindowDestroy |
+		xfixes.SelectionEventMaskSelectionClientClose)
+	for _, name := range names {
+		atom, err := internAtom(conn, name)
+		if err != nil {
+			return err
+		}
+		selections[atom] = name
+		if err := xfixes.SelectSelectionInputChecked(conn, win, atom, mask).Check(); err != nil {
+			return fmt.Errorf("failed to select %s events: %v", name, err)
+		}
+	}
+
+	// 关闭连接可以解除 WaitForEvent 的阻塞
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		ev, xerr := conn.WaitForEvent()
+		if ev == nil && xerr == nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return errors.New("X11 connection closed")
+		}
+		if e, ok := ev.(xfixes.SelectionNotifyEvent); ok {
+			b.mu.Lock()
+			b.selection = selections[e.Selection]
+			b.mu.Unlock()
+			notify()
+		}
+	}
+}
+
+// currentSelection 返回最近一次发生变化的选区
+func (b *X11Backend) currentSelection() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.selection
+}
+
+// ReadAll 读取最近变化的选区文本
+func (b *X11Backend) ReadAll() (string, error) {
+	if b.currentSelection() == SelectionPrimary {
+		return readPrimary()
+	}
+	return b.SystemBackend.ReadAll()
+}
+
+// ReadFormats 读取最近变化的选区，PRIMARY 选区只包含文本
+func (b *X11Backend) ReadFormats() (map[string][]byte, error) {
+	if b.currentSelection() == SelectionPrimary {
+		text, err := readPrimary()
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{MIMEText: []byte(text)}, nil
+	}
+	return b.SystemBackend.ReadFormats()
+}
+
+// WriteAll 写入文本到剪贴板
+func (b *X11Backend) WriteAll(text string) error {
+	return b.WriteFormats(map[string][]byte{MIMEText: []byte(text)})
+}
+
+// WriteFormats 以本程序的窗口持有 CLIPBOARD 选区，同时提供全部格式。
+// 内容在其他程序复制之前一直有效，本程序退出后随之失效。
+func (b *X11Backend) WriteFormats(formats map[string][]byte) error {
+	owner, err := newX11Owner(b.display, formats)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	previous := b.owner
+	b.owner = owner
+	b.mu.Unlock()
+
+	// 新的所有者生效后旧的已收到 SelectionClear，这里只需等待其退出
+	if previous != nil {
+		previous.close()
+	}
+	return nil
+}
+
+// readPrimary 通过 xclip 或 xsel 读取 PRIMARY 选区
+func readPrimary() (string, error) {
+	if _, err := exec.LookPath("xclip"); err == nil {
+		out, err := exec.Command("xclip", "-o", "-selection", "primary").Output()
+		return string(out), err
+	}
+	if _, err := exec.LookPath("xsel"); err == nil {
+		out, err := exec.Command("xsel", "--output", "--primary").Output()
+		return string(out), err
+	}
+	return "", errors.New("xclip or xsel is required to read the PRIMARY selection")
+}
+
+// internAtom 获取原子
+func internAtom(conn *xgb.Conn, name string) (xproto.Atom, error) {
+	reply, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to intern atom %s: %v", name, err)
+	}
+	return reply.Atom, nil
+}
@@ -0,0 +1,222 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// startXvfb 启动一个 Xvfb 实例并返回其显示名，未安装 Xvfb 时跳过测试
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("Xvfb"); err != nil {
+		t.Skip("Xvfb not installed")
+	}
+
+	for n := 90; n < 100; n++ {
+		socket := fmt.Sprintf("/tmp/.X11-unix/X%d", n)
+		if _, err := os.Stat(socket); err == nil {
+			continue
+		}
+		display := fmt.Sprintf(":%d", n)
+		cmd := exec.Command("Xvfb", display, "-nolisten", "tcp")
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("failed to start Xvfb: %v", err)
+		}
+		t.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, err := os.Stat(socket); err == nil {
+				return display
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatal("Xvfb did not start in time")
+	}
+	t.Skip("no free X display number")
+	return ""
+}
+
+// takeSelection 以新窗口的身份获取选区所有权，模拟其他程序复制
+func takeSelection(t *testing.T, conn *xgb.Conn, name string) {
+	t.Helper()
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.CreateWindow(conn, 0, win, root, 0, 0, 1, 1, 0, xproto.WindowClassInputOnly, 0, 0, nil)
+	atom, err := internAtom(conn, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xproto.SetSelectionOwnerChecked(conn, win, atom, xproto.TimeCurrentTime).Check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestX11BackendWatch(t *testing.T) {
+	display := startXvfb(t)
+
+	backend, err := NewX11Backend(display, true)
+	if err != nil {
+		t.Fatalf("NewX11Backend failed: %v", err)
+	}
+
+	notified := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- backend.Watch(ctx, func() { notified <- struct{}{} })
+	}()
+
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, selection := range []string{SelectionClipboard, SelectionPrimary} {
+		// Watch 在后台订阅事件，重复获取所有权直到收到通知
+		received := false
+		for attempt := 0; attempt < 20 && !received; attempt++ {
+			takeSelection(t, conn, selection)
+			select {
+			case <-notified:
+				received = true
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		if !received {
+			t.Fatalf("no notification for %s", selection)
+		}
+		if got := backend.currentSelection(); got != selection {
+			t.Errorf("Expected current selection %s, got %s", selection, got)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch did not return after cancel")
+	}
+}
+
+func TestNewX11BackendWithoutDisplay(t *testing.T) {
+	if _, err := NewX11Backend("/nonexistent:0", false); err == nil {
+		t.Error("Expected error for unreachable display")
+	}
+}
+
+// convertSelection 以新窗口请求 CLIPBOARD 选区的指定目标，返回属性类型和数据
+func convertSelection(t *testing.T, conn *xgb.Conn, target string) (xproto.Atom, []byte) {
+	t.Helper()
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.CreateWindow(conn, 0, win, root, 0, 0, 1, 1, 0, xproto.WindowClassInputOnly, 0, 0, nil)
+	selection, err := internAtom(conn, SelectionClipboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targetAtom, err := internAtom(conn, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	property, err := internAtom(conn, "CLIPBOARD_TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.ConvertSelection(conn, win, selection, targetAtom, property, xproto.TimeCurrentTime)
+
+	for {
+		ev, xerr := conn.WaitForEvent()
+		if ev == nil && xerr == nil {
+			t.Fatal("X11 connection closed")
+		}
+		e, ok := ev.(xproto.SelectionNotifyEvent)
+		if !ok || e.Requestor != win {
+			continue
+		}
+		if e.Property == xproto.AtomNone {
+			t.Fatalf("Target %s was refused", target)
+		}
+		reply, err := xproto.GetProperty(conn, false, win, property, xproto.AtomAny, 0, 1<<20).Reply()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reply.Type, reply.Value
+	}
+}
+
+func TestX11BackendWriteFormats(t *testing.T) {
+	display := startXvfb(t)
+
+	backend, err := NewX11Backend(display, false)
+	if err != nil {
+		t.Fatalf("NewX11Backend failed: %v", err)
+	}
+	err = backend.WriteFormats(map[string][]byte{MIMEText: []byte("hello"), MIMEHTML: []byte("<b>hello</b>")})
+	if err != nil {
+		t.Fatalf("WriteFormats failed: %v", err)
+	}
+
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	typ, data := convertSelection(t, conn, "TARGETS")
+	if typ != xproto.AtomAtom {
+		t.Fatalf("Expected ATOM list, got type %d", typ)
+	}
+	offered := make(map[xproto.Atom]bool)
+	for i := 0; i+4 <= len(data); i += 4 {
+		offered[xproto.Atom(xgb.Get32(data[i:]))] = true
+	}
+	for _, name := range []string{"TARGETS", "UTF8_STRING", MIMEText, MIMEHTML} {
+		atom, err := internAtom(conn, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !offered[atom] {
+			t.Errorf("Expected %s to be offered", name)
+		}
+	}
+
+	// 文本和 HTML 由同一个所有者同时提供
+	if _, data := convertSelection(t, conn, "UTF8_STRING"); string(data) != "hello" {
+		t.Errorf("Expected text 'hello', got %q", data)
+	}
+	if _, data := convertSelection(t, conn, MIMEHTML); string(data) != "<b>hello</b>" {
+		t.Errorf("Expected HTML, got %q", data)
+	}
+
+	// 其他程序复制后放弃选区
+	owner := backend.owner
+	takeSelection(t, conn, SelectionClipboard)
+	select {
+	case <-owner.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Owner did not exit after losing the selection")
+	}
+}
@@ -0,0 +1,204 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11Owner 以一个不可见窗口持有 CLIPBOARD 选区，按请求提供条目的全部格式。
+// 选区被其他程序取代或调用 close 后退出。
+type x11Owner struct {
+	conn      *xgb.Conn
+	window    xproto.Window
+	selection xproto.Atom
+	targets   xproto.Atom // TARGETS，列出可提供的格式
+	incr      xproto.Atom // INCR，分段传输较大的数据
+	chunk     int         // 单次 ChangeProperty 能写入的最大字节数
+
+	formats   map[xproto.Atom][]byte
+	transfers map[incrKey]*incrTransfer
+	done      chan struct{}
+}
+
+// incrKey 标识一次 INCR 传输：请求方窗口和用于传输的属性
+type incrKey struct {
+	window   xproto.Window
+	property xproto.Atom
+}
+
+// incrTransfer 尚未传输完的数据
+type incrTransfer struct {
+	target xproto.Atom
+	data   []byte
+}
+
+// newX11Owner 连接 X 显示并获取 CLIPBOARD 选区，纯文本同时以常见的别名提供
+func newX11Owner(display string, formats map[string][]byte) (*x11Owner, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to X display: %v", err)
+	}
+	o, err := setupX11Owner(conn, formats)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go o.serve()
+	return o, nil
+}
+
+// setupX11Owner 创建窗口、获取原子并成为选区所有者
+func setupX11Owner(conn *xgb.Conn, formats map[string][]byte) (*x11Owner, error) {
+	setup := xproto.Setup(conn)
+	o := &x11Owner{
+		conn:      conn,
+		chunk:     int(setup.MaximumRequestLength)*4 - 1024,
+		formats:   make(map[xproto.Atom][]byte, len(formats)+len(textMIMETypes)),
+		transfers: make(map[incrKey]*incrTransfer),
+		done:      make(chan struct{}),
+	}
+
+	var err error
+	for name, atom := range map[string]*xproto.Atom{
+		SelectionClipboard: &o.selection,
+		"TARGETS":          &o.targets,
+		"INCR":             &o.incr,
+	} {
+		if *atom, err = internAtom(conn, name); err != nil {
+			return nil, err
+		}
+	}
+	for mime, data := range formats {
+		names := []string{mime}
+		if mime == MIMEText {
+			names = textMIMETypes
+		}
+		for _, name := range names {
+			atom, err := internAtom(conn, name)
+			if err != nil {
+				return nil, err
+			}
+			o.formats[atom] = data
+		}
+	}
+
+	if o.window, err = xproto.NewWindowId(conn); err != nil {
+		return nil, err
+	}
+	root := setup.DefaultScreen(conn).Root
+	err = xproto.CreateWindowChecked(conn, 0, o.window, root, 0, 0, 1, 1, 0,
+		xproto.WindowClassInputOnly, 0, 0, nil).Check()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create window: %v", err)
+	}
+
+	err = xproto.SetSelectionOwnerChecked(conn, o.window, o.selection, xproto.TimeCurrentTime).Check()
+	if err != nil {
+		return nil, fmt.Errorf("failed to set selection owner: %v", err)
+	}
+	reply, err := xproto.GetSelectionOwner(conn, o.selection).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query selection owner: %v", err)
+	}
+	if reply.Owner != o.window {
+		return nil, errors.New("failed to become the clipboard owner")
+	}
+	return o, nil
+}
+
+// serve 处理选区请求，直到失去选区或连接被关闭
+func (o *x11Owner) serve() {
+	defer close(o.done)
+	for {
+		ev, xerr := o.conn.WaitForEvent()
+		if ev == nil && xerr == nil {
+			return
+		}
+		switch e := ev.(type) {
+		case xproto.SelectionRequestEvent:
+			o.handleRequest(e)
+		case xproto.PropertyNotifyEvent:
+			// 请求方删除属性表示已读取上一段，继续发送下一段
+			if e.State == xproto.PropertyDelete {
+				o.continueTransfer(incrKey{e.Window, e.Atom})
+			}
+		case xproto.SelectionClearEvent:
+			o.conn.Close()
+		}
+	}
+}
+
+// handleRequest 把请求的格式写入请求方窗口的属性并通知请求方
+func (o *x11Owner) handleRequest(e xproto.SelectionRequestEvent) {
+	property := e.Property
+	if property == xproto.AtomNone {
+		// 旧的客户端不指定属性，按 ICCCM 约定使用目标名
+		property = e.Target
+	}
+
+	data, ok := o.formats[e.Target]
+	switch {
+	case e.Target == o.targets:
+		atoms := make([]byte, 4*(len(o.formats)+1))
+		xgb.Put32(atoms, uint32(o.targets))
+		i := 4
+		for atom := range o.formats {
+			xgb.Put32(atoms[i:], uint32(atom))
+			i += 4
+		}
+		xproto.ChangeProperty(o.conn, xproto.PropModeReplace, e.Requestor, property,
+			xproto.AtomAtom, 32, uint32(len(atoms)/4), atoms)
+	case ok && len(data) > o.chunk:
+		// 数据超过单个请求的上限，改用 INCR 协议分段传输
+		xproto.ChangeWindowAttributes(o.conn, e.Requestor, xproto.CwEventMask,
+			[]uint32{xproto.EventMaskPropertyChange})
+		size := make([]byte, 4)
+		xgb.Put32(size, uint32(len(data)))
+		xproto.ChangeProperty(o.conn, xproto.PropModeReplace, e.Requestor, property,
+			o.incr, 32, 1, size)
+		o.transfers[incrKey{e.Requestor, property}] = &incrTransfer{target: e.Target, data: data}
+	case ok:
+		xproto.ChangeProperty(o.conn, xproto.PropModeReplace, e.Requestor, property,
+			e.Target, 8, uint32(len(data)), data)
+	default:
+		property = xproto.AtomNone
+	}
+
+	notify := xproto.SelectionNotifyEvent{
+		Time:      e.Time,
+		Requestor: e.Requestor,
+		Selection: e.Selection,
+		Target:    e.Target,
+		Property:  property,
+	}
+	xproto.SendEvent(o.conn, false, e.Requestor, 0, string(notify.Bytes()))
+}
+
+// continueTransfer 发送 INCR 传输的下一段，长度为零的一段表示传输结束
+func (o *x11Owner) continueTransfer(key incrKey) {
+	t, ok := o.transfers[key]
+	if !ok {
+		return
+	}
+	n := len(t.data)
+	if n > o.chunk {
+		n = o.chunk
+	}
+	xproto.ChangeProperty(o.conn, xproto.PropModeReplace, key.window, key.property,
+		t.target, 8, uint32(n), t.data[:n])
+	t.data = t.data[n:]
+	if n == 0 {
+		delete(o.transfers, key)
+	}
+}
+
+// close 放弃选区并等待 serve 退出
+func (o *x11Owner) close() {
+	o.conn.Close()
+	<-o.done
+}
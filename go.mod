@@ -4,10 +4,9 @@ go 1.21
 
 require (
 	github.com/atotto/clipboard v0.1.4
+	github.com/jezek/xgb v1.1.1
 	github.com/webview/webview_go v0.0.0-20240831120633-6173450d4dd6
+	golang.org/x/crypto v0.31.0
 )
 
-require (
-	github.com/robotn/gohook v0.42.2 // indirect
-	github.com/vcaesar/keycode v0.10.1 // indirect
-)
+require golang.org/x/sys v0.28.0 // indirect
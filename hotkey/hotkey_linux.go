@@ -0,0 +1,270 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// X11 keysym
+const (
+	XK_Num_Lock = 0xff7f
+)
+
+// grab 一个绑定在 X 服务器上抓取的按键
+type grab struct {
+	keycode   xproto.Keycode
+	modifiers uint16
+}
+
+// HotkeyManager 全局热键管理器 (X11，通过在根窗口上 XGrabKey 实现)
+type HotkeyManager struct {
+	mu sync.Mutex
+	registry
+	grabs   map[int]grab
+	display string
+	conn    *xgb.Conn
+	root    xproto.Window
+	ignored []uint16 // 需要额外抓取的 CapsLock/NumLock 组合
+}
+
+// NewHotkeyManager 创建新的热键管理器，使用 DISPLAY 环境变量指定的显示
+func NewHotkeyManager() *HotkeyManager {
+	return &HotkeyManager{
+		grabs:   make(map[int]grab),
+		display: os.Getenv("DISPLAY"),
+	}
+}
+
+// Register 注册一个全局热键绑定，返回绑定 ID
+func (hm *HotkeyManager) Register(spec Spec, callback func()) (int, error) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	if err := spec.Validate(); err != nil {
+		return 0, err
+	}
+	if err := hm.conflict(spec); err != nil {
+		return 0, err
+	}
+	mods, keysym, err := x11Key(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := hm.connect(); err != nil {
+		return 0, err
+	}
+	keycode, err := keysymToKeycode(hm.conn, keysym)
+	if err == nil {
+		err = hm.grabKey(grab{keycode, mods})
+	}
+	if err != nil {
+		hm.disconnectIfIdle()
+		return 0, err
+	}
+
+	id := hm.newID()
+	hm.add(id, spec, callback)
+	hm.grabs[id] = grab{keycode, mods}
+	log.Printf("已注册全局热键: %s (ID: %d, keycode: %d)", spec, id, keycode)
+
+	return id, nil
+}
+
+// Unregister 注销指定的热键绑定
+func (hm *HotkeyManager) Unregister(id int) error {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+
+	b, ok := hm.remove(id)
+	if !ok {
+		return nil
+	}
+
+	hm.ungrabKey(hm.grabs[id])
+	delete(hm.grabs, id)
+	hm.disconnectIfIdle()
+	log.Printf("已注销全局热键: %s (ID: %d)", b.Spec, id)
+
+	return nil
+}
+
+// UnregisterAll 注销全部热键绑定
+func (hm *HotkeyManager) UnregisterAll() error {
+	hm.mu.Lock()
+	ids := hm.ids()
+	hm.mu.Unlock()
+
+	for _, id := range ids {
+		if err := hm.Unregister(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsRegistered 检查指定绑定是否已注册
+func (hm *HotkeyManager) IsRegistered(id int) bool {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	_, ok := hm.get(id)
+	return ok
+}
+
+// Bindings 返回当前全部热键绑定
+func (hm *HotkeyManager) Bindings() []Binding {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	return hm.list()
+}
+
+// connect 首次注册时连接 X 服务器并启动事件循环，调用方需持有锁
+func (hm *HotkeyManager) connect() error {
+	if hm.conn != nil {
+		return nil
+	}
+	if hm.display == "" {
+		return fmt.Errorf("global hotkey requires an X11 display")
+	}
+
+	conn, err := xgb.NewConnDisplay(hm.display)
+	if err != nil {
+		return fmt.Errorf("failed to connect to X display: %v", err)
+	}
+	numLock := numLockMask(conn)
+
+	hm.conn = conn
+	hm.root = xproto.Setup(conn).DefaultScreen(conn).Root
+	hm.ignored = []uint16{0, xproto.ModMaskLock, numLock, xproto.ModMaskLock | numLock}
+
+	// 启动事件循环
+	go hm.eventLoop(conn)
+
+	return nil
+}
+
+// disconnectIfIdle 没有任何绑定时关闭连接，关闭连接会结束事件循环，调用方需持有锁
+func (hm *HotkeyManager) disconnectIfIdle() {
+	if hm.conn == nil || len(hm.grabs) > 0 {
+		return
+	}
+	hm.conn.Close()
+	hm.conn = nil
+}
+
+// grabKey 抓取按键，调用方需持有锁
+func (hm *HotkeyManager) grabKey(g grab) error {
+	// 分别抓取带 CapsLock/NumLock 的组合，否则这些锁定键打开时热键不会触发
+	for i, extra := range hm.ignored {
+		err := xproto.GrabKeyChecked(hm.conn, true, hm.root, g.modifiers|extra, g.keycode,
+			xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+		if err != nil {
+			for _, done := range hm.ignored[:i] {
+				xproto.UngrabKey(hm.conn, g.keycode, hm.root, g.modifiers|done)
+			}
+			return fmt.Errorf("failed to register hotkey (already grabbed by another program?): %v", err)
+		}
+	}
+	return nil
+}
+
+// ungrabKey 释放按键的全部抓取组合，调用方需持有锁
+func (hm *HotkeyManager) ungrabKey(g grab) {
+	for _, extra := range hm.ignored {
+		xproto.UngrabKey(hm.conn, g.keycode, hm.root, g.modifiers|extra)
+	}
+	// 通过一次往返确保请求已被服务器处理
+	xproto.GetInputFocus(hm.conn).Reply()
+}
+
+// eventLoop 事件循环
+func (hm *HotkeyManager) eventLoop(conn *xgb.Conn) {
+	for {
+		ev, err := conn.WaitForEvent()
+		if ev == nil && err == nil {
+			// 连接已关闭
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		press, ok := ev.(xproto.KeyPressEvent)
+		if !ok {
+			continue
+		}
+
+		pressed := grab{press.Detail, stripLocks(press.State)}
+		var callback func()
+		hm.mu.Lock()
+		for id, g := range hm.grabs {
+			if g == pressed {
+				if b, ok := hm.get(id); ok {
+					callback = b.callback
+				}
+				break
+			}
+		}
+		hm.mu.Unlock()
+
+		if callback != nil {
+			log.Printf("检测到全局热键按下")
+			go callback()
+		}
+	}
+}
+
+// stripLocks 去掉 CapsLock/NumLock 等锁定修饰键
+func stripLocks(state uint16) uint16 {
+	const relevant = xproto.ModMaskShift | xproto.ModMaskControl | xproto.ModMask1 | xproto.ModMask4
+	return state & relevant
+}
+
+// keysymToKeycode 查找产生指定 keysym 的键码
+func keysymToKeycode(conn *xgb.Conn, keysym xproto.Keysym) (xproto.Keycode, error) {
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	reply, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get keyboard mapping: %v", err)
+	}
+
+	per := int(reply.KeysymsPerKeycode)
+	for i := 0; i < int(count); i++ {
+		for j := 0; j < per; j++ {
+			if reply.Keysyms[i*per+j] == keysym {
+				return setup.MinKeycode + xproto.Keycode(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no keycode for keysym 0x%x", keysym)
+}
+
+// numLockMask 查找 NumLock 所在的修饰位，找不到时使用通常的 Mod2
+func numLockMask(conn *xgb.Conn) uint16 {
+	keycode, err := keysymToKeycode(conn, XK_Num_Lock)
+	if err != nil {
+		return xproto.ModMask2
+	}
+	reply, err := xproto.GetModifierMapping(conn).Reply()
+	if err != nil {
+		return xproto.ModMask2
+	}
+
+	per := int(reply.KeycodesPerModifier)
+	for mod := 0; mod < 8; mod++ {
+		for i := 0; i < per; i++ {
+			if reply.Keycodes[mod*per+i] == keycode {
+				return 1 << uint(mod)
+			}
+		}
+	}
+	return xproto.ModMask2
+}
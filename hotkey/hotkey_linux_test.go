@@ -0,0 +1,249 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+)
+
+// X11 keysym
+const (
+	xkControlL = 0xffe3
+	xkShiftL   = 0xffe1
+	xkCapsLock = 0xffe5
+	xkV        = 0x0076
+	xkP        = 0x0070
+)
+
+// startXvfb 启动一个 Xvfb 实例并返回其显示名，未安装 Xvfb 时跳过测试
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("Xvfb"); err != nil {
+		t.Skip("Xvfb not installed")
+	}
+
+	for n := 80; n < 90; n++ {
+		socket := fmt.Sprintf("/tmp/.X11-unix/X%d", n)
+		if _, err := os.Stat(socket); err == nil {
+			continue
+		}
+		display := fmt.Sprintf(":%d", n)
+		cmd := exec.Command("Xvfb", display, "-nolisten", "tcp")
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("failed to start Xvfb: %v", err)
+		}
+		t.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, err := os.Stat(socket); err == nil {
+				return display
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatal("Xvfb did not start in time")
+	}
+	t.Skip("no free X display number")
+	return ""
+}
+
+// fakeKeys 通过 XTest 依次按下并逆序释放按键
+func fakeKeys(t *testing.T, conn *xgb.Conn, keysyms ...xproto.Keysym) {
+	t.Helper()
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	codes := make([]xproto.Keycode, len(keysyms))
+	for i, sym := range keysyms {
+		code, err := keysymToKeycode(conn, sym)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes[i] = code
+	}
+	for _, code := range codes {
+		xtest.FakeInput(conn, xproto.KeyPress, byte(code), 0, root, 0, 0, 0)
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		xtest.FakeInput(conn, xproto.KeyRelease, byte(codes[i]), 0, root, 0, 0, 0)
+	}
+	xproto.GetInputFocus(conn).Reply()
+}
+
+func TestHotkeyManagerX11(t *testing.T) {
+	display := startXvfb(t)
+
+	hm := NewHotkeyManager()
+	hm.display = display
+
+	triggered := make(chan struct{}, 10)
+	id, err := hm.Register(DefaultSpec, func() { triggered <- struct{}{} })
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if !hm.IsRegistered(id) {
+		t.Fatal("Expected hotkey to be registered")
+	}
+	if _, err := hm.Register(DefaultSpec, func() {}); err == nil {
+		t.Error("Expected error when registering the same spec twice")
+	}
+
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := xtest.Init(conn); err != nil {
+		t.Skipf("XTest unavailable: %v", err)
+	}
+
+	expect := func(want bool, msg string) {
+		t.Helper()
+		select {
+		case <-triggered:
+			if !want {
+				t.Error(msg)
+			}
+		case <-time.After(500 * time.Millisecond):
+			if want {
+				t.Error(msg)
+			}
+		}
+	}
+
+	fakeKeys(t, conn, xkControlL, xkShiftL, xkV)
+	expect(true, "Ctrl+Shift+V should trigger the hotkey")
+
+	fakeKeys(t, conn, xkControlL, xkV)
+	expect(false, "Ctrl+V should not trigger the hotkey")
+
+	// 打开 CapsLock 后仍应触发
+	fakeKeys(t, conn, xkCapsLock)
+	fakeKeys(t, conn, xkControlL, xkShiftL, xkV)
+	expect(true, "Ctrl+Shift+V with CapsLock should trigger the hotkey")
+	fakeKeys(t, conn, xkCapsLock)
+
+	if err := hm.Unregister(id); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	if hm.IsRegistered(id) {
+		t.Error("Expected hotkey to be unregistered")
+	}
+	fakeKeys(t, conn, xkControlL, xkShiftL, xkV)
+	expect(false, "Hotkey should not trigger after unregister")
+}
+
+func TestMultipleBindingsX11(t *testing.T) {
+	display := startXvfb(t)
+
+	hm := NewHotkeyManager()
+	hm.display = display
+	defer hm.UnregisterAll()
+
+	quick := make(chan struct{}, 10)
+	pause := make(chan struct{}, 10)
+	quickID, err := hm.Register(DefaultSpec, func() { quick <- struct{}{} })
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	pauseID, err := hm.Register(Spec{Modifiers: ModCtrl | ModShift, Key: "P"}, func() { pause <- struct{}{} })
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if quickID == pauseID {
+		t.Fatal("Expected unique binding IDs")
+	}
+	if got := hm.Bindings(); len(got) != 2 {
+		t.Fatalf("Bindings() = %+v", got)
+	}
+
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := xtest.Init(conn); err != nil {
+		t.Skipf("XTest unavailable: %v", err)
+	}
+
+	expect := func(ch chan struct{}, want bool, msg string) {
+		t.Helper()
+		select {
+		case <-ch:
+			if !want {
+				t.Error(msg)
+			}
+		case <-time.After(500 * time.Millisecond):
+			if want {
+				t.Error(msg)
+			}
+		}
+	}
+
+	fakeKeys(t, conn, xkControlL, xkShiftL, xkP)
+	expect(pause, true, "Ctrl+Shift+P should trigger its own binding")
+	expect(quick, false, "Ctrl+Shift+P should not trigger Ctrl+Shift+V")
+
+	// 注销一个绑定后另一个仍然有效
+	if err := hm.Unregister(quickID); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	fakeKeys(t, conn, xkControlL, xkShiftL, xkV)
+	expect(quick, false, "Unregistered binding should not trigger")
+	fakeKeys(t, conn, xkControlL, xkShiftL, xkP)
+	expect(pause, true, "Remaining binding should still trigger")
+
+	if err := hm.UnregisterAll(); err != nil {
+		t.Fatalf("UnregisterAll failed: %v", err)
+	}
+	if hm.IsRegistered(pauseID) || len(hm.Bindings()) != 0 {
+		t.Error("Expected all bindings to be removed")
+	}
+}
+
+func TestRegisterHotkeyWithoutDisplay(t *testing.T) {
+	hm := NewHotkeyManager()
+	hm.display = ""
+	if _, err := hm.Register(DefaultSpec, func() {}); err == nil {
+		t.Error("Expected error without display")
+	}
+	if len(hm.Bindings()) != 0 {
+		t.Error("Failed registration should not leave a binding")
+	}
+}
+
+func TestX11KeyMapping(t *testing.T) {
+	tests := []struct {
+		spec   string
+		mods   uint16
+		keysym xproto.Keysym
+	}{
+		{"Ctrl+Shift+V", xproto.ModMaskControl | xproto.ModMaskShift, 0x0076},
+		{"Alt+Super+1", xproto.ModMask1 | xproto.ModMask4, 0x0031},
+		{"F12", 0, 0xffc9},
+		{"Ctrl+Alt+PageDown", xproto.ModMaskControl | xproto.ModMask1, 0xff56},
+		{"Ctrl+`", xproto.ModMaskControl, 0x0060},
+	}
+	for _, tt := range tests {
+		spec, err := ParseSpec(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseSpec(%q) failed: %v", tt.spec, err)
+		}
+		mods, keysym, err := x11Key(spec)
+		if err != nil {
+			t.Fatalf("x11Key(%q) failed: %v", tt.spec, err)
+		}
+		if mods != tt.mods || keysym != tt.keysym {
+			t.Errorf("x11Key(%q) = %#x, %#x; want %#x, %#x", tt.spec, mods, keysym, tt.mods, tt.keysym)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-//go:build !windows
+//go:build !windows && !linux
 
 package hotkey
 
@@ -12,17 +12,30 @@ func NewHotkeyManager() *HotkeyManager {
 	return &HotkeyManager{}
 }
 
-// RegisterHotkey 注册全局热键 (非Windows平台暂不支持)
-func (hm *HotkeyManager) RegisterHotkey(callback func()) error {
-	return fmt.Errorf("global hotkey not supported on this platform")
+// Register 注册全局热键 (当前平台暂不支持)
+func (hm *HotkeyManager) Register(spec Spec, callback func()) (int, error) {
+	if err := spec.Validate(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("global hotkey not supported on this platform")
 }
 
-// UnregisterHotkey 注销热键 (非Windows平台暂不支持)
-func (hm *HotkeyManager) UnregisterHotkey() error {
+// Unregister 注销热键 (当前平台暂不支持)
+func (hm *HotkeyManager) Unregister(id int) error {
+	return nil
+}
+
+// UnregisterAll 注销全部热键 (当前平台暂不支持)
+func (hm *HotkeyManager) UnregisterAll() error {
 	return nil
 }
 
 // IsRegistered 检查热键是否已注册
-func (hm *HotkeyManager) IsRegistered() bool {
+func (hm *HotkeyManager) IsRegistered(id int) bool {
 	return false
 }
+
+// Bindings 返回当前全部热键绑定
+func (hm *HotkeyManager) Bindings() []Binding {
+	return nil
+}
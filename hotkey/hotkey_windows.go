@@ -5,6 +5,8 @@ package hotkey
 import (
 	"fmt"
 	"log"
+	"runtime"
+	"sync"
 	"syscall"
 	"unsafe"
 )
@@ -15,27 +17,35 @@ var (
 	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
 	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
 	procGetMessage         = user32.NewProc("GetMessageW")
+	procPeekMessage        = user32.NewProc("PeekMessageW")
+	procPostThreadMessage  = user32.NewProc("PostThreadMessageW")
 	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
 )
 
 // 修饰键常量
 const (
-	MOD_ALT     = 0x0001
-	MOD_CONTROL = 0x0002
-	MOD_SHIFT   = 0x0004
-	MOD_WIN     = 0x0008
+	MOD_ALT      = 0x0001
+	MOD_CONTROL  = 0x0002
+	MOD_SHIFT    = 0x0004
+	MOD_WIN      = 0x0008
+	MOD_NOREPEAT = 0x4000
 )
 
-// 虚拟键码
+// 消息类型
 const (
-	VK_V = 0x56
+	WM_USER   = 0x0400
+	WM_HOTKEY = 0x0312
+	WM_APP    = 0x8000
 )
 
-// 消息类型
+// PeekMessage 标志
 const (
-	WM_HOTKEY = 0x0312
+	PM_NOREMOVE = 0x0000
 )
 
+// wmRunCall 通知消息循环线程执行排队的调用
+const wmRunCall = WM_APP + 1
+
 // MSG 结构体
 type MSG struct {
 	HWND    uintptr
@@ -47,106 +57,197 @@ type MSG struct {
 }
 
 // HotkeyManager 全局热键管理器
+//
+// RegisterHotKey 注册的热键消息只会投递到调用它的线程，
+// 因此注册、注销和消息循环都在同一个锁定的系统线程上执行。
 type HotkeyManager struct {
-	registered bool
-	callback   func()
-	stopChan   chan bool
+	opMu sync.Mutex // 串行化注册和注销
+	mu   sync.Mutex // 保护绑定表，消息循环查找回调时也会使用
+	registry
+	threadID uintptr
+	calls    chan func()
 }
 
 // NewHotkeyManager 创建新的热键管理器
 func NewHotkeyManager() *HotkeyManager {
-	return &HotkeyManager{
-		stopChan: make(chan bool),
-	}
+	return &HotkeyManager{}
 }
 
-// RegisterHotkey 注册全局热键 (Ctrl+Shift+V)
-func (hm *HotkeyManager) RegisterHotkey(callback func()) error {
-	if hm.registered {
-		return fmt.Errorf("hotkey already registered")
+// Register 注册一个全局热键绑定，返回绑定 ID
+func (hm *HotkeyManager) Register(spec Spec, callback func()) (int, error) {
+	hm.opMu.Lock()
+	defer hm.opMu.Unlock()
+
+	if err := spec.Validate(); err != nil {
+		return 0, err
+	}
+	mods, vk, err := virtualKey(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	hm.mu.Lock()
+	err = hm.conflict(spec)
+	id := hm.newID()
+	hm.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	hm.start()
+	err = hm.onLoopThread(func() error {
+		ret, _, callErr := procRegisterHotKey.Call(
+			0,                 // NULL窗口句柄，消息投递到当前线程
+			uintptr(id),       // 热键ID
+			mods|MOD_NOREPEAT, // 修饰键，按住不放时不重复触发
+			vk,                // 虚拟键码
+		)
+		if ret == 0 {
+			return fmt.Errorf("failed to register hotkey %s: %v", spec, callErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	hm.callback = callback
+	hm.mu.Lock()
+	hm.add(id, spec, callback)
+	hm.mu.Unlock()
+	log.Printf("已注册全局热键: %s (ID: %d)", spec, id)
 
-	// 获取当前线程ID
-	threadId, _, _ := procGetCurrentThreadId.Call()
+	return id, nil
+}
 
-	// 注册热键 Ctrl+Shift+V (ID=1)
-	ret, _, err := procRegisterHotKey.Call(
-		0,                              // NULL窗口句柄
-		1,                              // 热键ID
-		uintptr(MOD_CONTROL|MOD_SHIFT), // 修饰键
-		uintptr(VK_V),                  // 虚拟键码
-	)
+// Unregister 注销指定的热键绑定
+func (hm *HotkeyManager) Unregister(id int) error {
+	hm.opMu.Lock()
+	defer hm.opMu.Unlock()
 
-	if ret == 0 {
-		return fmt.Errorf("failed to register hotkey: %v", err)
+	hm.mu.Lock()
+	b, ok := hm.get(id)
+	hm.mu.Unlock()
+	if !ok {
+		return nil
 	}
 
-	hm.registered = true
-	log.Printf("已注册全局热键: Ctrl+Shift+V (线程ID: %d)", threadId)
+	err := hm.onLoopThread(func() error {
+		ret, _, callErr := procUnregisterHotKey.Call(0, uintptr(id))
+		if ret == 0 {
+			return fmt.Errorf("failed to unregister hotkey %s: %v", b.Spec, callErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-	// 启动消息循环
-	go hm.messageLoop()
+	hm.mu.Lock()
+	hm.remove(id)
+	hm.mu.Unlock()
+	log.Printf("已注销全局热键: %s (ID: %d)", b.Spec, id)
 
 	return nil
 }
 
-// UnregisterHotkey 注销热键
-func (hm *HotkeyManager) UnregisterHotkey() error {
-	if !hm.registered {
-		return nil
+// UnregisterAll 注销全部热键绑定
+func (hm *HotkeyManager) UnregisterAll() error {
+	hm.mu.Lock()
+	ids := hm.ids()
+	hm.mu.Unlock()
+
+	for _, id := range ids {
+		if err := hm.Unregister(id); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	ret, _, err := procUnregisterHotKey.Call(0, 1)
-	if ret == 0 {
-		return fmt.Errorf("failed to unregister hotkey: %v", err)
+// IsRegistered 检查指定绑定是否已注册
+func (hm *HotkeyManager) IsRegistered(id int) bool {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	_, ok := hm.get(id)
+	return ok
+}
+
+// Bindings 返回当前全部热键绑定
+func (hm *HotkeyManager) Bindings() []Binding {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	return hm.list()
+}
+
+// start 首次使用时启动消息循环线程，调用方需持有 opMu
+func (hm *HotkeyManager) start() {
+	if hm.calls != nil {
+		return
 	}
 
-	hm.registered = false
-	hm.stopChan <- true
-	log.Printf("已注销全局热键")
+	hm.calls = make(chan func(), 1)
+	started := make(chan uintptr)
+	go hm.messageLoop(started)
+	hm.threadID = <-started
+}
 
-	return nil
+// onLoopThread 在消息循环线程上执行 fn 并等待结果，调用方需持有 opMu
+func (hm *HotkeyManager) onLoopThread(fn func() error) error {
+	done := make(chan error, 1)
+	hm.calls <- func() { done <- fn() }
+
+	ret, _, err := procPostThreadMessage.Call(hm.threadID, wmRunCall, 0, 0)
+	if ret == 0 {
+		<-hm.calls
+		return fmt.Errorf("failed to post message to hotkey thread: %v", err)
+	}
+	return <-done
 }
 
 // messageLoop 消息循环
-func (hm *HotkeyManager) messageLoop() {
+func (hm *HotkeyManager) messageLoop(started chan<- uintptr) {
+	runtime.LockOSThread()
+
 	var msg MSG
 
+	// 调用一次 PeekMessage 让系统为当前线程创建消息队列，之后才能接收线程消息
+	procPeekMessage.Call(uintptr(unsafe.Pointer(&msg)), 0, WM_USER, WM_USER, PM_NOREMOVE)
+	threadID, _, _ := procGetCurrentThreadId.Call()
+	started <- threadID
+
 	for {
-		select {
-		case <-hm.stopChan:
+		// 获取消息
+		ret, _, _ := procGetMessage.Call(
+			uintptr(unsafe.Pointer(&msg)),
+			0,
+			0,
+			0,
+		)
+
+		if ret == 0 { // WM_QUIT
 			return
-		default:
-			// 获取消息
-			ret, _, _ := procGetMessage.Call(
-				uintptr(unsafe.Pointer(&msg)),
-				0,
-				0,
-				0,
-			)
-
-			if ret == 0 { // WM_QUIT
-				return
-			}
+		}
 
-			if ret == ^uintptr(0) { // 错误
-				continue
-			}
+		if ret == ^uintptr(0) { // 错误
+			continue
+		}
 
-			// 检查是否是热键消息
-			if msg.Message == WM_HOTKEY && msg.WParam == 1 {
-				log.Printf("检测到全局热键按下")
-				if hm.callback != nil {
-					go hm.callback()
-				}
+		switch msg.Message {
+		case wmRunCall:
+			select {
+			case fn := <-hm.calls:
+				fn()
+			default:
+			}
+		case WM_HOTKEY:
+			hm.mu.Lock()
+			b, ok := hm.get(int(msg.WParam))
+			hm.mu.Unlock()
+
+			if ok && b.callback != nil {
+				log.Printf("检测到全局热键按下: %s", b.Spec)
+				go b.callback()
 			}
 		}
 	}
 }
-
-// IsRegistered 检查热键是否已注册
-func (hm *HotkeyManager) IsRegistered() bool {
-	return hm.registered
-}
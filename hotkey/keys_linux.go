@@ -0,0 +1,75 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11NamedKeys 命名键与标点对应的 keysym
+var x11NamedKeys = map[string]xproto.Keysym{
+	"Space":     0x0020,
+	"Enter":     0xff0d, // Return
+	"Tab":       0xff09,
+	"Escape":    0xff1b,
+	"Backspace": 0xff08,
+	"Delete":    0xffff,
+	"Insert":    0xff63,
+	"Home":      0xff50,
+	"End":       0xff57,
+	"PageUp":    0xff55, // Prior
+	"PageDown":  0xff56, // Next
+	"Left":      0xff51,
+	"Up":        0xff52,
+	"Right":     0xff53,
+	"Down":      0xff54,
+	"`":         0x0060, // grave
+	"-":         0x002d,
+	"=":         0x003d,
+	"Plus":      0x002b, // 美式键盘上是 "=" 键的第二层，按产生它的键注册
+	"[":         0x005b,
+	"]":         0x005d,
+	"\\":        0x005c,
+	";":         0x003b,
+	"'":         0x0027,
+	",":         0x002c,
+	".":         0x002e,
+	"/":         0x002f,
+}
+
+// x11Key 将热键组合转换为 X11 修饰键掩码和 keysym
+func x11Key(spec Spec) (mods uint16, keysym xproto.Keysym, err error) {
+	if spec.Modifiers&ModCtrl != 0 {
+		mods |= xproto.ModMaskControl
+	}
+	if spec.Modifiers&ModShift != 0 {
+		mods |= xproto.ModMaskShift
+	}
+	if spec.Modifiers&ModAlt != 0 {
+		mods |= xproto.ModMask1
+	}
+	if spec.Modifiers&ModSuper != 0 {
+		mods |= xproto.ModMask4
+	}
+
+	key := spec.Key
+	if sym, ok := x11NamedKeys[key]; ok {
+		return mods, sym, nil
+	}
+	if len(key) == 1 {
+		c := key[0]
+		switch {
+		case c >= 'A' && c <= 'Z':
+			// 键盘映射中字母键的第一个 keysym 是小写
+			return mods, xproto.Keysym(c - 'A' + 'a'), nil
+		case c >= '0' && c <= '9':
+			return mods, xproto.Keysym(c), nil
+		}
+	}
+	if n, ok := functionKeyNumber(key); ok {
+		return mods, xproto.Keysym(0xffbe + n - 1), nil // XK_F1
+	}
+	return 0, 0, fmt.Errorf("unsupported key %q", key)
+}
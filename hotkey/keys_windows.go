@@ -0,0 +1,65 @@
+//go:build windows
+
+package hotkey
+
+import "fmt"
+
+// winNamedKeys 命名键与标点对应的虚拟键码（标点按美式键盘布局）
+var winNamedKeys = map[string]uintptr{
+	"Space":     0x20,
+	"Enter":     0x0D,
+	"Tab":       0x09,
+	"Escape":    0x1B,
+	"Backspace": 0x08,
+	"Delete":    0x2E,
+	"Insert":    0x2D,
+	"Home":      0x24,
+	"End":       0x23,
+	"PageUp":    0x21,
+	"PageDown":  0x22,
+	"Left":      0x25,
+	"Up":        0x26,
+	"Right":     0x27,
+	"Down":      0x28,
+	"`":         0xC0,
+	"-":         0xBD,
+	"=":         0xBB,
+	"Plus":      0xBB, // VK_OEM_PLUS，任何布局下都是 "+" 所在的键
+	"[":         0xDB,
+	"]":         0xDD,
+	"\\":        0xDC,
+	";":         0xBA,
+	"'":         0xDE,
+	",":         0xBC,
+	".":         0xBE,
+	"/":         0xBF,
+}
+
+// virtualKey 将热键组合转换为 RegisterHotKey 需要的修饰键和虚拟键码
+func virtualKey(spec Spec) (mods uintptr, vk uintptr, err error) {
+	if spec.Modifiers&ModCtrl != 0 {
+		mods |= MOD_CONTROL
+	}
+	if spec.Modifiers&ModShift != 0 {
+		mods |= MOD_SHIFT
+	}
+	if spec.Modifiers&ModAlt != 0 {
+		mods |= MOD_ALT
+	}
+	if spec.Modifiers&ModSuper != 0 {
+		mods |= MOD_WIN
+	}
+
+	key := spec.Key
+	if code, ok := winNamedKeys[key]; ok {
+		return mods, code, nil
+	}
+	if len(key) == 1 && (key[0] >= 'A' && key[0] <= 'Z' || key[0] >= '0' && key[0] <= '9') {
+		// 字母和数字的虚拟键码与 ASCII 相同
+		return mods, uintptr(key[0]), nil
+	}
+	if n, ok := functionKeyNumber(key); ok {
+		return mods, uintptr(0x70 + n - 1), nil // VK_F1 = 0x70
+	}
+	return 0, 0, fmt.Errorf("unsupported key %q", key)
+}
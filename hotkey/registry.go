@@ -0,0 +1,82 @@
+package hotkey
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Binding 一个已注册的热键绑定
+type Binding struct {
+	ID   int
+	Spec Spec
+}
+
+// binding 绑定及其回调
+type binding struct {
+	Binding
+	callback func()
+}
+
+// registry 热键绑定表，由各平台的 HotkeyManager 持有锁后使用
+type registry struct {
+	bindings map[int]*binding
+	lastID   int
+}
+
+// newID 分配一个新的绑定 ID，ID 从 1 开始且不会重复使用
+func (r *registry) newID() int {
+	r.lastID++
+	return r.lastID
+}
+
+// conflict 检查组合是否已被其他绑定占用
+func (r *registry) conflict(spec Spec) error {
+	for _, b := range r.bindings {
+		if b.Spec == spec {
+			return fmt.Errorf("hotkey %s already registered (id %d)", spec, b.ID)
+		}
+	}
+	return nil
+}
+
+// add 添加绑定
+func (r *registry) add(id int, spec Spec, callback func()) {
+	if r.bindings == nil {
+		r.bindings = make(map[int]*binding)
+	}
+	r.bindings[id] = &binding{Binding: Binding{ID: id, Spec: spec}, callback: callback}
+}
+
+// get 按 ID 查找绑定
+func (r *registry) get(id int) (*binding, bool) {
+	b, ok := r.bindings[id]
+	return b, ok
+}
+
+// remove 删除绑定并返回被删除的项
+func (r *registry) remove(id int) (*binding, bool) {
+	b, ok := r.bindings[id]
+	if ok {
+		delete(r.bindings, id)
+	}
+	return b, ok
+}
+
+// ids 按注册顺序返回全部绑定 ID
+func (r *registry) ids() []int {
+	ids := make([]int, 0, len(r.bindings))
+	for id := range r.bindings {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// list 按注册顺序返回全部绑定
+func (r *registry) list() []Binding {
+	list := make([]Binding, 0, len(r.bindings))
+	for _, id := range r.ids() {
+		list = append(list, r.bindings[id].Binding)
+	}
+	return list
+}
@@ -0,0 +1,56 @@
+package hotkey
+
+import "testing"
+
+func TestRegistry(t *testing.T) {
+	var r registry
+
+	quick := Spec{Modifiers: ModCtrl | ModShift, Key: "V"}
+	pause := Spec{Modifiers: ModCtrl | ModAlt, Key: "P"}
+
+	id1 := r.newID()
+	if err := r.conflict(quick); err != nil {
+		t.Fatalf("unexpected conflict: %v", err)
+	}
+	called := 0
+	r.add(id1, quick, func() { called++ })
+
+	id2 := r.newID()
+	if id2 == id1 {
+		t.Fatal("Expected unique IDs")
+	}
+	r.add(id2, pause, func() {})
+
+	if err := r.conflict(quick); err == nil {
+		t.Error("Expected conflict for duplicate spec")
+	}
+
+	b, ok := r.get(id1)
+	if !ok || b.Spec != quick {
+		t.Fatalf("get(%d) = %+v, %v", id1, b, ok)
+	}
+	b.callback()
+	if called != 1 {
+		t.Error("Expected callback of first binding to run")
+	}
+
+	list := r.list()
+	if len(list) != 2 || list[0].ID != id1 || list[1].ID != id2 {
+		t.Errorf("list() = %+v", list)
+	}
+
+	if _, ok := r.remove(id1); !ok {
+		t.Error("Expected remove to succeed")
+	}
+	if _, ok := r.remove(id1); ok {
+		t.Error("Expected second remove to fail")
+	}
+	if err := r.conflict(quick); err != nil {
+		t.Errorf("Spec should be free after remove: %v", err)
+	}
+
+	// 删除后 ID 不会被重新分配
+	if id3 := r.newID(); id3 == id1 || id3 == id2 {
+		t.Errorf("ID %d reused", id3)
+	}
+}
@@ -0,0 +1,227 @@
+package hotkey
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modifier 修饰键
+type Modifier uint8
+
+const (
+	ModCtrl Modifier = 1 << iota
+	ModShift
+	ModAlt
+	ModSuper // Windows 键 / Meta / Command
+)
+
+// modifierOrder 格式化时修饰键的顺序
+var modifierOrder = []struct {
+	mod  Modifier
+	name string
+}{
+	{ModCtrl, "Ctrl"},
+	{ModShift, "Shift"},
+	{ModAlt, "Alt"},
+	{ModSuper, "Super"},
+}
+
+// modifierAliases 解析时接受的修饰键名称（小写）
+var modifierAliases = map[string]Modifier{
+	"ctrl":    ModCtrl,
+	"control": ModCtrl,
+	"shift":   ModShift,
+	"alt":     ModAlt,
+	"option":  ModAlt,
+	"super":   ModSuper,
+	"win":     ModSuper,
+	"meta":    ModSuper,
+	"cmd":     ModSuper,
+	"command": ModSuper,
+}
+
+// namedKeys 非字母数字的按键：小写别名 -> 规范名称
+var namedKeys = map[string]string{
+	"space":      "Space",
+	"enter":      "Enter",
+	"return":     "Enter",
+	"tab":        "Tab",
+	"esc":        "Escape",
+	"escape":     "Escape",
+	"backspace":  "Backspace",
+	"delete":     "Delete",
+	"del":        "Delete",
+	"insert":     "Insert",
+	"ins":        "Insert",
+	"home":       "Home",
+	"end":        "End",
+	"pageup":     "PageUp",
+	"pgup":       "PageUp",
+	"pagedown":   "PageDown",
+	"pgdn":       "PageDown",
+	"up":         "Up",
+	"arrowup":    "Up",
+	"down":       "Down",
+	"arrowdown":  "Down",
+	"left":       "Left",
+	"arrowleft":  "Left",
+	"right":      "Right",
+	"arrowright": "Right",
+	"`":          "`",
+	"backquote":  "`",
+	"-":          "-",
+	"minus":      "-",
+	"=":          "=",
+	"equal":      "=",
+	"+":          "Plus",
+	"plus":       "Plus",
+	"[":          "[",
+	"]":          "]",
+	"\\":         "\\",
+	";":          ";",
+	"'":          "'",
+	",":          ",",
+	"comma":      ",",
+	".":          ".",
+	"period":     ".",
+	"/":          "/",
+	"slash":      "/",
+}
+
+// Spec 热键组合，如 Ctrl+Shift+V
+type Spec struct {
+	Modifiers Modifier
+	Key       string // 规范化的键名：A-Z、0-9、F1-F24、Space、Plus 等命名键或标点
+}
+
+// DefaultSpec 默认的全局热键 Ctrl+Shift+V
+var DefaultSpec = Spec{Modifiers: ModCtrl | ModShift, Key: "V"}
+
+// ParseSpec 解析 "Ctrl+Shift+V" 形式的热键字符串，大小写不敏感。
+// "+" 键写作 "Ctrl+Plus" 或 "Ctrl++"。
+func ParseSpec(s string) (Spec, error) {
+	var spec Spec
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return spec, fmt.Errorf("empty hotkey")
+	}
+
+	parts := splitSpec(s)
+	for i, part := range parts {
+		if part == "" {
+			return spec, fmt.Errorf("invalid hotkey %q", s)
+		}
+		if mod, ok := modifierAliases[strings.ToLower(part)]; ok && i < len(parts)-1 {
+			if spec.Modifiers&mod != 0 {
+				return spec, fmt.Errorf("duplicate modifier %q in %q", part, s)
+			}
+			spec.Modifiers |= mod
+			continue
+		}
+		if i != len(parts)-1 {
+			return spec, fmt.Errorf("only the last part of %q may be a key, got %q", s, part)
+		}
+		key, ok := normalizeKey(part)
+		if !ok {
+			return spec, fmt.Errorf("unknown key %q", part)
+		}
+		spec.Key = key
+	}
+
+	return spec, spec.Validate()
+}
+
+// splitSpec 按 "+" 拆分并去掉每段的空白，末尾的 "++" 拆为分隔符和 "+" 键
+func splitSpec(s string) []string {
+	if rest, ok := strings.CutSuffix(s, "+"); ok {
+		rest = strings.TrimSpace(rest)
+		if rest == "" {
+			return []string{"+"}
+		}
+		if rest, ok := strings.CutSuffix(rest, "+"); ok {
+			return append(splitSpec(strings.TrimSpace(rest)), "+")
+		}
+	}
+
+	parts := strings.Split(s, "+")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// normalizeKey 将键名规范化
+func normalizeKey(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	if key, ok := namedKeys[lower]; ok {
+		return key, true
+	}
+	if len(name) == 1 {
+		c := name[0]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return string(c - 'a' + 'A'), true
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return name, true
+		}
+	}
+	if n, ok := functionKeyNumber(lower); ok {
+		return fmt.Sprintf("F%d", n), true
+	}
+	return "", false
+}
+
+// functionKeyNumber 解析 F1-F24
+func functionKeyNumber(name string) (int, bool) {
+	name = strings.ToLower(name)
+	if len(name) < 2 || name[0] != 'f' {
+		return 0, false
+	}
+	n := 0
+	for _, c := range name[1:] {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	if n < 1 || n > 24 {
+		return 0, false
+	}
+	return n, true
+}
+
+// IsFunctionKey 主键是否为 F1-F24
+func (s Spec) IsFunctionKey() bool {
+	_, ok := functionKeyNumber(s.Key)
+	return ok
+}
+
+// Validate 检查热键是否可以作为全局热键使用：
+// 除功能键外必须包含 Shift 以外的修饰键，否则会干扰正常输入
+func (s Spec) Validate() error {
+	if s.Key == "" {
+		return fmt.Errorf("hotkey has no key")
+	}
+	if _, ok := normalizeKey(s.Key); !ok {
+		return fmt.Errorf("unknown key %q", s.Key)
+	}
+	if s.IsFunctionKey() {
+		return nil
+	}
+	if s.Modifiers&^ModShift == 0 {
+		return fmt.Errorf("hotkey %s needs Ctrl, Alt or Super", s)
+	}
+	return nil
+}
+
+// String 格式化为 "Ctrl+Shift+V" 形式，ParseSpec 可以还原
+func (s Spec) String() string {
+	parts := make([]string, 0, 5)
+	for _, m := range modifierOrder {
+		if s.Modifiers&m.mod != 0 {
+			parts = append(parts, m.name)
+		}
+	}
+	parts = append(parts, s.Key)
+	return strings.Join(parts, "+")
+}
@@ -0,0 +1,96 @@
+package hotkey
+
+import "testing"
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Spec
+	}{
+		{"Ctrl+Shift+V", Spec{ModCtrl | ModShift, "V"}},
+		{"ctrl + shift + v", Spec{ModCtrl | ModShift, "V"}},
+		{"Shift+Control+v", Spec{ModCtrl | ModShift, "V"}},
+		{"Alt+Win+1", Spec{ModAlt | ModSuper, "1"}},
+		{"Cmd+Option+Space", Spec{ModSuper | ModAlt, "Space"}},
+		{"F5", Spec{0, "F5"}},
+		{"shift+f24", Spec{ModShift, "F24"}},
+		{"Ctrl+Esc", Spec{ModCtrl, "Escape"}},
+		{"Ctrl+ArrowUp", Spec{ModCtrl, "Up"}},
+		{"Ctrl+PgDn", Spec{ModCtrl, "PageDown"}},
+		{"Ctrl+`", Spec{ModCtrl, "`"}},
+		{"Ctrl+Alt+/", Spec{ModCtrl | ModAlt, "/"}},
+		{"Ctrl+\\", Spec{ModCtrl, "\\"}},
+		{"Meta+Shift+;", Spec{ModSuper | ModShift, ";"}},
+		{"Ctrl+Plus", Spec{ModCtrl, "Plus"}},
+		{"Ctrl+Shift++", Spec{ModCtrl | ModShift, "Plus"}},
+		{"ctrl + +", Spec{ModCtrl, "Plus"}},
+		{"Ctrl+=", Spec{ModCtrl, "="}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpec(tt.input)
+		if err != nil {
+			t.Errorf("ParseSpec(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSpec(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSpecErrors(t *testing.T) {
+	inputs := []string{
+		"",
+		"Ctrl",
+		"Ctrl+Shift",
+		"V",
+		"Shift+V",
+		"Ctrl+Ctrl+V",
+		"Ctrl+V+B",
+		"Ctrl++V",
+		"Ctrl+",
+		"Ctrl+++",
+		"+",
+		"Ctrl+F25",
+		"Ctrl+F0",
+		"Ctrl+Hyper",
+		"Ctrl+é",
+	}
+	for _, input := range inputs {
+		if spec, err := ParseSpec(input); err == nil {
+			t.Errorf("ParseSpec(%q) = %+v, expected error", input, spec)
+		}
+	}
+}
+
+func TestSpecRoundTrip(t *testing.T) {
+	keys := []string{"A", "Z", "0", "9", "F1", "F12", "F24", "Space", "Enter", "Tab", "Escape",
+		"Backspace", "Delete", "Insert", "Home", "End", "PageUp", "PageDown", "Up", "Down",
+		"Left", "Right", "`", "-", "=", "Plus", "[", "]", "\\", ";", "'", ",", ".", "/"}
+	for _, key := range keys {
+		for mods := ModCtrl; mods <= ModCtrl|ModShift|ModAlt|ModSuper; mods++ {
+			spec := Spec{Modifiers: mods, Key: key}
+			if spec.Validate() != nil {
+				continue
+			}
+			parsed, err := ParseSpec(spec.String())
+			if err != nil {
+				t.Errorf("ParseSpec(%q) failed: %v", spec, err)
+				continue
+			}
+			if parsed != spec {
+				t.Errorf("round trip %q = %+v, want %+v", spec, parsed, spec)
+			}
+		}
+	}
+}
+
+func TestSpecString(t *testing.T) {
+	spec := Spec{Modifiers: ModSuper | ModAlt | ModShift | ModCtrl, Key: "K"}
+	if got := spec.String(); got != "Ctrl+Shift+Alt+Super+K" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := DefaultSpec.String(); got != "Ctrl+Shift+V" {
+		t.Errorf("DefaultSpec.String() = %q", got)
+	}
+}
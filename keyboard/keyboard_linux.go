@@ -0,0 +1,169 @@
+//go:build linux
+
+package keyboard
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"clipboard-monitor/wayland"
+)
+
+// injector 按键注入方式
+type injector interface {
+	// sendChord 依次按下 keys 中的按键，再按相反顺序释放
+	sendChord(keys []linuxKey) error
+	// typeText 逐字符输入文本，delay 为字符之间的间隔
+	typeText(text string, delay time.Duration) error
+	// isPressed 检查按键当前是否处于按下状态
+	isPressed(key linuxKey) (bool, error)
+	close()
+}
+
+var (
+	injectorMu sync.Mutex
+	current    injector
+)
+
+// currentInjector 返回当前使用的注入方式，首次调用时按运行环境选择：
+// X11 会话优先使用 XTest，Wayland 会话使用 /dev/uinput 虚拟键盘
+// （XTest 在 Wayland 下只能把按键发送给 XWayland 窗口）。
+// 可以通过 CLIPBOARD_MONITOR_KEYBOARD=xtest|uinput 强制指定。
+func currentInjector() (injector, error) {
+	injectorMu.Lock()
+	defer injectorMu.Unlock()
+
+	if current != nil {
+		return current, nil
+	}
+
+	order := []string{"xtest", "uinput"}
+	if os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("DISPLAY") == "" {
+		order = []string{"uinput", "xtest"}
+	}
+	if forced := os.Getenv("CLIPBOARD_MONITOR_KEYBOARD"); forced != "" {
+		order = []string{forced}
+	}
+
+	var errs []string
+	for _, name := range order {
+		var inj injector
+		var err error
+		switch name {
+		case "xtest":
+			inj, err = newXTestInjector(os.Getenv("DISPLAY"))
+		case "uinput":
+			var u *uinputInjector
+			if u, err = newUinputInjector(uinputPath, uinputDeviceName); err == nil {
+				u.layout = waylandLayout()
+				inj = u
+			}
+		default:
+			err = fmt.Errorf("unknown keyboard injection method")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		log.Printf("使用 %s 模拟键盘输入", name)
+		current = inj
+		return current, nil
+	}
+	return nil, fmt.Errorf("keyboard simulation not available: %s", strings.Join(errs, "; "))
+}
+
+// waylandLayout 读取合成器当前的键盘布局，供 uinput 按布局查找按键。
+// 不在 Wayland 会话中或读取失败时返回 nil，按美式键盘布局输入。
+func waylandLayout() xkbLayout {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return nil
+	}
+	keymap, err := wayland.Keymap("")
+	if err != nil {
+		log.Printf("无法读取键盘布局，按美式键盘布局输入: %v", err)
+		return nil
+	}
+	layout, err := parseXKBKeymap(keymap)
+	if err != nil {
+		log.Printf("无法解析键盘布局，按美式键盘布局输入: %v", err)
+		return nil
+	}
+	return layout
+}
+
+// resetInjector 注入失败时关闭当前注入方式，下次调用时重新选择
+func resetInjector(inj injector) {
+	injectorMu.Lock()
+	defer injectorMu.Unlock()
+	if current == inj {
+		current.close()
+		current = nil
+	}
+}
+
+// sendVKs 按下并释放一组虚拟键码
+func sendVKs(vks ...int) error {
+	keys := make([]linuxKey, len(vks))
+	for i, vk := range vks {
+		key, ok := lookupVK(vk)
+		if !ok {
+			return fmt.Errorf("unsupported virtual key code 0x%x", vk)
+		}
+		keys[i] = key
+	}
+
+	inj, err := currentInjector()
+	if err != nil {
+		return err
+	}
+	if err := inj.sendChord(keys); err != nil {
+		resetInjector(inj)
+		return err
+	}
+	return nil
+}
+
+// SendCtrlV 发送 Ctrl+V 组合键
+func SendCtrlV() error {
+	return sendVKs(VK_CONTROL, VK_V)
+}
+
+// SendKey 发送单个按键
+func SendKey(vkCode int) error {
+	return sendVKs(vkCode)
+}
+
+// IsKeyPressed 检查按键是否被按下（uinput 方式无法查询，始终返回 false）
+func IsKeyPressed(vkCode int) bool {
+	key, ok := lookupVK(vkCode)
+	if !ok {
+		return false
+	}
+	inj, err := currentInjector()
+	if err != nil {
+		return false
+	}
+	pressed, err := inj.isPressed(key)
+	return err == nil && pressed
+}
+
+// TypeText 逐字符模拟输入文本，delay 为每个字符之间的间隔。
+// XTest 方式可以输入任意 Unicode 字符（临时修改键盘映射），
+// uinput 方式只能输入当前键盘布局上不需要 AltGr 的字符。
+func TypeText(text string, delay time.Duration) error {
+	inj, err := currentInjector()
+	if err != nil {
+		return err
+	}
+	err = inj.typeText(text, delay)
+	var unsupported unsupportedCharError
+	if err != nil && !errors.As(err, &unsupported) {
+		resetInjector(inj)
+	}
+	return err
+}
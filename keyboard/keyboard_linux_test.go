@@ -0,0 +1,386 @@
+//go:build linux
+
+package keyboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestLookupVK(t *testing.T) {
+	tests := []struct {
+		vk     int
+		keysym uint32
+		code   uint16
+	}{
+		{VK_CONTROL, 0xffe3, 29},
+		{VK_V, 'v', 47},
+		{'A', 'a', 30},
+		{'Z', 'z', 44},
+		{'1', '1', 2},
+		{'0', '0', 11},
+		{VK_F1, 0xffbe, 59},
+		{VK_F12, 0xffc9, 88},
+		{VK_RETURN, 0xff0d, 28},
+	}
+	for _, tt := range tests {
+		key, ok := lookupVK(tt.vk)
+		if !ok {
+			t.Errorf("lookupVK(0x%x) not found", tt.vk)
+			continue
+		}
+		if key.keysym != tt.keysym || key.code != tt.code {
+			t.Errorf("lookupVK(0x%x) = {%#x, %d}, want {%#x, %d}", tt.vk, key.keysym, key.code, tt.keysym, tt.code)
+		}
+	}
+	if _, ok := lookupVK(0xFF); ok {
+		t.Error("Expected unknown virtual key to be rejected")
+	}
+}
+
+func TestRuneKeysym(t *testing.T) {
+	tests := map[rune]uint32{
+		'a':  'a',
+		'~':  '~',
+		'é':  0xe9,
+		'剪':  0x01000000 | '剪',
+		'😀':  0x01000000 | '😀',
+		'\n': 0xff0d,
+		'\t': 0xff09,
+	}
+	for r, want := range tests {
+		if got := runeKeysym(r); got != want {
+			t.Errorf("runeKeysym(%q) = %#x, want %#x", r, got, want)
+		}
+	}
+}
+
+func TestUSKeyCoversPrintableASCII(t *testing.T) {
+	seen := make(map[[2]uint16]rune)
+	for r := rune(0x20); r <= 0x7e; r++ {
+		code, shift, ok := usKey(r)
+		if !ok {
+			t.Errorf("usKey(%q) not found", r)
+			continue
+		}
+		pos := [2]uint16{code, 0}
+		if shift {
+			pos[1] = 1
+		}
+		if other, dup := seen[pos]; dup {
+			t.Errorf("%q and %q map to the same key", other, r)
+		}
+		seen[pos] = r
+	}
+	if _, _, ok := usKey('剪'); ok {
+		t.Error("Expected non-ASCII rune to be unsupported")
+	}
+}
+
+// startXvfb 启动一个 Xvfb 实例并返回其显示名，未安装 Xvfb 时跳过测试
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("Xvfb"); err != nil {
+		t.Skip("Xvfb not installed")
+	}
+
+	for n := 70; n < 80; n++ {
+		socket := fmt.Sprintf("/tmp/.X11-unix/X%d", n)
+		if _, err := os.Stat(socket); err == nil {
+			continue
+		}
+		display := fmt.Sprintf(":%d", n)
+		cmd := exec.Command("Xvfb", display, "-nolisten", "tcp")
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("failed to start Xvfb: %v", err)
+		}
+		t.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, err := os.Stat(socket); err == nil {
+				return display
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatal("Xvfb did not start in time")
+	}
+	t.Skip("no free X display number")
+	return ""
+}
+
+// grabKeyboard 创建一个窗口并抓取键盘，返回的连接会收到全部按键事件
+func grabKeyboard(t *testing.T, display string) *xgb.Conn {
+	t.Helper()
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(conn.Close)
+
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.CreateWindow(conn, screen.RootDepth, win, screen.Root, 0, 0, 10, 10, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual,
+		xproto.CwEventMask, []uint32{xproto.EventMaskKeyPress | xproto.EventMaskKeyRelease})
+	xproto.MapWindow(conn, win)
+
+	reply, err := xproto.GrabKeyboard(conn, true, win, xproto.TimeCurrentTime,
+		xproto.GrabModeAsync, xproto.GrabModeAsync).Reply()
+	if err != nil || reply.Status != xproto.GrabStatusSuccess {
+		t.Fatalf("GrabKeyboard failed: %v", err)
+	}
+	return conn
+}
+
+// nextKeyPress 等待下一个按键按下事件
+func nextKeyPress(t *testing.T, conn *xgb.Conn) xproto.KeyPressEvent {
+	t.Helper()
+	events := make(chan xproto.KeyPressEvent, 1)
+	go func() {
+		for {
+			ev, err := conn.WaitForEvent()
+			if ev == nil && err == nil {
+				return
+			}
+			if press, ok := ev.(xproto.KeyPressEvent); ok {
+				events <- press
+				return
+			}
+		}
+	}()
+	select {
+	case press := <-events:
+		return press
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for key press")
+		return xproto.KeyPressEvent{}
+	}
+}
+
+func TestXTestSendChord(t *testing.T) {
+	display := startXvfb(t)
+	listener := grabKeyboard(t, display)
+
+	inj, err := newXTestInjector(display)
+	if err != nil {
+		t.Skipf("XTest unavailable: %v", err)
+	}
+	defer inj.close()
+
+	ctrl, _ := lookupVK(VK_CONTROL)
+	v, _ := lookupVK(VK_V)
+	vCode, found, err := inj.keycodeFor(xproto.Keysym(v.keysym))
+	if err != nil || !found {
+		t.Fatalf("keycodeFor(v) = %d, %v, %v", vCode, found, err)
+	}
+
+	if err := inj.sendChord([]linuxKey{ctrl, v}); err != nil {
+		t.Fatalf("sendChord failed: %v", err)
+	}
+
+	nextKeyPress(t, listener) // Control
+	press := nextKeyPress(t, listener)
+	if press.Detail != vCode {
+		t.Errorf("Expected keycode %d, got %d", vCode, press.Detail)
+	}
+	if press.State&xproto.ModMaskControl == 0 {
+		t.Errorf("Expected Control modifier in state %#x", press.State)
+	}
+}
+
+func TestXTestRemapsMissingKeysym(t *testing.T) {
+	display := startXvfb(t)
+	listener := grabKeyboard(t, display)
+
+	inj, err := newXTestInjector(display)
+	if err != nil {
+		t.Skipf("XTest unavailable: %v", err)
+	}
+	defer inj.close()
+
+	// U+263A WHITE SMILING FACE，默认键盘映射中没有
+	smile := linuxKey{keysym: 0x100263a}
+	if _, found, _ := inj.keycodeFor(xproto.Keysym(smile.keysym)); found {
+		t.Skip("keysym already mapped")
+	}
+	if err := inj.sendChord([]linuxKey{smile}); err != nil {
+		t.Fatalf("sendChord failed: %v", err)
+	}
+	press := nextKeyPress(t, listener)
+
+	// 发送后临时映射应已恢复
+	if _, found, _ := inj.keycodeFor(xproto.Keysym(smile.keysym)); found {
+		t.Error("Expected temporary mapping to be removed")
+	}
+	reply, err := xproto.GetKeyboardMapping(listener, press.Detail, 1).Reply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sym := range reply.Keysyms {
+		if sym != 0 {
+			t.Errorf("Keycode %d still mapped to %#x", press.Detail, sym)
+		}
+	}
+}
+
+func TestXTestTypeText(t *testing.T) {
+	display := startXvfb(t)
+	listener := grabKeyboard(t, display)
+
+	inj, err := newXTestInjector(display)
+	if err != nil {
+		t.Skipf("XTest unavailable: %v", err)
+	}
+	defer inj.close()
+
+	mapping, err := inj.keyboardMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aCode, _, _ := mapping.find('a')
+	shiftCode, _, _ := mapping.find(xkShiftL)
+
+	if err := inj.typeText("aA剪", 0); err != nil {
+		t.Fatalf("typeText failed: %v", err)
+	}
+
+	if press := nextKeyPress(t, listener); press.Detail != aCode {
+		t.Errorf("Expected 'a' keycode %d, got %d", aCode, press.Detail)
+	}
+	if press := nextKeyPress(t, listener); press.Detail != shiftCode {
+		t.Errorf("Expected Shift keycode %d, got %d", shiftCode, press.Detail)
+	}
+	if press := nextKeyPress(t, listener); press.Detail != aCode || press.State&xproto.ModMaskShift == 0 {
+		t.Errorf("Expected shifted 'a', got keycode %d state %#x", press.Detail, press.State)
+	}
+	// 布局中没有的字符通过临时映射输入，结束后映射被清除
+	press := nextKeyPress(t, listener)
+	reply, err := xproto.GetKeyboardMapping(listener, press.Detail, 1).Reply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sym := range reply.Keysyms {
+		if sym != 0 {
+			t.Errorf("Keycode %d still mapped to %#x", press.Detail, sym)
+		}
+	}
+}
+
+// findEventDevice 按设备名查找 uinput 创建的 /dev/input/eventN
+func findEventDevice(t *testing.T, name string) string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		paths, _ := filepath.Glob("/sys/class/input/event*/device/name")
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err == nil && strings.TrimSpace(string(data)) == name {
+				return filepath.Join("/dev/input", filepath.Base(filepath.Dir(filepath.Dir(path))))
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("input device %q not found", name)
+	return ""
+}
+
+func TestUinputLoopback(t *testing.T) {
+	name := fmt.Sprintf("clipboard-monitor test keyboard %d", os.Getpid())
+	inj, err := newUinputInjector(uinputPath, name)
+	if err != nil {
+		t.Skipf("uinput unavailable: %v", err)
+	}
+	defer inj.close()
+
+	reader, err := os.Open(findEventDevice(t, name))
+	if err != nil {
+		t.Skipf("cannot read input device: %v", err)
+	}
+	defer reader.Close()
+
+	ctrl, _ := lookupVK(VK_CONTROL)
+	v, _ := lookupVK(VK_V)
+	if err := inj.sendChord([]linuxKey{ctrl, v}); err != nil {
+		t.Fatalf("sendChord failed: %v", err)
+	}
+
+	want := []struct {
+		code  uint16
+		value int32
+	}{{29, 1}, {47, 1}, {47, 0}, {29, 0}}
+	reader.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var ev inputEvent
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&ev)), unsafe.Sizeof(ev))
+	for i := 0; i < len(want); {
+		if _, err := reader.Read(buf); err != nil {
+			t.Fatalf("failed to read event %d: %v", i, err)
+		}
+		if ev.Type != evKey {
+			continue
+		}
+		if ev.Code != want[i].code || ev.Value != want[i].value {
+			t.Errorf("event %d = {%d, %d}, want {%d, %d}", i, ev.Code, ev.Value, want[i].code, want[i].value)
+		}
+		i++
+	}
+}
+
+func TestUinputTypeText(t *testing.T) {
+	name := fmt.Sprintf("clipboard-monitor test keyboard %d", os.Getpid())
+	inj, err := newUinputInjector(uinputPath, name)
+	if err != nil {
+		t.Skipf("uinput unavailable: %v", err)
+	}
+	defer inj.close()
+
+	if err := inj.typeText("a剪", 0); err == nil {
+		t.Error("Expected error for non-ASCII text")
+	}
+
+	reader, err := os.Open(findEventDevice(t, name))
+	if err != nil {
+		t.Skipf("cannot read input device: %v", err)
+	}
+	defer reader.Close()
+
+	if err := inj.typeText("a!", 0); err != nil {
+		t.Fatalf("typeText failed: %v", err)
+	}
+
+	// a 按下/释放，然后 Shift+1
+	want := []struct {
+		code  uint16
+		value int32
+	}{{30, 1}, {30, 0}, {42, 1}, {2, 1}, {2, 0}, {42, 0}}
+	reader.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var ev inputEvent
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&ev)), unsafe.Sizeof(ev))
+	for i := 0; i < len(want); {
+		if _, err := reader.Read(buf); err != nil {
+			t.Fatalf("failed to read event %d: %v", i, err)
+		}
+		if ev.Type != evKey {
+			continue
+		}
+		if ev.Code != want[i].code || ev.Value != want[i].value {
+			t.Errorf("event %d = {%d, %d}, want {%d, %d}", i, ev.Code, ev.Value, want[i].code, want[i].value)
+		}
+		i++
+	}
+}
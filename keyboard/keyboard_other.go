@@ -1,8 +1,11 @@
-//go:build !windows
+//go:build !windows && !linux
 
 package keyboard
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // SendCtrlV 发送 Ctrl+V 组合键 (非Windows平台暂不支持)
 func SendCtrlV() error {
@@ -18,3 +21,8 @@ func SendKey(vkCode int) error {
 func IsKeyPressed(vkCode int) bool {
 	return false
 }
+
+// TypeText 逐字符模拟输入文本 (当前平台暂不支持)
+func TypeText(text string, delay time.Duration) error {
+	return fmt.Errorf("keyboard simulation not supported on this platform")
+}
@@ -3,26 +3,47 @@
 package keyboard
 
 import (
+	"fmt"
 	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
 )
 
 var (
 	user32          = syscall.NewLazyDLL("user32.dll")
 	procKeybd_event = user32.NewProc("keybd_event")
 	procGetKeyState = user32.NewProc("GetKeyState")
+	procSendInput   = user32.NewProc("SendInput")
 )
 
-// Virtual key codes
+// Key event flags
 const (
-	VK_CONTROL = 0x11
-	VK_V       = 0x56
+	KEYEVENTF_KEYUP   = 0x0002
+	KEYEVENTF_UNICODE = 0x0004
 )
 
-// Key event flags
+// INPUT 类型
 const (
-	KEYEVENTF_KEYUP = 0x0002
+	INPUT_KEYBOARD = 1
 )
 
+// KEYBDINPUT 结构体
+type KEYBDINPUT struct {
+	Vk        uint16
+	Scan      uint16
+	Flags     uint32
+	Time      uint32
+	ExtraInfo uintptr
+}
+
+// INPUT 结构体（键盘输入），联合体按其中最大的 MOUSEINPUT 补齐
+type INPUT struct {
+	Type uint32
+	Ki   KEYBDINPUT
+	_    [8]byte
+}
+
 // SendCtrlV 发送 Ctrl+V 组合键
 func SendCtrlV() error {
 	// 按下 Ctrl 键
@@ -86,3 +107,46 @@ func IsKeyPressed(vkCode int) bool {
 	ret, _, _ := procGetKeyState.Call(uintptr(vkCode))
 	return (ret & 0x8000) != 0
 }
+
+// TypeText 逐字符模拟输入文本，使用 KEYEVENTF_UNICODE 直接发送字符，与键盘布局无关；
+// delay 为每个字符之间的间隔
+func TypeText(text string, delay time.Duration) error {
+	for _, r := range normalizeNewlines(text) {
+		var inputs []INPUT
+		switch r {
+		case '\n':
+			inputs = vkInputs(VK_RETURN)
+		case '\t':
+			inputs = vkInputs(VK_TAB)
+		default:
+			// 基本多文种平面以外的字符需要按 UTF-16 代理对分两次发送
+			for _, unit := range utf16.Encode([]rune{r}) {
+				inputs = append(inputs,
+					INPUT{Type: INPUT_KEYBOARD, Ki: KEYBDINPUT{Scan: unit, Flags: KEYEVENTF_UNICODE}},
+					INPUT{Type: INPUT_KEYBOARD, Ki: KEYBDINPUT{Scan: unit, Flags: KEYEVENTF_UNICODE | KEYEVENTF_KEYUP}},
+				)
+			}
+		}
+
+		sent, _, err := procSendInput.Call(
+			uintptr(len(inputs)),
+			uintptr(unsafe.Pointer(&inputs[0])),
+			unsafe.Sizeof(inputs[0]),
+		)
+		if int(sent) != len(inputs) {
+			return fmt.Errorf("SendInput failed: %v", err)
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
+
+// vkInputs 按下并释放一个虚拟键
+func vkInputs(vk uint16) []INPUT {
+	return []INPUT{
+		{Type: INPUT_KEYBOARD, Ki: KEYBDINPUT{Vk: vk}},
+		{Type: INPUT_KEYBOARD, Ki: KEYBDINPUT{Vk: vk, Flags: KEYEVENTF_KEYUP}},
+	}
+}
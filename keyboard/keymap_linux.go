@@ -0,0 +1,106 @@
+//go:build linux
+
+package keyboard
+
+// linuxKey 虚拟键码在 Linux 上的两种表示
+type linuxKey struct {
+	keysym uint32 // X11 keysym，XTest 注入时按当前键盘映射查找对应键码
+	code   uint16 // evdev 键码（linux/input-event-codes.h），uinput 注入时使用
+}
+
+// linuxNamedKeys 非字母数字键
+var linuxNamedKeys = map[int]linuxKey{
+	VK_BACK:    {0xff08, 14},  // BackSpace / KEY_BACKSPACE
+	VK_TAB:     {0xff09, 15},  // Tab / KEY_TAB
+	VK_RETURN:  {0xff0d, 28},  // Return / KEY_ENTER
+	VK_SHIFT:   {0xffe1, 42},  // Shift_L / KEY_LEFTSHIFT
+	VK_CONTROL: {0xffe3, 29},  // Control_L / KEY_LEFTCTRL
+	VK_MENU:    {0xffe9, 56},  // Alt_L / KEY_LEFTALT
+	VK_ESCAPE:  {0xff1b, 1},   // Escape / KEY_ESC
+	VK_SPACE:   {0x0020, 57},  // space / KEY_SPACE
+	VK_PRIOR:   {0xff55, 104}, // Prior / KEY_PAGEUP
+	VK_NEXT:    {0xff56, 109}, // Next / KEY_PAGEDOWN
+	VK_END:     {0xff57, 107}, // End / KEY_END
+	VK_HOME:    {0xff50, 102}, // Home / KEY_HOME
+	VK_LEFT:    {0xff51, 105}, // Left / KEY_LEFT
+	VK_UP:      {0xff52, 103}, // Up / KEY_UP
+	VK_RIGHT:   {0xff53, 106}, // Right / KEY_RIGHT
+	VK_DOWN:    {0xff54, 108}, // Down / KEY_DOWN
+	VK_INSERT:  {0xff63, 110}, // Insert / KEY_INSERT
+	VK_DELETE:  {0xffff, 111}, // Delete / KEY_DELETE
+	VK_LWIN:    {0xffeb, 125}, // Super_L / KEY_LEFTMETA
+}
+
+// evdevLetters A-Z 在 evdev 中的键码（按物理位置编号，与美式键盘上的字母对应）
+var evdevLetters = [26]uint16{
+	30, 48, 46, 32, 18, 33, 34, 35, 23, 36, 37, 38, 50,
+	49, 24, 25, 16, 19, 31, 20, 22, 47, 17, 45, 21, 44,
+}
+
+// evdevFunctionKeys F1-F12 在 evdev 中的键码
+var evdevFunctionKeys = [12]uint16{59, 60, 61, 62, 63, 64, 65, 66, 67, 68, 87, 88}
+
+// lookupVK 将虚拟键码转换为 Linux 按键
+func lookupVK(vk int) (linuxKey, bool) {
+	if key, ok := linuxNamedKeys[vk]; ok {
+		return key, true
+	}
+	switch {
+	case vk >= 'A' && vk <= 'Z':
+		// 键盘映射中字母键的第一个 keysym 是小写
+		return linuxKey{uint32(vk - 'A' + 'a'), evdevLetters[vk-'A']}, true
+	case vk >= '1' && vk <= '9':
+		return linuxKey{uint32(vk), uint16(vk-'1') + 2}, true // KEY_1 = 2
+	case vk == '0':
+		return linuxKey{'0', 11}, true // KEY_0
+	case vk >= VK_F1 && vk <= VK_F12:
+		return linuxKey{0xffbe + uint32(vk-VK_F1), evdevFunctionKeys[vk-VK_F1]}, true
+	}
+	return linuxKey{}, false
+}
+
+// runeKeysym 字符对应的 keysym：Latin-1 字符的 keysym 等于码位，其他 Unicode 字符为 0x01000000 + 码位
+func runeKeysym(r rune) uint32 {
+	switch {
+	case r == '\n':
+		return 0xff0d // Return
+	case r == '\t':
+		return 0xff09 // Tab
+	case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+		return uint32(r)
+	}
+	return 0x01000000 | uint32(r)
+}
+
+// usShifted 美式键盘上需要 Shift 的符号及其所在按键的 evdev 键码
+var usShifted = map[rune]uint16{
+	'!': 2, '@': 3, '#': 4, '$': 5, '%': 6, '^': 7, '&': 8, '*': 9, '(': 10, ')': 11,
+	'_': 12, '+': 13, '{': 26, '}': 27, '|': 43, ':': 39, '"': 40, '~': 41, '<': 51, '>': 52, '?': 53,
+}
+
+// usUnshifted 美式键盘上不需要 Shift 的符号和空白字符的 evdev 键码
+var usUnshifted = map[rune]uint16{
+	' ': 57, '\n': 28, '\t': 15,
+	'-': 12, '=': 13, '[': 26, ']': 27, '\\': 43, ';': 39, '\'': 40, '`': 41, ',': 51, '.': 52, '/': 53,
+}
+
+// usKey 按美式键盘布局查找字符对应的 evdev 键码，uinput 无法输入布局以外的字符
+func usKey(r rune) (code uint16, shift bool, ok bool) {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return evdevLetters[r-'a'], false, true
+	case r >= 'A' && r <= 'Z':
+		return evdevLetters[r-'A'], true, true
+	case r >= '1' && r <= '9':
+		return uint16(r-'1') + 2, false, true
+	case r == '0':
+		return 11, false, true
+	}
+	if code, ok := usUnshifted[r]; ok {
+		return code, false, true
+	}
+	if code, ok := usShifted[r]; ok {
+		return code, true, true
+	}
+	return 0, false, false
+}
@@ -0,0 +1,15 @@
+package keyboard
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultTypeDelay TypeText 默认的字符间隔
+const DefaultTypeDelay = 5 * time.Millisecond
+
+// normalizeNewlines 将 \r\n 和 \r 统一为 \n，换行按 Enter 键输入
+func normalizeNewlines(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
@@ -0,0 +1,17 @@
+package keyboard
+
+import "testing"
+
+func TestNormalizeNewlines(t *testing.T) {
+	tests := map[string]string{
+		"a\r\nb":     "a\nb",
+		"a\rb\nc":    "a\nb\nc",
+		"no newline": "no newline",
+		"\r\n\r\n":   "\n\n",
+	}
+	for input, want := range tests {
+		if got := normalizeNewlines(input); got != want {
+			t.Errorf("normalizeNewlines(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
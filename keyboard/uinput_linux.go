@@ -0,0 +1,236 @@
+//go:build linux
+
+package keyboard
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	uinputPath       = "/dev/uinput"
+	uinputDeviceName = "clipboard-monitor virtual keyboard"
+
+	// uinputSettleDelay 创建设备后等待合成器识别新键盘的时间
+	uinputSettleDelay = 200 * time.Millisecond
+)
+
+// uinput ioctl 请求（linux/uinput.h）
+const (
+	uiDevCreate  = 0x5501
+	uiDevDestroy = 0x5502
+	uiDevSetup   = 0x405c5503 // _IOW('U', 3, struct uinput_setup)
+	uiSetEvBit   = 0x40045564 // _IOW('U', 100, int)
+	uiSetKeyBit  = 0x40045565 // _IOW('U', 101, int)
+)
+
+// 输入事件类型（linux/input-event-codes.h）
+const (
+	evSyn      = 0x00
+	evKey      = 0x01
+	synReport  = 0
+	busVirtual = 0x06
+	maxKeyCode = 255
+)
+
+// inputID struct input_id
+type inputID struct {
+	Bustype uint16
+	Vendor  uint16
+	Product uint16
+	Version uint16
+}
+
+// uinputSetup struct uinput_setup
+type uinputSetup struct {
+	ID           inputID
+	Name         [80]byte
+	FFEffectsMax uint32
+}
+
+// uinputUserDev struct uinput_user_dev，内核不支持 UI_DEV_SETUP (4.5 之前) 时写入设备描述
+type uinputUserDev struct {
+	Name         [80]byte
+	ID           inputID
+	FFEffectsMax uint32
+	Absmax       [64]int32
+	Absmin       [64]int32
+	Absfuzz      [64]int32
+	Absflat      [64]int32
+}
+
+// inputEvent struct input_event
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// uinputInjector 通过 /dev/uinput 创建虚拟键盘注入按键，Wayland 下也可用。
+// 注入的是物理键位（evdev 键码），由合成器按当前布局转换为字符，
+// 因此需要按当前布局查找字符所在的键；没有布局信息时按美式键盘布局。
+type uinputInjector struct {
+	mu     sync.Mutex
+	f      *os.File
+	layout xkbLayout // 合成器的键盘布局，为 nil 时按美式键盘布局
+}
+
+// newUinputInjector 创建虚拟键盘设备
+func newUinputInjector(path, name string) (*uinputInjector, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioctl(f, uiSetEvBit, evKey); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("UI_SET_EVBIT failed: %v", err)
+	}
+	for code := uintptr(1); code <= maxKeyCode; code++ {
+		if err := ioctl(f, uiSetKeyBit, code); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("UI_SET_KEYBIT failed: %v", err)
+		}
+	}
+
+	id := inputID{Bustype: busVirtual, Vendor: 0x1209, Product: 0x0001, Version: 1}
+	setup := uinputSetup{ID: id}
+	copy(setup.Name[:len(setup.Name)-1], name)
+	if err := ioctl(f, uiDevSetup, uintptr(unsafe.Pointer(&setup))); err != nil {
+		// 旧内核：直接写入 uinput_user_dev
+		dev := uinputUserDev{ID: id}
+		copy(dev.Name[:len(dev.Name)-1], name)
+		buf := unsafe.Slice((*byte)(unsafe.Pointer(&dev)), unsafe.Sizeof(dev))
+		if _, err := f.Write(buf); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to set up uinput device: %v", err)
+		}
+	}
+
+	if err := ioctl(f, uiDevCreate, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("UI_DEV_CREATE failed: %v", err)
+	}
+	time.Sleep(uinputSettleDelay)
+
+	return &uinputInjector{f: f}, nil
+}
+
+// sendChord 依次按下 keys 中的按键，再按相反顺序释放
+func (u *uinputInjector) sendChord(keys []linuxKey) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	codes := make([]uint16, len(keys))
+	for i, key := range keys {
+		// 字母键按布局查找，Ctrl+V 在 Dvorak、AZERTY 等布局下才会按到 V
+		codes[i] = key.code
+		if k, ok := u.layout[key.keysym]; ok && !k.shift {
+			codes[i] = k.code
+		}
+	}
+	for _, code := range codes {
+		if err := u.emitKey(code, 1); err != nil {
+			return err
+		}
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		if err := u.emitKey(codes[i], 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeText 按当前键盘布局逐字符输入文本，包含布局以外的字符时不输入任何内容
+func (u *uinputInjector) typeText(text string, delay time.Duration) error {
+	text = normalizeNewlines(text)
+	for _, r := range text {
+		if _, _, ok := u.runeKey(r); !ok {
+			return unsupportedCharError{r}
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	shift, _ := lookupVK(VK_SHIFT)
+	for _, r := range text {
+		code, needShift, _ := u.runeKey(r)
+		if needShift {
+			if err := u.emitKey(shift.code, 1); err != nil {
+				return err
+			}
+		}
+		if err := u.emitKey(code, 1); err != nil {
+			return err
+		}
+		if err := u.emitKey(code, 0); err != nil {
+			return err
+		}
+		if needShift {
+			if err := u.emitKey(shift.code, 0); err != nil {
+				return err
+			}
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
+
+// runeKey 查找输入字符需要按下的键，有键盘布局时按布局查找，否则按美式键盘布局
+func (u *uinputInjector) runeKey(r rune) (code uint16, shift bool, ok bool) {
+	if u.layout == nil {
+		return usKey(r)
+	}
+	key, ok := u.layout[runeKeysym(r)]
+	return key.code, key.shift, ok
+}
+
+// emitKey 写入一个按键事件和同步事件
+func (u *uinputInjector) emitKey(code uint16, value int32) error {
+	events := []inputEvent{
+		{Type: evKey, Code: code, Value: value},
+		{Type: evSyn, Code: synReport},
+	}
+	size := int(unsafe.Sizeof(inputEvent{}))
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), size*len(events))
+	if _, err := u.f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write uinput event: %v", err)
+	}
+	return nil
+}
+
+// isPressed uinput 只能写入事件，无法查询全局按键状态
+func (u *uinputInjector) isPressed(key linuxKey) (bool, error) {
+	return false, fmt.Errorf("key state not available via uinput")
+}
+
+func (u *uinputInjector) close() {
+	ioctl(u.f, uiDevDestroy, 0)
+	u.f.Close()
+}
+
+func ioctl(f *os.File, req, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// unsupportedCharError 当前注入方式无法输入的字符
+type unsupportedCharError struct {
+	r rune
+}
+
+func (e unsupportedCharError) Error() string {
+	return fmt.Sprintf("cannot type %q via uinput: the character is not on the keyboard layout", e.r)
+}
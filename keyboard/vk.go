@@ -0,0 +1,28 @@
+package keyboard
+
+// 虚拟键码，取值与 Windows 的 VK_* 相同。
+// SendKey 和 IsKeyPressed 在所有平台上都使用这套键码，字母和数字键的键码等于其大写 ASCII 码。
+const (
+	VK_BACK    = 0x08
+	VK_TAB     = 0x09
+	VK_RETURN  = 0x0D
+	VK_SHIFT   = 0x10
+	VK_CONTROL = 0x11
+	VK_MENU    = 0x12 // Alt
+	VK_ESCAPE  = 0x1B
+	VK_SPACE   = 0x20
+	VK_PRIOR   = 0x21 // Page Up
+	VK_NEXT    = 0x22 // Page Down
+	VK_END     = 0x23
+	VK_HOME    = 0x24
+	VK_LEFT    = 0x25
+	VK_UP      = 0x26
+	VK_RIGHT   = 0x27
+	VK_DOWN    = 0x28
+	VK_INSERT  = 0x2D
+	VK_DELETE  = 0x2E
+	VK_V       = 0x56
+	VK_LWIN    = 0x5B
+	VK_F1      = 0x70
+	VK_F12     = 0x7B
+)
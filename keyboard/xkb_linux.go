@@ -0,0 +1,169 @@
+//go:build linux
+
+package keyboard
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// layoutKey 当前布局下输入某个 keysym 需要按下的键
+type layoutKey struct {
+	code  uint16 // evdev 键码
+	shift bool   // keysym 在第二层，需要同时按住 Shift
+}
+
+// xkbLayout 从 XKB 键盘映射的第一个组（布局）得到的 keysym 到按键的对应关系。
+// 只记录前两层，需要 AltGr 等修饰键的第三层以上无法通过 uinput 输入。
+type xkbLayout map[uint32]layoutKey
+
+var (
+	xkbKeycodePattern = regexp.MustCompile(`<([^>]+)>\s*=\s*(\d+)\s*;`)
+	xkbAliasPattern   = regexp.MustCompile(`alias\s*<([^>]+)>\s*=\s*<([^>]+)>\s*;`)
+	xkbKeyPattern     = regexp.MustCompile(`(?s)key\s*<([^>]+)>\s*\{(.*?)\}\s*;`)
+)
+
+// xkbPunctuation ASCII 符号的 keysym 名称，keysym 的值等于字符的码位
+var xkbPunctuation = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "apostrophe": '\'', "parenleft": '(', "parenright": ')',
+	"asterisk": '*', "plus": '+', "comma": ',', "minus": '-', "period": '.', "slash": '/',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>', "question": '?',
+	"at": '@', "bracketleft": '[', "backslash": '\\', "bracketright": ']', "asciicircum": '^',
+	"underscore": '_', "grave": '`', "braceleft": '{', "bar": '|', "braceright": '}', "asciitilde": '~',
+}
+
+// parseXKBKeymap 解析合成器提供的 XKB 键盘映射（xkb_keymap_get_as_string 的输出格式）
+func parseXKBKeymap(text string) (xkbLayout, error) {
+	start := strings.Index(text, "xkb_keycodes")
+	symbolsStart := strings.Index(text, "xkb_symbols")
+	if start < 0 || symbolsStart < 0 {
+		return nil, errors.New("keymap has no keycodes or symbols section")
+	}
+	keycodesText, symbolsText := text[start:], text[symbolsStart:]
+	if symbolsStart > start {
+		keycodesText = text[start:symbolsStart]
+	}
+
+	// XKB 键码等于 evdev 键码加 8
+	codes := make(map[string]uint16)
+	for _, m := range xkbKeycodePattern.FindAllStringSubmatch(keycodesText, -1) {
+		if n, err := strconv.Atoi(m[2]); err == nil && n >= 8 && n-8 <= maxKeyCode {
+			codes[m[1]] = uint16(n - 8)
+		}
+	}
+	for _, m := range xkbAliasPattern.FindAllStringSubmatch(keycodesText, -1) {
+		if code, ok := codes[m[2]]; ok {
+			codes[m[1]] = code
+		}
+	}
+
+	layout := make(xkbLayout)
+	for _, m := range xkbKeyPattern.FindAllStringSubmatch(symbolsText, -1) {
+		code, ok := codes[m[1]]
+		// 小键盘的第二层由 NumLock 而不是 Shift 切换，不用于输入
+		if !ok || strings.HasPrefix(m[1], "KP") {
+			continue
+		}
+		for level, name := range firstGroupSymbols(m[2]) {
+			if level > 1 {
+				break
+			}
+			keysym, ok := keysymFromName(name)
+			if !ok {
+				continue
+			}
+			// 同一个 keysym 出现在多个键上时优先使用不需要 Shift 的键
+			key := layoutKey{code: code, shift: level == 1}
+			if existing, ok := layout[keysym]; !ok || existing.shift && !key.shift {
+				layout[keysym] = key
+			}
+		}
+	}
+	if len(layout) == 0 {
+		return nil, errors.New("keymap has no usable keys")
+	}
+	return layout, nil
+}
+
+// firstGroupSymbols 从按键定义中取出第一个组各层的 keysym 名称。
+// 定义可以是 "[ a, A ]"，也可以是 "type= ..., symbols[Group1]= [ a, A ], symbols[Group2]= [...]"。
+func firstGroupSymbols(body string) []string {
+	group := 0
+	for _, entry := range splitTopLevel(body) {
+		entry = strings.TrimSpace(entry)
+		var list string
+		switch {
+		case strings.HasPrefix(entry, "["):
+			group++
+			if group != 1 {
+				continue
+			}
+			list = entry
+		case strings.HasPrefix(entry, "symbols["):
+			index, value, ok := strings.Cut(entry[len("symbols["):], "]")
+			index = strings.TrimPrefix(strings.ToLower(index), "group")
+			if !ok || index != "1" {
+				continue
+			}
+			_, list, _ = strings.Cut(value, "=")
+		default:
+			continue
+		}
+		list = strings.Trim(strings.TrimSpace(list), "[]")
+		names := strings.Split(list, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		return names
+	}
+	return nil
+}
+
+// splitTopLevel 按不在方括号或圆括号内的逗号分割
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i, c := range s {
+		switch c {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// keysymFromName 把 keysym 名称转换为数值，只识别 ASCII 字符、回车和制表符，
+// 以及数值形式（0x1000439、U0439）的 keysym
+func keysymFromName(name string) (uint32, bool) {
+	switch {
+	case len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z' || name[0] >= '0' && name[0] <= '9'):
+		return uint32(name[0]), true
+	case name == "Return":
+		return runeKeysym('\n'), true
+	case name == "Tab":
+		return runeKeysym('\t'), true
+	case strings.HasPrefix(name, "0x"):
+		n, err := strconv.ParseUint(name[2:], 16, 32)
+		return uint32(n), err == nil
+	case len(name) > 1 && name[0] == 'U':
+		n, err := strconv.ParseUint(name[1:], 16, 32)
+		if err != nil {
+			return 0, false
+		}
+		return runeKeysym(rune(n)), true
+	}
+	if r, ok := xkbPunctuation[name]; ok {
+		return uint32(r), true
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+//go:build linux
+
+package keyboard
+
+import "testing"
+
+// testKeymap 按 xkbcommon 输出格式截取的 AZERTY 布局，第二组为俄语
+const testKeymap = `xkb_keymap {
+xkb_keycodes "evdev+aliases(azerty)" {
+	minimum = 8;
+	maximum = 255;
+	<AE01>               = 10;
+	<AD01>               = 24;
+	<AC01>               = 38;
+	<AB01>               = 52;
+	<AB04>               = 55;
+	<AB07>               = 58;
+	<RTRN>               = 36;
+	<KP1>                = 87;
+	<LFSH>               = 50;
+	indicator 1 = "Caps Lock";
+	alias <AC12>         = <AE01>;
+};
+
+xkb_types "complete" {
+	type "TWO_LEVEL" {
+		modifiers= Shift;
+		map[Shift]= 2;
+		level_name[1]= "Base";
+		level_name[2]= "Shift";
+	};
+};
+
+xkb_symbols "pc+fr+ru:2" {
+	name[group1]="French";
+	key <AE01>               {	type= "FOUR_LEVEL", symbols[Group1]= [       ampersand,               1,     onesuperior,      exclamdown ], symbols[Group2]= [ 1, exclam ] };
+	key <AD01>               {	type= "ALPHABETIC", symbols[Group1]= [ a, A ], symbols[Group2]= [ Cyrillic_shorti, Cyrillic_SHORTI ] };
+	key <AC01>               {	[               q,               Q ], [ Cyrillic_ef, Cyrillic_EF ] };
+	key <AB01>               {	[               w,               W ] };
+	key <AB04>               {	[               v,               V ] };
+	key <AB07>               {	[           comma,        question ] };
+	key <RTRN>               {	[          Return ] };
+	key <KP1>                {	[         KP_End,               1 ] };
+	key <LFSH>               {	[         Shift_L ] };
+	modifier_map Shift { <LFSH> };
+};
+};
+`
+
+func TestParseXKBKeymap(t *testing.T) {
+	layout, err := parseXKBKeymap(testKeymap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		r     rune
+		code  uint16
+		shift bool
+	}{
+		{'a', 16, false},  // <AD01>
+		{'A', 16, true},   // Shift+<AD01>
+		{'q', 30, false},  // <AC01>
+		{'w', 44, false},  // <AB01>
+		{'v', 47, false},  // <AB04>
+		{'&', 2, false},   // <AE01> 第一层
+		{'1', 2, true},    // <AE01> 第二层，不使用小键盘
+		{'?', 50, true},   // <AB07>
+		{'\n', 28, false}, // <RTRN>
+	}
+	for _, tt := range tests {
+		key, ok := layout[runeKeysym(tt.r)]
+		if !ok {
+			t.Errorf("%q not found in layout", tt.r)
+			continue
+		}
+		if key.code != tt.code || key.shift != tt.shift {
+			t.Errorf("%q = {%d, %v}, want {%d, %v}", tt.r, key.code, key.shift, tt.code, tt.shift)
+		}
+	}
+
+	// 第三层（AltGr）和其他组的字符无法输入
+	for _, r := range []rune{'¹', 'й', 'z'} {
+		if _, ok := layout[runeKeysym(r)]; ok {
+			t.Errorf("Expected %q to be unavailable", r)
+		}
+	}
+
+	if _, err := parseXKBKeymap("xkb_keymap { };"); err == nil {
+		t.Error("Expected error for keymap without sections")
+	}
+}
+
+func TestUinputUsesLayout(t *testing.T) {
+	layout, err := parseXKBKeymap(testKeymap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &uinputInjector{layout: layout}
+	if code, shift, ok := u.runeKey('a'); !ok || code != 16 || shift {
+		t.Errorf("runeKey('a') = %d, %v, %v", code, shift, ok)
+	}
+	if _, _, ok := u.runeKey('z'); ok {
+		t.Error("Expected character missing from the layout to be unsupported")
+	}
+
+	// 没有布局时按美式键盘
+	us := &uinputInjector{}
+	if code, _, ok := us.runeKey('a'); !ok || code != 30 {
+		t.Errorf("US runeKey('a') = %d, %v", code, ok)
+	}
+}
+
+func TestKeysymFromName(t *testing.T) {
+	tests := map[string]uint32{
+		"a":           'a',
+		"Z":           'Z',
+		"7":           '7',
+		"bracketleft": '[',
+		"Tab":         0xff09,
+		"0x1000439":   0x1000439,
+		"U0439":       0x1000439,
+	}
+	for name, want := range tests {
+		if got, ok := keysymFromName(name); !ok || got != want {
+			t.Errorf("keysymFromName(%q) = %#x, %v, want %#x", name, got, ok, want)
+		}
+	}
+	for _, name := range []string{"Shift_L", "NoSymbol", "eacute"} {
+		if _, ok := keysymFromName(name); ok {
+			t.Errorf("Expected %q to be unknown", name)
+		}
+	}
+}
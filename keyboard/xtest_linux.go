@@ -0,0 +1,298 @@
+//go:build linux
+
+package keyboard
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+)
+
+// remapDelay 临时修改键盘映射后等待其他程序处理 MappingNotify 的时间
+const remapDelay = 20 * time.Millisecond
+
+// xkShiftL Shift_L 的 keysym
+const xkShiftL = 0xffe1
+
+// xtestInjector 通过 XTest 扩展注入按键
+type xtestInjector struct {
+	mu   sync.Mutex
+	conn *xgb.Conn
+	root xproto.Window
+}
+
+// keyMapping 键盘映射的快照
+type keyMapping struct {
+	min  xproto.Keycode
+	per  int
+	syms []xproto.Keysym
+}
+
+// stroke 一次按键：键码及是否需要同时按住 Shift
+type stroke struct {
+	code  xproto.Keycode
+	shift bool
+}
+
+// newXTestInjector 连接 X 服务器并初始化 XTest 扩展
+func newXTestInjector(display string) (*xtestInjector, error) {
+	if display == "" {
+		return nil, fmt.Errorf("no X11 display")
+	}
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to X display: %v", err)
+	}
+	if err := xtest.Init(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("XTest extension unavailable: %v", err)
+	}
+	return &xtestInjector{
+		conn: conn,
+		root: xproto.Setup(conn).DefaultScreen(conn).Root,
+	}, nil
+}
+
+// sendChord 依次按下 keys 中的按键，再按相反顺序释放
+func (x *xtestInjector) sendChord(keys []linuxKey) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	mapping, err := x.keyboardMapping()
+	if err != nil {
+		return err
+	}
+	spares := mapping.spareKeycodes()
+
+	codes := make([]xproto.Keycode, len(keys))
+	var remapped []xproto.Keycode
+	defer func() { x.restoreMapping(remapped) }()
+
+	for i, key := range keys {
+		code, _, found := mapping.find(xproto.Keysym(key.keysym))
+		if !found {
+			// 当前键盘布局中没有这个 keysym，临时映射到一个空闲键码上
+			if len(spares) == 0 {
+				return fmt.Errorf("no spare keycode for temporary mapping")
+			}
+			code, spares = spares[0], spares[1:]
+			if err := x.setMapping(code, mapping.per, xproto.Keysym(key.keysym)); err != nil {
+				return err
+			}
+			remapped = append(remapped, code)
+		}
+		codes[i] = code
+	}
+	if len(remapped) > 0 {
+		time.Sleep(remapDelay)
+	}
+
+	for _, code := range codes {
+		x.fake(xproto.KeyPress, code)
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		x.fake(xproto.KeyRelease, codes[i])
+	}
+	return x.sync()
+}
+
+// typeText 逐字符输入文本。布局中存在的字符直接按键（必要时加 Shift），
+// 其他字符临时映射到空闲键码；空闲键码不够时分段输入。
+func (x *xtestInjector) typeText(text string, delay time.Duration) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	runes := []rune(normalizeNewlines(text))
+	for len(runes) > 0 {
+		n, err := x.typeChunk(runes, delay)
+		if err != nil {
+			return err
+		}
+		runes = runes[n:]
+	}
+	return nil
+}
+
+// typeChunk 输入 runes 开头尽可能多的字符，返回已输入的字符数
+func (x *xtestInjector) typeChunk(runes []rune, delay time.Duration) (int, error) {
+	mapping, err := x.keyboardMapping()
+	if err != nil {
+		return 0, err
+	}
+	shift, _, found := mapping.find(xkShiftL)
+	if !found {
+		return 0, fmt.Errorf("no keycode for Shift")
+	}
+	spares := mapping.spareKeycodes()
+
+	var strokes []stroke
+	var remapped []xproto.Keycode
+	defer func() { x.restoreMapping(remapped) }()
+
+	assigned := make(map[xproto.Keysym]xproto.Keycode)
+	for _, r := range runes {
+		sym := xproto.Keysym(runeKeysym(r))
+		if code, column, found := mapping.find(sym); found && column <= 1 {
+			strokes = append(strokes, stroke{code, column == 1})
+			continue
+		}
+		if code, ok := assigned[sym]; ok {
+			strokes = append(strokes, stroke{code, false})
+			continue
+		}
+		if len(spares) == 0 {
+			break
+		}
+		code := spares[0]
+		spares = spares[1:]
+		if err := x.setMapping(code, mapping.per, sym); err != nil {
+			return 0, err
+		}
+		remapped = append(remapped, code)
+		assigned[sym] = code
+		strokes = append(strokes, stroke{code, false})
+	}
+	if len(strokes) == 0 {
+		return 0, fmt.Errorf("no spare keycode for temporary mapping")
+	}
+	if len(remapped) > 0 {
+		time.Sleep(remapDelay)
+	}
+
+	for _, s := range strokes {
+		if s.shift {
+			x.fake(xproto.KeyPress, shift)
+		}
+		x.fake(xproto.KeyPress, s.code)
+		x.fake(xproto.KeyRelease, s.code)
+		if s.shift {
+			x.fake(xproto.KeyRelease, shift)
+		}
+		if err := x.sync(); err != nil {
+			return 0, err
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+	return len(strokes), nil
+}
+
+// isPressed 通过 QueryKeymap 检查按键状态
+func (x *xtestInjector) isPressed(key linuxKey) (bool, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	code, found, err := x.keycodeFor(xproto.Keysym(key.keysym))
+	if err != nil || !found {
+		return false, err
+	}
+	reply, err := xproto.QueryKeymap(x.conn).Reply()
+	if err != nil {
+		return false, err
+	}
+	return reply.Keys[code/8]&(1<<(code%8)) != 0, nil
+}
+
+func (x *xtestInjector) close() {
+	x.conn.Close()
+}
+
+// fake 注入一个按键事件
+func (x *xtestInjector) fake(eventType byte, code xproto.Keycode) {
+	xtest.FakeInput(x.conn, eventType, byte(code), 0, x.root, 0, 0, 0)
+}
+
+// sync 通过一次往返确保按键已被服务器处理
+func (x *xtestInjector) sync() error {
+	if _, err := xproto.GetInputFocus(x.conn).Reply(); err != nil {
+		return fmt.Errorf("failed to send key events: %v", err)
+	}
+	return nil
+}
+
+// keyboardMapping 读取完整的键盘映射
+func (x *xtestInjector) keyboardMapping() (keyMapping, error) {
+	setup := xproto.Setup(x.conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	reply, err := xproto.GetKeyboardMapping(x.conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return keyMapping{}, fmt.Errorf("failed to get keyboard mapping: %v", err)
+	}
+	return keyMapping{setup.MinKeycode, int(reply.KeysymsPerKeycode), reply.Keysyms}, nil
+}
+
+// keycodeFor 在当前键盘映射中查找产生 keysym 的键码
+func (x *xtestInjector) keycodeFor(keysym xproto.Keysym) (xproto.Keycode, bool, error) {
+	mapping, err := x.keyboardMapping()
+	if err != nil {
+		return 0, false, err
+	}
+	code, _, found := mapping.find(keysym)
+	return code, found, nil
+}
+
+// setMapping 把键码映射为 keysym（大小写位置相同），keysym 为 0 时清除映射
+func (x *xtestInjector) setMapping(code xproto.Keycode, per int, keysym xproto.Keysym) error {
+	syms := make([]xproto.Keysym, per)
+	syms[0] = keysym
+	if per > 1 {
+		syms[1] = keysym
+	}
+	err := xproto.ChangeKeyboardMappingChecked(x.conn, 1, code, byte(per), syms).Check()
+	if err != nil {
+		return fmt.Errorf("failed to change keyboard mapping: %v", err)
+	}
+	return nil
+}
+
+// restoreMapping 等目标程序按临时映射处理完按键后清除临时映射
+func (x *xtestInjector) restoreMapping(codes []xproto.Keycode) {
+	if len(codes) == 0 {
+		return
+	}
+	time.Sleep(remapDelay)
+	mapping, err := x.keyboardMapping()
+	if err != nil {
+		return
+	}
+	for _, code := range codes {
+		x.setMapping(code, mapping.per, 0)
+	}
+}
+
+// find 查找产生 keysym 的键码，优先选择列号最小（不需要 Shift 或切换布局组）的位置，
+// 因此无论当前使用哪种键盘布局都能找到正确的物理键
+func (m keyMapping) find(keysym xproto.Keysym) (xproto.Keycode, int, bool) {
+	count := len(m.syms) / m.per
+	for column := 0; column < m.per; column++ {
+		for i := 0; i < count; i++ {
+			if m.syms[i*m.per+column] == keysym {
+				return m.min + xproto.Keycode(i), column, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// spareKeycodes 返回没有映射任何 keysym 的键码，从大到小排列
+func (m keyMapping) spareKeycodes() []xproto.Keycode {
+	var spares []xproto.Keycode
+	for i := len(m.syms)/m.per - 1; i >= 0; i-- {
+		empty := true
+		for _, sym := range m.syms[i*m.per : (i+1)*m.per] {
+			if sym != 0 {
+				empty = false
+				break
+			}
+		}
+		if empty {
+			spares = append(spares, m.min+xproto.Keycode(i))
+		}
+	}
+	return spares
+}
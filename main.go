@@ -4,12 +4,15 @@ import (
 	"clipboard-monitor/clipboard"
 	"clipboard-monitor/hotkey"
 	"clipboard-monitor/keyboard"
+	"clipboard-monitor/settings"
+	"clipboard-monitor/storage"
 	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	webview "github.com/webview/webview_go"
@@ -22,18 +25,154 @@ type ClipboardApp struct {
 	cancel       context.CancelFunc
 	hidden       bool // 窗口是否隐藏
 	hotkeyMgr    *hotkey.HotkeyManager
-	globalHotkey bool // 全局热键是否启用
+	globalHotkey bool                    // 全局热键是否启用
+	hotkeys      map[string]hotkey.Spec  // 动作名 -> 热键组合
+	hotkeyIDs    map[string]int          // 已注册的动作 -> 热键绑定 ID
+	events       *clipboard.Subscription // 历史记录事件订阅
+	dataDir      string
+	store        *storage.FileStore
+	locked       bool // 加密历史记录是否处于锁定状态
+	configDir    string
+	settings     settings.Settings
 }
 
 func NewClipboardApp() *ClipboardApp {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &ClipboardApp{
-		monitor:   clipboard.NewMonitor(50), // Save last 50 records
+	ca := &ClipboardApp{
 		ctx:       ctx,
 		cancel:    cancel,
 		hotkeyMgr: hotkey.NewHotkeyManager(),
+		hotkeyIDs: make(map[string]int),
 	}
+	ca.loadSettings()
+
+	opts := []clipboard.Option{
+		clipboard.WithBackend(clipboard.DefaultBackend()),
+		clipboard.WithPollInterval(ca.settings.PollInterval()),
+		clipboard.WithRetention(retentionPolicy(ca.settings)),
+	}
+	dir, err := storage.DefaultDir()
+	if err != nil {
+		log.Printf("无法确定数据目录，历史记录仅保存在内存中: %v", err)
+	} else if storage.EncryptionEnabled(dir) {
+		// 加密的历史记录需要用户输入口令后才能加载
+		ca.dataDir = dir
+		ca.locked = true
+	} else if store, err := storage.Open(dir); err != nil {
+		log.Printf("无法打开历史记录存储，仅保存在内存中: %v", err)
+	} else {
+		ca.dataDir = dir
+		ca.store = store
+		opts = append(opts, clipboard.WithStore(store))
+	}
+
+	filter, err := sensitiveFilter(ca.settings)
+	if err != nil {
+		log.Printf("敏感内容设置无效，使用默认策略: %v", err)
+		filter = clipboard.NewSensitiveFilter(nil, ca.settings.SensitiveTTL())
+	}
+	opts = append(opts, clipboard.WithSensitiveFilter(filter))
+	rules, err := appRules(ca.settings)
+	if err != nil {
+		log.Printf("排除规则无效，不按来源应用程序排除: %v", err)
+	}
+	opts = append(opts, clipboard.WithAppRules(rules...))
+
+	ca.monitor = clipboard.NewMonitor(ca.settings.MaxHistory, opts...)
+	return ca
+}
+
+// unlockHistory 使用口令解锁加密的历史记录并挂载到监听器
+func (ca *ClipboardApp) unlockHistory(passphrase string) error {
+	if !ca.locked {
+		return nil
+	}
+
+	cipher, err := storage.UnlockEncryption(ca.dataDir, passphrase)
+	if err != nil {
+		return err
+	}
+	store, err := storage.Open(ca.dataDir, storage.WithCipher(cipher))
+	if err != nil {
+		return err
+	}
+	if err := ca.monitor.AttachStore(store); err != nil {
+		store.Close()
+		return err
+	}
+
+	ca.store = store
+	ca.locked = false
+	return nil
+}
+
+// lockHistory 锁定加密的历史记录，卸载存储并清空内存中的条目
+func (ca *ClipboardApp) lockHistory() error {
+	if ca.locked {
+		return nil
+	}
+	if ca.dataDir == "" || !storage.EncryptionEnabled(ca.dataDir) {
+		return fmt.Errorf("未启用历史记录加密")
+	}
+
+	ca.monitor.DetachStore()
+	if ca.store != nil {
+		if err := ca.store.Close(); err != nil {
+			log.Printf("关闭历史记录存储失败: %v", err)
+		}
+		ca.store = nil
+	}
+	ca.locked = true
+	return nil
+}
+
+// enableEncryption 设置口令并将现有历史记录改为加密存储
+func (ca *ClipboardApp) enableEncryption(passphrase string) error {
+	if ca.dataDir == "" {
+		return fmt.Errorf("历史记录存储不可用")
+	}
+
+	cipher, err := storage.SetupEncryption(ca.dataDir, passphrase, storage.DefaultKDFParams)
+	if err != nil {
+		return err
+	}
+
+	// 新的存储打开时会用密文重写数据目录，此期间监听器不会写入旧的存储
+	var store *storage.FileStore
+	_, err = ca.monitor.SwapStore(func() (clipboard.Store, error) {
+		if ca.store != nil {
+			// 先合并旧存储的日志，之后关闭时不会再用明文覆盖快照
+			if err := ca.store.Compact(); err != nil {
+				return nil, err
+			}
+		}
+		s, err := storage.Open(ca.dataDir, storage.WithCipher(cipher))
+		if err != nil {
+			return nil, err
+		}
+		store = s
+		return s, nil
+	})
+	if err != nil {
+		if store != nil {
+			// 数据已经用密文重写，口令文件必须保留
+			store.Close()
+			return err
+		}
+		if rerr := storage.DiscardEncryption(ca.dataDir); rerr != nil {
+			log.Printf("删除口令文件失败: %v", rerr)
+		}
+		return err
+	}
+
+	if ca.store != nil {
+		if err := ca.store.Close(); err != nil {
+			log.Printf("关闭历史记录存储失败: %v", err)
+		}
+	}
+	ca.store = store
+	return nil
 }
 
 func (ca *ClipboardApp) setupUI() error {
@@ -97,99 +236,386 @@ func (ca *ClipboardApp) loadHTMLFile() error {
 func (ca *ClipboardApp) bindFunctions() {
 	// 绑定获取历史记录函数
 	ca.w.Bind("getHistory", func() interface{} {
-		history := ca.monitor.GetHistory()
+		if ca.locked {
+			return map[string]bool{"locked": true}
+		}
+		// 大条目只返回预览，完整内容通过 getEntryContent 获取
+		return briefEntries(ca.monitor.GetHistory())
+	})
 
-		// 确保返回的是数组，即使为空
-		if history == nil {
+	// 获取条目的完整内容和全部格式数据
+	ca.w.Bind("getEntryContent", func(id string) interface{} {
+		if ca.locked {
+			return map[string]bool{"locked": true}
+		}
+		entry, ok := ca.monitor.Entry(id)
+		if !ok {
+			return map[string]string{"error": entryError(clipboard.ErrEntryNotFound)}
+		}
+		return entry
+	})
+
+	// 绑定历史记录搜索函数，limit 可省略
+	ca.w.Bind("searchHistory", func(query string, limit ...int) interface{} {
+		if ca.locked {
+			return map[string]bool{"locked": true}
+		}
+		n := 0
+		if len(limit) > 0 {
+			n = limit[0]
+		}
+		results := ca.monitor.Search(query, n)
+		if results == nil {
 			return []interface{}{}
 		}
+		for i := range results {
+			results[i] = results[i].Brief()
+		}
+		return results
+	})
 
-		return history
+	// 绑定快速选择器的模糊匹配函数，limit 可省略
+	ca.w.Bind("quickSearch", func(pattern string, limit ...int) interface{} {
+		if ca.locked {
+			return map[string]bool{"locked": true}
+		}
+		n := 0
+		if len(limit) > 0 {
+			n = limit[0]
+		}
+		matches := ca.monitor.FuzzySearch(pattern, n)
+		for i := range matches {
+			matches[i] = matches[i].Brief()
+		}
+		return matches
 	})
 
-	// 绑定复制到剪贴板函数
-	ca.w.Bind("copyToClipboardGo", func(content string) interface{} {
-		err := ca.monitor.CopyToClipboard(content)
-		if err != nil {
+	// 绑定历史记录加密相关函数
+	ca.w.Bind("getLockState", func() interface{} {
+		return map[string]bool{
+			"encrypted": ca.dataDir != "" && storage.EncryptionEnabled(ca.dataDir),
+			"locked":    ca.locked,
+		}
+	})
+
+	ca.w.Bind("unlockHistory", func(passphrase string) interface{} {
+		if err := ca.unlockHistory(passphrase); err != nil {
+			if err == storage.ErrWrongPassphrase {
+				return map[string]string{"error": "口令错误"}
+			}
+			log.Printf("解锁历史记录失败: %v", err)
+			return map[string]string{"error": "解锁失败: " + err.Error()}
+		}
+		return map[string]bool{"success": true}
+	})
+
+	ca.w.Bind("lockHistory", func() interface{} {
+		if err := ca.lockHistory(); err != nil {
 			return map[string]string{"error": err.Error()}
 		}
 		return map[string]bool{"success": true}
 	})
 
+	ca.w.Bind("enableEncryption", func(passphrase string) interface{} {
+		if err := ca.enableEncryption(passphrase); err != nil {
+			log.Printf("启用历史记录加密失败: %v", err)
+			return map[string]string{"error": "启用加密失败: " + err.Error()}
+		}
+		return map[string]bool{"success": true}
+	})
+
+	// 绑定复制到剪贴板函数，条目按 ID 指定
+	ca.w.Bind("copyToClipboardGo", func(id string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		err := ca.monitor.CopyEntry(id)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return map[string]bool{"success": true}
+	})
+
 	// 绑定清空历史函数
 	ca.w.Bind("clearHistory", func() interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
 		ca.monitor.ClearHistory()
 		return map[string]bool{"success": true}
 	})
 
+	// 获取暂停状态
+	ca.w.Bind("getPauseState", func() interface{} {
+		return ca.monitor.PauseState()
+	})
+
+	// 暂停记录，minutes > 0 时开启隐身模式，到期后自动恢复
+	ca.w.Bind("pauseMonitoring", func(minutes int) interface{} {
+		if minutes < 0 || minutes > settings.MaxIncognitoMin {
+			return map[string]string{"error": fmt.Sprintf("暂停时长必须在 0 到 %d 分钟之间", settings.MaxIncognitoMin)}
+		}
+		ca.monitor.PauseFor(time.Duration(minutes) * time.Minute)
+		return ca.monitor.PauseState()
+	})
+
+	// 恢复记录
+	ca.w.Bind("resumeMonitoring", func() interface{} {
+		ca.monitor.Resume()
+		return ca.monitor.PauseState()
+	})
+
 	// 绑定获取版本信息函数
 	ca.w.Bind("getVersionInfo", func() interface{} {
 		return GetVersionInfo()
 	})
 
 	// 绑定删除单个历史记录项函数
-	ca.w.Bind("deleteHistoryItemGo", func(index int) interface{} {
-		err := ca.monitor.DeleteHistoryItem(index)
+	ca.w.Bind("deleteHistoryItemGo", func(id string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		err := ca.monitor.DeleteEntry(id)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return map[string]bool{"success": true}
+	})
+
+	// 绑定固定条目相关函数，固定的条目排在最前面，不会被淘汰或清空
+	ca.w.Bind("pinEntryGo", func(id string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		entry, err := ca.monitor.Pin(id)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return entry.Brief()
+	})
+
+	ca.w.Bind("unpinEntryGo", func(id string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		entry, err := ca.monitor.Unpin(id)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return entry.Brief()
+	})
+
+	// 调整固定条目的顺序，index 为在固定条目中的目标位置（从 0 开始）
+	ca.w.Bind("movePinnedGo", func(id string, index int) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		entry, err := ca.monitor.MovePinned(id, index)
 		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return entry.Brief()
+	})
+
+	// 绑定标签（集合）相关函数，带有标签的条目不会被自动淘汰
+	ca.w.Bind("getTags", func() interface{} {
+		if ca.locked {
+			return map[string]bool{"locked": true}
+		}
+		return ca.monitor.Tags()
+	})
+
+	ca.w.Bind("getEntriesByTag", func(tag string) interface{} {
+		if ca.locked {
+			return map[string]bool{"locked": true}
+		}
+		return briefEntries(ca.monitor.EntriesByTag(tag))
+	})
+
+	ca.w.Bind("tagEntryGo", func(id string, tags ...string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		entry, err := ca.monitor.TagEntry(id, tags...)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return entry.Brief()
+	})
+
+	ca.w.Bind("untagEntryGo", func(id string, tags ...string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		entry, err := ca.monitor.UntagEntry(id, tags...)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return entry.Brief()
+	})
+
+	// 重命名标签，新名称已存在时两个标签合并
+	ca.w.Bind("renameTagGo", func(from string, to string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		n, err := ca.monitor.RenameTag(from, to)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return map[string]interface{}{"success": true, "count": n}
+	})
+
+	ca.w.Bind("mergeTagsGo", func(target string, sources ...string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		n, err := ca.monitor.MergeTags(target, sources...)
+		if err != nil {
+			return map[string]string{"error": entryError(err)}
+		}
+		return map[string]interface{}{"success": true, "count": n}
+	})
+
+	// 绑定设置函数
+	ca.w.Bind("getSettings", func() interface{} {
+		return ca.settings
+	})
+
+	ca.w.Bind("updateSettings", func(s settings.Settings) interface{} {
+		if err := ca.updateSettings(s); err != nil {
+			log.Printf("更新设置失败: %v", err)
 			return map[string]string{"error": err.Error()}
 		}
 		return map[string]bool{"success": true}
 	})
 
+	// 将应用程序加入排除列表，之后不再记录它复制的内容
+	// 规则放在最前面，覆盖已有的针对同一程序的规则和通配符规则
+	ca.w.Bind("excludeAppGo", func(app string) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
+		}
+		s := ca.settings.Clone()
+		exclusions := []string{app}
+		for _, name := range s.Exclusions {
+			if !strings.EqualFold(strings.TrimPrefix(name, "+"), app) {
+				exclusions = append(exclusions, name)
+			}
+		}
+		s.Exclusions = exclusions
+		if err := ca.updateSettings(s); err != nil {
+			log.Printf("添加排除规则失败: %v", err)
+			return map[string]string{"error": err.Error()}
+		}
+		return map[string]bool{"success": true}
+	})
+
+	// 列出敏感内容检测器及当前策略
+	ca.w.Bind("getSensitiveDetectors", func() interface{} {
+		defaults := clipboard.DefaultPolicies()
+		detectors := make([]map[string]string, 0, len(sensitiveDetectors))
+		for _, detector := range sensitiveDetectors {
+			policy, ok := ca.settings.SensitivePolicies[detector.Name]
+			if !ok {
+				policy = string(defaults[detector.Name])
+			}
+			detectors = append(detectors, map[string]string{
+				"name":   detector.Name,
+				"label":  detector.Label,
+				"policy": policy,
+			})
+		}
+		return detectors
+	})
+
 	// 绑定快捷键设置函数
 	ca.w.Bind("saveHotkeySettings", func(config map[string]interface{}) interface{} {
 		log.Printf("收到快捷键配置保存请求: %+v", config)
-		// 这里可以保存快捷键配置到文件或注册表
-		// 暂时返回成功，实际实现可以根据需要添加
-		result := map[string]bool{"success": true}
-		log.Printf("返回保存结果: %+v", result)
-		return result
+
+		s := ca.settings.Clone()
+		if text, _ := config["hotkey"].(string); text != "" {
+			if _, err := hotkey.ParseSpec(text); err != nil {
+				return map[string]string{"error": "快捷键无效: " + err.Error()}
+			}
+			s.Hotkeys[actionShowQuickSelector] = text
+		}
+		s.HotkeysEnabled, _ = config["enabled"].(bool)
+		s.MinimizeToTray, _ = config["minimizeToTray"].(bool)
+
+		if err := ca.updateSettings(s); err != nil {
+			log.Printf("保存快捷键配置失败: %v", err)
+			return map[string]string{"error": err.Error()}
+		}
+		return map[string]bool{"success": true}
 	})
 
 	ca.w.Bind("getHotkeySettings", func() interface{} {
-		log.Printf("收到获取快捷键配置请求")
-		// 这里可以从文件或注册表读取快捷键配置
-		// 暂时返回默认配置
-		result := map[string]interface{}{
-			"hotkey":  "Ctrl+Shift+V",
-			"enabled": false,
+		return map[string]interface{}{
+			"hotkey":         ca.settings.Hotkeys[actionShowQuickSelector],
+			"enabled":        ca.globalHotkey,
+			"minimizeToTray": ca.settings.MinimizeToTray,
 		}
-		log.Printf("返回快捷键配置: %+v", result)
-		return result
 	})
 
-	ca.w.Bind("setGlobalHotkeyEnabled", func(enabled bool) interface{} {
-		log.Printf("收到设置全局快捷键状态请求: %v", enabled)
+	// 校验并规范化快捷键字符串，供界面在捕获按键时即时提示
+	ca.w.Bind("validateHotkey", func(text string) interface{} {
+		spec, err := hotkey.ParseSpec(text)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		return map[string]string{"hotkey": spec.String()}
+	})
 
-		if enabled && !ca.globalHotkey {
-			// 启用全局热键
-			err := ca.hotkeyMgr.RegisterHotkey(func() {
-				log.Printf("全局热键被触发，显示快速选择界面")
-				// 显示快速选择界面
-				ca.w.Eval(`
-					if (typeof showQuickSelector === 'function') {
-						showQuickSelector();
-					}
-				`)
-			})
-			if err != nil {
-				log.Printf("注册全局热键失败: %v", err)
-				return map[string]string{"error": "注册全局热键失败: " + err.Error()}
+	// 列出可以绑定全局热键的动作及当前绑定
+	ca.w.Bind("getHotkeyActions", func() interface{} {
+		actions := make([]map[string]interface{}, 0, len(ca.hotkeyActions()))
+		for _, action := range ca.hotkeyActions() {
+			text := ""
+			if spec, ok := ca.hotkeys[action.Name]; ok {
+				text = spec.String()
 			}
-			ca.globalHotkey = true
-		} else if !enabled && ca.globalHotkey {
-			// 禁用全局热键
-			err := ca.hotkeyMgr.UnregisterHotkey()
-			if err != nil {
-				log.Printf("注销全局热键失败: %v", err)
-				return map[string]string{"error": "注销全局热键失败: " + err.Error()}
+			_, registered := ca.hotkeyIDs[action.Name]
+			actions = append(actions, map[string]interface{}{
+				"name":       action.Name,
+				"label":      action.Label,
+				"hotkey":     text,
+				"registered": registered,
+			})
+		}
+		return actions
+	})
+
+	// 为动作绑定热键，热键为空时解除绑定
+	ca.w.Bind("bindHotkeyAction", func(name string, text string) interface{} {
+		if _, ok := ca.findHotkeyAction(name); !ok {
+			return map[string]string{"error": "未知动作: " + name}
+		}
+		s := ca.settings.Clone()
+		if text == "" {
+			delete(s.Hotkeys, name)
+		} else {
+			if _, err := hotkey.ParseSpec(text); err != nil {
+				return map[string]string{"error": "快捷键无效: " + err.Error()}
 			}
-			ca.globalHotkey = false
+			s.Hotkeys[name] = text
+		}
+		if err := ca.updateSettings(s); err != nil {
+			return map[string]string{"error": err.Error()}
 		}
+		return map[string]bool{"success": true}
+	})
+
+	ca.w.Bind("setGlobalHotkeyEnabled", func(enabled bool) interface{} {
+		log.Printf("收到设置全局快捷键状态请求: %v", enabled)
 
-		result := map[string]bool{"success": true}
-		log.Printf("返回设置结果: %+v", result)
-		return result
+		s := ca.settings.Clone()
+		s.HotkeysEnabled = enabled
+		if err := ca.updateSettings(s); err != nil {
+			log.Printf("设置全局热键失败: %v", err)
+			return map[string]string{"error": err.Error()}
+		}
+		return map[string]bool{"success": true}
 	})
 
 	// 绑定窗口控制函数
@@ -228,76 +654,67 @@ func (ca *ClipboardApp) bindFunctions() {
 
 	ca.w.Bind("setMinimizeToTrayEnabled", func(enabled bool) interface{} {
 		log.Printf("设置最小化到托盘: %v", enabled)
-		// 这里可以设置托盘相关的配置
+		s := ca.settings.Clone()
+		s.MinimizeToTray = enabled
+		if err := ca.updateSettings(s); err != nil {
+			return map[string]string{"error": err.Error()}
+		}
 		return map[string]bool{"success": true}
 	})
 
 	// 绑定直接粘贴功能
-	ca.w.Bind("pasteContentGo", func(content string) interface{} {
-		contentPreview := content
-		if len(content) > 50 {
-			contentPreview = content[:50] + "..."
+	ca.w.Bind("pasteContentGo", func(id string, options ...pasteOptions) interface{} {
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
 		}
-		log.Printf("执行直接粘贴: %s", contentPreview)
-
-		// 先复制到剪贴板
-		err := ca.monitor.CopyToClipboard(content)
-		if err != nil {
-			return map[string]string{"error": "复制到剪贴板失败: " + err.Error()}
+		entry, ok := ca.monitor.Entry(id)
+		if !ok {
+			return map[string]string{"error": entryError(clipboard.ErrEntryNotFound)}
 		}
+		log.Printf("执行直接粘贴: 条目 %s", id)
 
-		// 等待一小段时间确保剪贴板更新
-		time.Sleep(50 * time.Millisecond)
-
-		// 模拟 Ctrl+V 按键
-		go func() {
-			// 稍微延迟以确保窗口切换完成
-			time.Sleep(100 * time.Millisecond)
-
-			// 发送 Ctrl+V 组合键
-			err := keyboard.SendCtrlV()
-			if err != nil {
-				log.Printf("发送Ctrl+V失败: %v", err)
-			} else {
-				log.Printf("已发送Ctrl+V组合键")
-			}
-		}()
-
+		if err := ca.pasteEntry(entry, ca.typeMode(options)); err != nil {
+			return map[string]string{"error": err.Error()}
+		}
 		return map[string]bool{"success": true}
 	})
 
 	// 绑定快速粘贴功能（全局热键触发）
-	ca.w.Bind("quickPaste", func(index int) interface{} {
-		log.Printf("快速粘贴第 %d 项", index)
+	ca.w.Bind("quickPaste", func(id string, options ...pasteOptions) interface{} {
+		log.Printf("快速粘贴条目 %s", id)
 
-		history := ca.monitor.GetHistory()
-		if index < 0 || index >= len(history) {
-			return map[string]string{"error": "索引超出范围"}
+		if ca.locked {
+			return map[string]string{"error": lockedMessage}
 		}
-
-		content := history[index].Content
-
-		// 复制到剪贴板
-		err := ca.monitor.CopyToClipboard(content)
-		if err != nil {
-			return map[string]string{"error": "复制失败: " + err.Error()}
+		entry, ok := ca.monitor.Entry(id)
+		if !ok {
+			return map[string]string{"error": entryError(clipboard.ErrEntryNotFound)}
 		}
 
-		// 发送Ctrl+V
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			err := keyboard.SendCtrlV()
-			if err != nil {
-				log.Printf("发送Ctrl+V失败: %v", err)
-			} else {
-				log.Printf("已快速粘贴内容")
-			}
-		}()
+		if err := ca.pasteEntry(entry, ca.typeMode(options)); err != nil {
+			return map[string]string{"error": err.Error()}
+		}
 
 		return map[string]bool{"success": true}
 	})
 }
 
+// lockedMessage 历史记录锁定时按 ID 操作条目返回的错误信息
+const lockedMessage = "历史记录已锁定"
+
+// entryError 返回按 ID 操作条目失败时给前端的错误信息
+func entryError(err error) string {
+	switch err {
+	case clipboard.ErrEntryNotFound:
+		return "条目不存在，可能已被删除"
+	case clipboard.ErrNotPinned:
+		return "条目未固定"
+	case clipboard.ErrInvalidTag:
+		return "标签名不能为空"
+	}
+	return err.Error()
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -306,20 +723,9 @@ func min(a, b int) int {
 }
 
 func (ca *ClipboardApp) startMonitoring() {
-	// Set new content callback
-	ca.monitor.SetOnNewContent(func(entry clipboard.ClipboardEntry) {
-		// 通知前端更新
-		if ca.w != nil {
-			// 可以通过 JavaScript 更新状态
-			ca.w.Eval(fmt.Sprintf(`
-				if (typeof updateStatus === 'function') {
-					updateStatus('新内容检测到: %s');
-				}
-			`, entry.Timestamp.Format("15:04:05")))
-		}
-	})
+	// 把历史记录变更推送给前端
+	ca.startEventBridge()
 
-	// Start monitoring in background
 	go func() {
 		err := ca.monitor.Start(ca.ctx)
 		if err != nil && err != context.Canceled {
@@ -328,6 +734,60 @@ func (ca *ClipboardApp) startMonitoring() {
 	}()
 }
 
+// pasteOptions 粘贴选项，未指定的字段使用设置中的值
+type pasteOptions struct {
+	Type *bool `json:"type"` // 逐字符模拟输入而不是发送 Ctrl+V
+}
+
+// typeMode 判断本次粘贴是否使用模拟输入
+func (ca *ClipboardApp) typeMode(options []pasteOptions) bool {
+	for _, opt := range options {
+		if opt.Type != nil {
+			return *opt.Type
+		}
+	}
+	return ca.settings.TypeInsteadOfPaste
+}
+
+// pasteEntry 将条目粘贴到当前窗口：默认复制到剪贴板后模拟 Ctrl+V，
+// typeText 为 true 时逐字符模拟输入文本，适用于禁止粘贴的远程桌面、密码框等
+func (ca *ClipboardApp) pasteEntry(entry clipboard.ClipboardEntry, typeText bool) error {
+	if typeText && entry.Content != "" {
+		delay := ca.settings.TypeDelay()
+		go func() {
+			// 稍微延迟以确保窗口切换完成
+			time.Sleep(100 * time.Millisecond)
+			if err := keyboard.TypeText(entry.Content, delay); err != nil {
+				log.Printf("模拟输入失败: %v", err)
+			} else {
+				log.Printf("已模拟输入 %d 个字符", len([]rune(entry.Content)))
+			}
+		}()
+		return nil
+	}
+	if typeText {
+		log.Printf("条目没有文本内容，改用剪贴板粘贴")
+	}
+
+	// 先复制到剪贴板（包含全部格式）
+	if err := ca.monitor.CopyEntryToClipboard(entry); err != nil {
+		return fmt.Errorf("复制到剪贴板失败: %v", err)
+	}
+
+	// 模拟 Ctrl+V 按键
+	go func() {
+		// 稍微延迟以确保剪贴板更新和窗口切换完成
+		time.Sleep(150 * time.Millisecond)
+		err := keyboard.SendCtrlV()
+		if err != nil {
+			log.Printf("发送Ctrl+V失败: %v", err)
+		} else {
+			log.Printf("已发送Ctrl+V组合键")
+		}
+	}()
+	return nil
+}
+
 func (ca *ClipboardApp) Run() error {
 	// 设置 UI
 	err := ca.setupUI()
@@ -338,13 +798,26 @@ func (ca *ClipboardApp) Run() error {
 	// 开始监控
 	ca.startMonitoring()
 
+	// 按设置注册全局热键
+	if ca.settings.HotkeysEnabled {
+		if err := ca.setGlobalHotkey(true); err != nil {
+			log.Printf("注册全局热键失败: %v", err)
+		}
+	}
+
 	// 运行 WebView
 	ca.w.Run()
 
 	// 清理资源
 	ca.cancel()
+	ca.stopEventBridge()
 	if ca.globalHotkey {
-		ca.hotkeyMgr.UnregisterHotkey()
+		ca.hotkeyMgr.UnregisterAll()
+	}
+	if ca.store != nil {
+		if err := ca.store.Close(); err != nil {
+			log.Printf("关闭历史记录存储失败: %v", err)
+		}
 	}
 	ca.w.Destroy()
 
@@ -0,0 +1,93 @@
+//go:build ignore
+
+// gen.go 使用 ICU 的 uconv 工具生成汉字读音表 readings.txt
+//
+// 运行方式：go run gen.go（需要安装 ICU，如 Debian 的 icu-devtools 包）
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// 收录的汉字范围：CJK 统一表意文字基本区
+const (
+	firstRune = 0x4e00
+	lastRune  = 0x9fff
+)
+
+// toneless 带声调的字母到不带声调字母的映射，ü 按输入法习惯写作 v
+var toneless = strings.NewReplacer(
+	"ā", "a", "á", "a", "ǎ", "a", "à", "a",
+	"ē", "e", "é", "e", "ě", "e", "è", "e", "ê", "e",
+	"ī", "i", "í", "i", "ǐ", "i", "ì", "i",
+	"ō", "o", "ó", "o", "ǒ", "o", "ò", "o",
+	"ū", "u", "ú", "u", "ǔ", "u", "ù", "u",
+	"ǖ", "v", "ǘ", "v", "ǚ", "v", "ǜ", "v", "ü", "v",
+	"ḿ", "m", "ń", "n", "ň", "n", "ǹ", "n",
+)
+
+func main() {
+	var input bytes.Buffer
+	for r := rune(firstRune); r <= lastRune; r++ {
+		input.WriteRune(r)
+		input.WriteByte('\n')
+	}
+
+	cmd := exec.Command("uconv", "-f", "utf-8", "-t", "utf-8", "-x", "Han-Latin")
+	cmd.Stdin = &input
+	output, err := cmd.Output()
+	if err != nil {
+		log.Fatalf("运行 uconv 失败: %v", err)
+	}
+
+	// 读音 -> 汉字
+	groups := make(map[string][]rune)
+	r := rune(firstRune)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		reading := toneless.Replace(strings.TrimSpace(scanner.Text()))
+		if isSyllable(reading) {
+			groups[reading] = append(groups[reading], r)
+		}
+		r++
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("读取 uconv 输出失败: %v", err)
+	}
+
+	readings := make([]string, 0, len(groups))
+	for reading := range groups {
+		readings = append(readings, reading)
+	}
+	sort.Strings(readings)
+
+	var out bytes.Buffer
+	out.WriteString("# 汉字读音表，由 gen.go 生成，请勿手动修改\n")
+	out.WriteString("# 每行为一个不带声调的读音及其对应的汉字\n")
+	for _, reading := range readings {
+		fmt.Fprintf(&out, "%s %s\n", reading, string(groups[reading]))
+	}
+	if err := os.WriteFile("readings.txt", out.Bytes(), 0644); err != nil {
+		log.Fatalf("写入读音表失败: %v", err)
+	}
+}
+
+// isSyllable 是否为只包含小写字母的读音，没有读音的字 uconv 会原样输出
+func isSyllable(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
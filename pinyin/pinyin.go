@@ -0,0 +1,166 @@
+// Package pinyin 提供汉字的拼音读音，用于按拼音或拼音首字母搜索中文内容
+package pinyin
+
+//go:generate go run gen.go
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// readingsData 由 gen.go 生成的读音表，每行为一个不带声调的读音及其对应的汉字
+//
+//go:embed readings.txt
+var readingsData string
+
+// polyphones 常用多音字的其他读音；读音表中每个字只有一个最常用的读音
+var polyphones = map[rune][]string{
+	'伯': {"bai"}, '便': {"pian"}, '剥': {"bao"}, '卜': {"bu"}, '参': {"shen", "cen"},
+	'藏': {"zang"}, '差': {"chai", "ci"}, '长': {"chang"}, '朝': {"zhao"}, '车': {"ju"},
+	'称': {"chen"}, '澄': {"deng"}, '乘': {"sheng"}, '匙': {"chi"}, '重': {"chong"},
+	'种': {"chong"}, '仇': {"qiu"}, '传': {"zhuan"}, '畜': {"xu"}, '大': {"dai"},
+	'单': {"shan", "chan"}, '弹': {"tan"}, '得': {"dei"}, '的': {"di"}, '地': {"di"},
+	'提': {"di"}, '调': {"tiao"}, '都': {"du"}, '度': {"duo"}, '恶': {"wu"},
+	'否': {"pi"}, '佛': {"fo"}, '咖': {"ga"}, '夹': {"ga"}, '给': {"ji"},
+	'还': {"huan"}, '行': {"hang"}, '和': {"huo", "hu"}, '吓': {"he"}, '会': {"kuai"},
+	'系': {"ji"}, '奇': {"ji"}, '降': {"xiang"}, '强': {"jiang"}, '角': {"jue"},
+	'嚼': {"jiao"}, '觉': {"jiao"}, '校': {"jiao"}, '藉': {"jie"}, '解': {"xie"},
+	'劲': {"jing"}, '圈': {"juan"}, '卡': {"qia"}, '壳': {"qiao"}, '落': {"la", "lao"},
+	'乐': {"yue"}, '了': {"liao"}, '俩': {"liang"}, '弄': {"long"}, '露': {"lou"},
+	'率': {"shuai"}, '抹': {"ma"}, '蔓': {"wan"}, '没': {"mo"}, '秘': {"bi"},
+	'模': {"mu"}, '那': {"nei"}, '哪': {"nei"}, '泊': {"bo"}, '薄': {"bo"},
+	'瀑': {"bao"}, '栖': {"xi"}, '亲': {"qing"}, '区': {"ou"}, '雀': {"qiao"},
+	'塞': {"se"}, '色': {"shai"}, '厦': {"xia"}, '什': {"shi"}, '省': {"xing"},
+	'盛': {"cheng"}, '熟': {"shou"}, '属': {"zhu"}, '数': {"shuo"}, '说': {"shui"},
+	'谁': {"shui"}, '似': {"si"}, '宿': {"xiu"}, '拓': {"tuo"}, '尾': {"yi"},
+	'削': {"xiao"}, '血': {"xie"}, '叶': {"xie"}, '殷': {"yan"}, '咽': {"ye"},
+	'钥': {"yue"}, '扎': {"za"}, '仔': {"zai"}, '曾': {"zeng"}, '综': {"zeng"},
+	'查': {"zha"}, '择': {"zhai"}, '着': {"zhao", "zhuo"}, '折': {"she"}, '爪': {"zhua"},
+}
+
+var (
+	loadOnce sync.Once
+	table    map[rune][]string
+)
+
+// load 解析读音表并合并多音字的其他读音
+func load() {
+	table = make(map[rune][]string, 21000)
+	for _, line := range strings.Split(readingsData, "\n") {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		reading, chars, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		for _, r := range chars {
+			table[r] = append(table[r], reading)
+		}
+	}
+	for r, extra := range polyphones {
+		table[r] = append(table[r], extra...)
+	}
+
+	// ü 写作 v，同时接受输入法中也常见的 u 写法，如 lv 和 lu
+	for r, readings := range table {
+		for _, reading := range readings {
+			if strings.ContainsRune(reading, 'v') {
+				table[r] = append(table[r], strings.ReplaceAll(reading, "v", "u"))
+			}
+		}
+	}
+}
+
+// Readings 返回汉字所有不带声调的小写拼音读音，最常用的在前；不是汉字或没有读音时返回 nil
+//
+// 返回的切片由所有调用者共享，不能修改。
+func Readings(r rune) []string {
+	loadOnce.Do(load)
+	return table[r]
+}
+
+// Initials 返回读音可以作为缩写的首字母，以及 zh、ch、sh 开头时的双字母声母（没有时为空）
+func Initials(reading string) (string, string) {
+	if reading == "" {
+		return "", ""
+	}
+	if len(reading) > 2 && reading[1] == 'h' && strings.IndexByte("zcs", reading[0]) >= 0 {
+		return reading[:1], reading[:2]
+	}
+	return reading[:1], ""
+}
+
+// MatchPrefix 判断 query 能否由 chars 开头的若干个汉字的拼音依次拼成，返回用到的汉字数，不能时返回 0
+//
+// 每个字可以使用完整拼音或首字母，最后一个字还可以只用拼音的开头部分，
+// 如 jtb、jiantieban、jiantb 和 jiant 都能匹配"剪贴板"的开头。query 应为小写字母。
+func MatchPrefix(query string, chars []rune) int {
+	if query == "" {
+		return 0
+	}
+	return matchFrom(query, chars, 0)
+}
+
+// matchFrom 从第 i 个字开始匹配剩余的 query，返回匹配结束时的下标，不能匹配时返回 0
+func matchFrom(query string, chars []rune, i int) int {
+	if query == "" {
+		return i
+	}
+	if i >= len(chars) {
+		return 0
+	}
+	for _, reading := range Readings(chars[i]) {
+		if strings.HasPrefix(reading, query) {
+			return i + 1
+		}
+		if strings.HasPrefix(query, reading) {
+			if end := matchFrom(query[len(reading):], chars, i+1); end > 0 {
+				return end
+			}
+		}
+		first, consonant := Initials(reading)
+		for _, initial := range [...]string{consonant, first} {
+			if initial != "" && strings.HasPrefix(query, initial) {
+				if end := matchFrom(query[len(initial):], chars, i+1); end > 0 {
+					return end
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// Consume 返回汉字 r 的拼音能匹配 query 开头的字节数，不能匹配时返回 0
+//
+// query 是某个读音的开头部分时全部匹配；否则优先匹配完整拼音，其次是首字母。
+// 用于逐字的贪心匹配，如快速选择器的模糊筛选。
+func Consume(query string, r rune) int {
+	if query == "" {
+		return 0
+	}
+	readings := Readings(r)
+	n := 0
+	for _, reading := range readings {
+		if strings.HasPrefix(reading, query) {
+			return len(query)
+		}
+		if strings.HasPrefix(query, reading) && len(reading) > n {
+			n = len(reading)
+		}
+	}
+	if n > 0 {
+		return n
+	}
+	for _, reading := range readings {
+		first, consonant := Initials(reading)
+		if consonant != "" && strings.HasPrefix(query, consonant) {
+			return len(consonant)
+		}
+		if strings.HasPrefix(query, first) {
+			n = 1
+		}
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package pinyin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadings(t *testing.T) {
+	cases := map[rune][]string{
+		'剪': {"jian"},
+		'行': {"xing", "hang"},
+		'长': {"zhang", "chang"},
+		'绿': {"lv", "lu"},
+		'a': nil,
+	}
+	for r, want := range cases {
+		if got := Readings(r); !reflect.DeepEqual(got, want) {
+			t.Errorf("Readings(%q) = %v, want %v", r, got, want)
+		}
+	}
+}
+
+func TestInitials(t *testing.T) {
+	cases := map[string][2]string{
+		"jian":  {"j", ""},
+		"zhong": {"z", "zh"},
+		"shi":   {"s", "sh"},
+		"an":    {"a", ""},
+		"":      {"", ""},
+	}
+	for reading, want := range cases {
+		if first, consonant := Initials(reading); first != want[0] || consonant != want[1] {
+			t.Errorf("Initials(%q) = %q, %q, want %q, %q", reading, first, consonant, want[0], want[1])
+		}
+	}
+}
+
+func TestMatchPrefix(t *testing.T) {
+	chars := []rune("剪贴板历史")
+	cases := map[string]int{
+		"jtb":          3,
+		"jiantieban":   3,
+		"jiantb":       3,
+		"jiant":        2,
+		"j":            1,
+		"jtbls":        5,
+		"jiantiebanls": 5,
+		"tb":           0, // 必须从第一个字开始
+		"jx":           0,
+		"":             0,
+	}
+	for query, want := range cases {
+		if got := MatchPrefix(query, chars); got != want {
+			t.Errorf("MatchPrefix(%q) = %d, want %d", query, got, want)
+		}
+	}
+
+	// 多音字的每个读音都可以匹配
+	polyphonic := []struct{ query, text string }{
+		{"yinhang", "银行"},
+		{"yh", "银行"},
+		{"xingdong", "行动"},
+		{"changdu", "长度"},
+		{"zhangda", "长大"},
+		{"lvse", "绿色"},
+		{"luse", "绿色"},
+	}
+	for _, tc := range polyphonic {
+		if got := MatchPrefix(tc.query, []rune(tc.text)); got != len([]rune(tc.text)) {
+			t.Errorf("MatchPrefix(%q, %q) = %d, want full match", tc.query, tc.text, got)
+		}
+	}
+}
+
+func TestConsume(t *testing.T) {
+	cases := []struct {
+		query string
+		r     rune
+		want  int
+	}{
+		{"jtb", '剪', 1},
+		{"jiantie", '剪', 4},
+		{"ji", '剪', 2},
+		{"zhg", '中', 2},
+		{"zg", '中', 1},
+		{"xian", '西', 2},
+		{"hang", '行', 4},
+		{"x", 'a', 0},
+	}
+	for _, tc := range cases {
+		if got := Consume(tc.query, tc.r); got != tc.want {
+			t.Errorf("Consume(%q, %q) = %d, want %d", tc.query, tc.r, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"clipboard-monitor/clipboard"
+	"clipboard-monitor/hotkey"
+	"clipboard-monitor/settings"
+)
+
+// sensitiveDetectors 敏感内容检测器在界面中的名称，顺序即显示顺序
+var sensitiveDetectors = []struct {
+	Name  string
+	Label string
+}{
+	{clipboard.DetectorPrivateKey, "私钥"},
+	{clipboard.DetectorToken, "访问令牌和 API 密钥"},
+	{clipboard.DetectorJWT, "JWT"},
+	{clipboard.DetectorCard, "银行卡号"},
+	{clipboard.DetectorPassword, "疑似密码"},
+}
+
+// loadSettings 读取配置文件，失败时使用默认设置
+func (ca *ClipboardApp) loadSettings() {
+	ca.settings = settings.Defaults()
+
+	dir, err := settings.DefaultDir()
+	if err != nil {
+		log.Printf("无法确定配置目录，设置不会被保存: %v", err)
+	} else {
+		ca.configDir = dir
+		loaded, err := settings.Load(dir)
+		if err != nil {
+			log.Printf("加载设置失败，使用默认设置: %v", err)
+		}
+		ca.settings = loaded
+	}
+
+	specs, err := ca.hotkeySpecs(ca.settings.Hotkeys)
+	if err != nil {
+		log.Printf("快捷键设置无效，使用默认快捷键: %v", err)
+		specs = map[string]hotkey.Spec{actionShowQuickSelector: hotkey.DefaultSpec}
+	}
+	ca.hotkeys = specs
+}
+
+// updateSettings 校验并保存新的设置，保存成功后才生效
+func (ca *ClipboardApp) updateSettings(s settings.Settings) error {
+	s = s.Clone()
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("设置无效: %v", err)
+	}
+	specs, err := ca.hotkeySpecs(s.Hotkeys)
+	if err != nil {
+		return err
+	}
+
+	filter, err := sensitiveFilter(s)
+	if err != nil {
+		return err
+	}
+	rules, err := appRules(s)
+	if err != nil {
+		return err
+	}
+
+	// 热键可能被其他程序占用，先注册，保存失败时恢复原来的热键；其余设置在保存成功后才应用
+	prevHotkeys, prevEnabled := ca.hotkeys, ca.globalHotkey
+	if err := ca.applyHotkeys(specs, s.HotkeysEnabled); err != nil {
+		return err
+	}
+	if ca.configDir == "" {
+		log.Printf("配置目录不可用，设置仅在本次运行中生效")
+	} else if err := settings.Save(ca.configDir, s); err != nil {
+		if restoreErr := ca.applyHotkeys(prevHotkeys, prevEnabled); restoreErr != nil {
+			log.Printf("恢复全局热键失败: %v", restoreErr)
+		}
+		return fmt.Errorf("保存设置失败: %v", err)
+	}
+
+	ca.monitor.SetRetention(retentionPolicy(s))
+	ca.monitor.SetPollInterval(s.PollInterval())
+	ca.monitor.SetSensitiveFilter(filter)
+	ca.monitor.SetAppRules(rules)
+	ca.settings = s
+	return nil
+}
+
+// sensitiveFilter 根据设置创建敏感内容过滤器，检测器名称或策略无效时返回错误
+func sensitiveFilter(s settings.Settings) (*clipboard.SensitiveFilter, error) {
+	defaults := clipboard.DefaultPolicies()
+	policies := make(map[string]clipboard.Policy, len(s.SensitivePolicies))
+	for name, text := range s.SensitivePolicies {
+		if _, ok := defaults[name]; !ok {
+			return nil, fmt.Errorf("未知的敏感内容检测器: %s", name)
+		}
+		policy, err := clipboard.ParsePolicy(text)
+		if err != nil {
+			return nil, fmt.Errorf("敏感内容策略无效 (%s): %v", name, err)
+		}
+		policies[name] = policy
+	}
+	return clipboard.NewSensitiveFilter(policies, s.SensitiveTTL()), nil
+}
+
+// appRules 将设置中的排除列表转换为来源应用程序规则，按顺序匹配，以 + 开头的行表示始终记录该应用程序
+func appRules(s settings.Settings) ([]clipboard.AppRule, error) {
+	rules := make([]clipboard.AppRule, 0, len(s.Exclusions))
+	for _, line := range s.Exclusions {
+		pattern, allow := strings.CutPrefix(line, "+")
+		rule, err := clipboard.NewAppRule(pattern, allow)
+		if err != nil {
+			return nil, fmt.Errorf("排除规则无效 (%s): %v", line, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// hotkeySpecs 解析设置中的快捷键，动作名必须在动作目录中
+func (ca *ClipboardApp) hotkeySpecs(texts map[string]string) (map[string]hotkey.Spec, error) {
+	specs := make(map[string]hotkey.Spec, len(texts))
+	for name, text := range texts {
+		if _, ok := ca.findHotkeyAction(name); !ok {
+			return nil, fmt.Errorf("未知动作: %s", name)
+		}
+		spec, err := hotkey.ParseSpec(text)
+		if err != nil {
+			return nil, fmt.Errorf("快捷键无效 (%s): %v", name, err)
+		}
+		specs[name] = spec
+	}
+	return specs, nil
+}
+
+// applyHotkeys 替换全部动作的热键绑定并按启用状态重新注册，失败时恢复原来的绑定
+func (ca *ClipboardApp) applyHotkeys(specs map[string]hotkey.Spec, enabled bool) error {
+	if enabled == ca.globalHotkey && sameHotkeys(specs, ca.hotkeys) {
+		return nil
+	}
+
+	prev, prevEnabled := ca.hotkeys, ca.globalHotkey
+	if err := ca.setGlobalHotkey(false); err != nil {
+		return err
+	}
+	ca.hotkeys = specs
+	if err := ca.setGlobalHotkey(enabled); err != nil {
+		ca.hotkeys = prev
+		if restoreErr := ca.setGlobalHotkey(prevEnabled); restoreErr != nil {
+			log.Printf("恢复全局热键失败: %v", restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// setGlobalHotkey 启用时注册全部已绑定的动作，禁用时注销全部热键
+func (ca *ClipboardApp) setGlobalHotkey(enabled bool) error {
+	if enabled == ca.globalHotkey {
+		return nil
+	}
+
+	if !enabled {
+		if err := ca.hotkeyMgr.UnregisterAll(); err != nil {
+			return fmt.Errorf("注销全局热键失败: %v", err)
+		}
+		ca.hotkeyIDs = make(map[string]int)
+		ca.globalHotkey = false
+		return nil
+	}
+
+	for _, action := range ca.hotkeyActions() {
+		spec, ok := ca.hotkeys[action.Name]
+		if !ok {
+			continue
+		}
+		id, err := ca.hotkeyMgr.Register(spec, action.run)
+		if err != nil {
+			ca.hotkeyMgr.UnregisterAll()
+			ca.hotkeyIDs = make(map[string]int)
+			return fmt.Errorf("注册全局热键 %s (%s) 失败: %v", spec, action.Label, err)
+		}
+		ca.hotkeyIDs[action.Name] = id
+	}
+	ca.globalHotkey = true
+	return nil
+}
+
+// sameHotkeys 比较两组动作绑定是否相同
+func sameHotkeys(a, b map[string]hotkey.Spec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, spec := range a {
+		if other, ok := b[name]; !ok || other != spec {
+			return false
+		}
+	}
+	return true
+}
+
+// retentionPolicy 根据设置创建历史记录保留策略
+func retentionPolicy(s settings.Settings) clipboard.RetentionPolicy {
+	return clipboard.RetentionPolicy{
+		MaxEntries:    s.MaxHistory,
+		MaxAge:        s.MaxAge(),
+		MaxTotalBytes: int64(s.MaxTotalMB) << 20,
+		MaxEntryBytes: int64(s.MaxEntryKB) << 10,
+	}
+}
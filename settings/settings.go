@@ -0,0 +1,296 @@
+// Package settings 保存用户设置：快捷键、历史记录数量、轮询间隔等，
+// 以带版本号的 JSON 文件存放在 XDG_CONFIG_HOME 下。
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"clipboard-monitor/hotkey"
+)
+
+// SchemaVersion 当前配置文件格式版本
+const SchemaVersion = 3
+
+const (
+	appDirName = "clipboard-monitor"
+	fileName   = "settings.json"
+)
+
+// 取值范围
+const (
+	MinMaxHistory     = 1
+	MaxMaxHistory     = 10000
+	MinPollIntervalMS = 100
+	MaxPollIntervalMS = 10000
+	MaxTypeDelayMS    = 1000
+	MinSensitiveTTL   = 10    // 秒
+	MaxSensitiveTTL   = 86400 // 秒
+	MinIncognitoMin   = 1
+	MaxIncognitoMin   = 1440
+	MaxMaxAgeDays     = 3650
+	MaxMaxTotalMB     = 10240
+	MaxMaxEntryKB     = 1048576
+)
+
+// Settings 用户设置
+type Settings struct {
+	Version            int               `json:"version"`
+	Hotkeys            map[string]string `json:"hotkeys"`        // 动作名 -> 快捷键，如 "Ctrl+Shift+V"
+	HotkeysEnabled     bool              `json:"hotkeysEnabled"` // 是否注册全局热键
+	MaxHistory         int               `json:"maxHistory"`
+	PollIntervalMS     int               `json:"pollIntervalMs"` // 轮询间隔（毫秒），仅在后端不支持事件通知时使用
+	MinimizeToTray     bool              `json:"minimizeToTray"`
+	TypeInsteadOfPaste bool              `json:"typeInsteadOfPaste"` // 粘贴时逐字符模拟输入而不是发送 Ctrl+V
+	TypeDelayMS        int               `json:"typeDelayMs"`        // 模拟输入时每个字符之间的间隔（毫秒）
+	Exclusions         []string          `json:"exclusions"`         // 不记录其复制内容的应用程序（进程名，支持通配符，以 + 开头表示始终记录）
+	SensitivePolicies  map[string]string `json:"sensitivePolicies"`  // 敏感内容检测器名 -> 处理策略，未列出的检测器使用默认策略
+	SensitiveTTLSec    int               `json:"sensitiveTtlSec"`    // 策略为 ttl 的敏感内容保留的秒数
+	IncognitoMinutes   int               `json:"incognitoMinutes"`   // 隐身模式暂停记录的分钟数
+	MaxAgeDays         int               `json:"maxAgeDays"`         // 删除超过该天数未再次复制的记录，0 表示不限制
+	MaxTotalMB         int               `json:"maxTotalMb"`         // 历史记录总大小上限（MB），0 表示不限制
+	MaxEntryKB         int               `json:"maxEntryKb"`         // 单条记录大小上限（KB），超出的内容不记录，0 表示不限制
+}
+
+// Defaults 返回默认设置
+func Defaults() Settings {
+	return Settings{
+		Version:        SchemaVersion,
+		Hotkeys:        map[string]string{"showQuickSelector": hotkey.DefaultSpec.String()},
+		MaxHistory:     50,
+		PollIntervalMS: 500,
+		TypeDelayMS:    5,
+		Exclusions:     []string{"keepassxc", "keepass", "1password", "bitwarden"},
+
+		SensitivePolicies: map[string]string{},
+		SensitiveTTLSec:   120,
+		IncognitoMinutes:  10,
+		MaxEntryKB:        10240,
+	}
+}
+
+// PollInterval 轮询间隔
+func (s Settings) PollInterval() time.Duration {
+	return time.Duration(s.PollIntervalMS) * time.Millisecond
+}
+
+// TypeDelay 模拟输入时的字符间隔
+func (s Settings) TypeDelay() time.Duration {
+	return time.Duration(s.TypeDelayMS) * time.Millisecond
+}
+
+// SensitiveTTL 敏感内容的保留时间
+func (s Settings) SensitiveTTL() time.Duration {
+	return time.Duration(s.SensitiveTTLSec) * time.Second
+}
+
+// IncognitoDuration 隐身模式的持续时间
+func (s Settings) IncognitoDuration() time.Duration {
+	return time.Duration(s.IncognitoMinutes) * time.Minute
+}
+
+// MaxAge 记录的最长保留时间，0 表示不限制
+func (s Settings) MaxAge() time.Duration {
+	return time.Duration(s.MaxAgeDays) * 24 * time.Hour
+}
+
+// Clone 返回深拷贝，修改副本不会影响原设置
+func (s Settings) Clone() Settings {
+	c := s
+	c.Hotkeys = make(map[string]string, len(s.Hotkeys))
+	for action, text := range s.Hotkeys {
+		c.Hotkeys[action] = text
+	}
+	c.Exclusions = append([]string{}, s.Exclusions...)
+	c.SensitivePolicies = make(map[string]string, len(s.SensitivePolicies))
+	for name, policy := range s.SensitivePolicies {
+		c.SensitivePolicies[name] = policy
+	}
+	return c
+}
+
+// Validate 检查设置是否有效，并将快捷键、排除规则和敏感内容策略规范化
+func (s *Settings) Validate() error {
+	if s.MaxHistory < MinMaxHistory || s.MaxHistory > MaxMaxHistory {
+		return fmt.Errorf("maxHistory must be between %d and %d, got %d", MinMaxHistory, MaxMaxHistory, s.MaxHistory)
+	}
+	if s.PollIntervalMS < MinPollIntervalMS || s.PollIntervalMS > MaxPollIntervalMS {
+		return fmt.Errorf("pollIntervalMs must be between %d and %d, got %d", MinPollIntervalMS, MaxPollIntervalMS, s.PollIntervalMS)
+	}
+	if s.TypeDelayMS < 0 || s.TypeDelayMS > MaxTypeDelayMS {
+		return fmt.Errorf("typeDelayMs must be between 0 and %d, got %d", MaxTypeDelayMS, s.TypeDelayMS)
+	}
+	if s.SensitiveTTLSec < MinSensitiveTTL || s.SensitiveTTLSec > MaxSensitiveTTL {
+		return fmt.Errorf("sensitiveTtlSec must be between %d and %d, got %d", MinSensitiveTTL, MaxSensitiveTTL, s.SensitiveTTLSec)
+	}
+	if s.IncognitoMinutes < MinIncognitoMin || s.IncognitoMinutes > MaxIncognitoMin {
+		return fmt.Errorf("incognitoMinutes must be between %d and %d, got %d", MinIncognitoMin, MaxIncognitoMin, s.IncognitoMinutes)
+	}
+	if s.MaxAgeDays < 0 || s.MaxAgeDays > MaxMaxAgeDays {
+		return fmt.Errorf("maxAgeDays must be between 0 and %d, got %d", MaxMaxAgeDays, s.MaxAgeDays)
+	}
+	if s.MaxTotalMB < 0 || s.MaxTotalMB > MaxMaxTotalMB {
+		return fmt.Errorf("maxTotalMb must be between 0 and %d, got %d", MaxMaxTotalMB, s.MaxTotalMB)
+	}
+	if s.MaxEntryKB < 0 || s.MaxEntryKB > MaxMaxEntryKB {
+		return fmt.Errorf("maxEntryKb must be between 0 and %d, got %d", MaxMaxEntryKB, s.MaxEntryKB)
+	}
+
+	used := make(map[hotkey.Spec]string, len(s.Hotkeys))
+	for action, text := range s.Hotkeys {
+		if text == "" {
+			delete(s.Hotkeys, action)
+			continue
+		}
+		spec, err := hotkey.ParseSpec(text)
+		if err != nil {
+			return fmt.Errorf("hotkey for %s: %v", action, err)
+		}
+		if other, ok := used[spec]; ok {
+			return fmt.Errorf("hotkey %s is bound to both %s and %s", spec, other, action)
+		}
+		used[spec] = action
+		s.Hotkeys[action] = spec.String()
+	}
+
+	exclusions := make([]string, 0, len(s.Exclusions))
+	for _, name := range s.Exclusions {
+		if name = strings.TrimSpace(name); name != "" {
+			exclusions = append(exclusions, name)
+		}
+	}
+	s.Exclusions = exclusions
+
+	// 策略名称由 clipboard.ParsePolicy 检查，这里只规范化大小写并去掉空值
+	if s.SensitivePolicies == nil {
+		s.SensitivePolicies = make(map[string]string)
+	}
+	for name, policy := range s.SensitivePolicies {
+		if policy = strings.ToLower(strings.TrimSpace(policy)); policy == "" {
+			delete(s.SensitivePolicies, name)
+		} else {
+			s.SensitivePolicies[name] = policy
+		}
+	}
+	return nil
+}
+
+// DefaultDir 返回配置目录：优先使用 CLIPBOARD_MONITOR_CONFIG_DIR，
+// 其次是 $XDG_CONFIG_HOME/clipboard-monitor，最后是系统的用户配置目录
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("CLIPBOARD_MONITOR_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appDirName), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// Load 从目录中读取设置，文件不存在时返回默认设置
+//
+// 旧版本的配置会迁移到当前版本，缺少的字段使用默认值。
+func Load(dir string) (Settings, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fileName))
+	if os.IsNotExist(err) {
+		return Defaults(), nil
+	}
+	if err != nil {
+		return Defaults(), fmt.Errorf("failed to read settings: %v", err)
+	}
+
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Defaults(), fmt.Errorf("failed to parse settings: %v", err)
+	}
+	if s.Version > SchemaVersion {
+		return Defaults(), fmt.Errorf("settings version %d is newer than supported version %d", s.Version, SchemaVersion)
+	}
+	s = migrate(s)
+	if err := s.Validate(); err != nil {
+		return Defaults(), fmt.Errorf("invalid settings: %v", err)
+	}
+	return s, nil
+}
+
+// migrate 将旧版本的设置升级到当前版本，并为缺少的字段填充默认值
+func migrate(s Settings) Settings {
+	defaults := Defaults()
+	// 版本 0：没有版本号的早期配置，字段与版本 1 相同但可能不完整
+	if s.Hotkeys == nil {
+		s.Hotkeys = defaults.Hotkeys
+	}
+	if s.MaxHistory == 0 {
+		s.MaxHistory = defaults.MaxHistory
+	}
+	if s.PollIntervalMS == 0 {
+		s.PollIntervalMS = defaults.PollIntervalMS
+	}
+	if s.Exclusions == nil {
+		s.Exclusions = defaults.Exclusions
+	}
+	if s.SensitivePolicies == nil {
+		s.SensitivePolicies = defaults.SensitivePolicies
+	}
+	if s.SensitiveTTLSec == 0 {
+		s.SensitiveTTLSec = defaults.SensitiveTTLSec
+	}
+	if s.IncognitoMinutes == 0 {
+		s.IncognitoMinutes = defaults.IncognitoMinutes
+	}
+	// 版本 2：新增模拟输入选项，0 是有效的字符间隔，只能按版本号判断是否需要填充默认值
+	if s.Version < 2 {
+		s.TypeDelayMS = defaults.TypeDelayMS
+	}
+	// 版本 3：单条记录默认限制为 10 MB，0 表示不限制，同样只能按版本号填充
+	if s.Version < 3 {
+		s.MaxEntryKB = defaults.MaxEntryKB
+	}
+	s.Version = SchemaVersion
+	return s
+}
+
+// Save 校验并保存设置，先写入临时文件再重命名，避免写入中断导致配置损坏
+func Save(dir string, s Settings) error {
+	s = s.Clone()
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	s.Version = SchemaVersion
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config dir: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, fileName+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filepath.Join(dir, fileName))
+}
@@ -0,0 +1,223 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingReturnsDefaults(t *testing.T) {
+	s, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if s.MaxHistory != 50 || s.PollInterval() != 500*time.Millisecond {
+		t.Errorf("Unexpected defaults: %+v", s)
+	}
+	if s.Hotkeys["showQuickSelector"] != "Ctrl+Shift+V" {
+		t.Errorf("Unexpected default hotkeys: %v", s.Hotkeys)
+	}
+	if len(s.Exclusions) == 0 || s.Exclusions[0] != "keepassxc" {
+		t.Errorf("Expected password managers to be excluded by default, got %v", s.Exclusions)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+
+	s := Defaults()
+	s.Hotkeys["pastePrevious"] = "alt + ctrl + p"
+	s.HotkeysEnabled = true
+	s.MaxHistory = 200
+	s.PollIntervalMS = 1000
+	s.MinimizeToTray = true
+	s.Exclusions = []string{" keepassxc ", "", "1Password"}
+	s.SensitivePolicies = map[string]string{"card": " Skip ", "jwt": ""}
+	s.SensitiveTTLSec = 30
+	s.IncognitoMinutes = 5
+	s.MaxAgeDays = 30
+	s.MaxTotalMB = 100
+	s.MaxEntryKB = 0
+	if err := Save(dir, s); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Version != SchemaVersion {
+		t.Errorf("Expected version %d, got %d", SchemaVersion, loaded.Version)
+	}
+	if loaded.Hotkeys["pastePrevious"] != "Ctrl+Alt+P" {
+		t.Errorf("Expected normalized hotkey, got %q", loaded.Hotkeys["pastePrevious"])
+	}
+	if !loaded.HotkeysEnabled || loaded.MaxHistory != 200 || loaded.PollIntervalMS != 1000 || !loaded.MinimizeToTray {
+		t.Errorf("Settings not round-tripped: %+v", loaded)
+	}
+	if len(loaded.Exclusions) != 2 || loaded.Exclusions[0] != "keepassxc" {
+		t.Errorf("Unexpected exclusions: %v", loaded.Exclusions)
+	}
+	if len(loaded.SensitivePolicies) != 1 || loaded.SensitivePolicies["card"] != "skip" || loaded.SensitiveTTL() != 30*time.Second {
+		t.Errorf("Unexpected sensitive content settings: %v, %d", loaded.SensitivePolicies, loaded.SensitiveTTLSec)
+	}
+	if loaded.IncognitoDuration() != 5*time.Minute {
+		t.Errorf("Expected 5 minute incognito mode, got %d", loaded.IncognitoMinutes)
+	}
+	if loaded.MaxAge() != 30*24*time.Hour || loaded.MaxTotalMB != 100 || loaded.MaxEntryKB != 0 {
+		t.Errorf("Unexpected retention settings: %d, %d, %d", loaded.MaxAgeDays, loaded.MaxTotalMB, loaded.MaxEntryKB)
+	}
+
+	// Save 不应修改调用方的设置
+	if s.Hotkeys["pastePrevious"] != "alt + ctrl + p" {
+		t.Error("Save modified the caller's settings")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Settings)
+	}{
+		{"max history too small", func(s *Settings) { s.MaxHistory = 0 }},
+		{"max history too large", func(s *Settings) { s.MaxHistory = MaxMaxHistory + 1 }},
+		{"poll interval too small", func(s *Settings) { s.PollIntervalMS = 10 }},
+		{"negative type delay", func(s *Settings) { s.TypeDelayMS = -1 }},
+		{"sensitive ttl too small", func(s *Settings) { s.SensitiveTTLSec = 1 }},
+		{"incognito too long", func(s *Settings) { s.IncognitoMinutes = MaxIncognitoMin + 1 }},
+		{"negative max age", func(s *Settings) { s.MaxAgeDays = -1 }},
+		{"total size too large", func(s *Settings) { s.MaxTotalMB = MaxMaxTotalMB + 1 }},
+		{"negative entry size", func(s *Settings) { s.MaxEntryKB = -1 }},
+		{"invalid hotkey", func(s *Settings) { s.Hotkeys["pastePrevious"] = "Ctrl+Nope" }},
+		{"hotkey without modifier", func(s *Settings) { s.Hotkeys["pastePrevious"] = "P" }},
+		{"duplicate hotkey", func(s *Settings) { s.Hotkeys["pastePrevious"] = "Shift+Ctrl+V" }},
+	}
+	for _, tt := range tests {
+		s := Defaults()
+		tt.modify(&s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", tt.name)
+		}
+		if err := Save(t.TempDir(), s); err == nil {
+			t.Errorf("%s: expected Save to reject invalid settings", tt.name)
+		}
+	}
+
+	s := Defaults()
+	s.Hotkeys["pastePrevious"] = ""
+	if err := s.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if _, ok := s.Hotkeys["pastePrevious"]; ok {
+		t.Error("Expected empty hotkey to be removed")
+	}
+}
+
+func TestLoadMigratesUnversioned(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"hotkeysEnabled": true, "maxHistory": 80}`
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if s.Version != SchemaVersion || !s.HotkeysEnabled || s.MaxHistory != 80 {
+		t.Errorf("Unexpected migrated settings: %+v", s)
+	}
+	if s.PollIntervalMS != 500 || s.Hotkeys["showQuickSelector"] != "Ctrl+Shift+V" {
+		t.Errorf("Expected missing fields to use defaults: %+v", s)
+	}
+	if s.TypeDelayMS != 5 {
+		t.Errorf("Expected default type delay after migration, got %d", s.TypeDelayMS)
+	}
+	if s.SensitivePolicies == nil || s.SensitiveTTLSec != 120 {
+		t.Errorf("Expected default sensitive content settings, got %v, %d", s.SensitivePolicies, s.SensitiveTTLSec)
+	}
+	if s.IncognitoMinutes != 10 {
+		t.Errorf("Expected default incognito duration, got %d", s.IncognitoMinutes)
+	}
+	if s.MaxEntryKB != 10240 {
+		t.Errorf("Expected default entry size limit after migration, got %d", s.MaxEntryKB)
+	}
+}
+
+func TestLoadMigratesEntrySizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"version": 2, "maxHistory": 80, "typeDelayMs": 0}`
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if s.MaxEntryKB != 10240 || s.TypeDelayMS != 0 {
+		t.Errorf("Expected only the entry size limit to be filled in, got %d, %d", s.MaxEntryKB, s.TypeDelayMS)
+	}
+}
+
+func TestLoadKeepsZeroTypeDelay(t *testing.T) {
+	dir := t.TempDir()
+	s := Defaults()
+	s.TypeInsteadOfPaste = true
+	s.TypeDelayMS = 0
+	if err := Save(dir, s); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !loaded.TypeInsteadOfPaste || loaded.TypeDelayMS != 0 {
+		t.Errorf("Expected type settings to round-trip, got %+v", loaded)
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"version": 99, "maxHistory": 80}`
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Load(dir)
+	if err == nil {
+		t.Fatal("Expected error for newer settings version")
+	}
+	if s.MaxHistory != Defaults().MaxHistory {
+		t.Error("Expected defaults when loading fails")
+	}
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, fileName), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil {
+		t.Error("Expected error for corrupt settings")
+	}
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("CLIPBOARD_MONITOR_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg-config")
+	dir, err := DefaultDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join("/tmp/xdg-config", appDirName) {
+		t.Errorf("Unexpected dir: %s", dir)
+	}
+
+	t.Setenv("CLIPBOARD_MONITOR_CONFIG_DIR", "/tmp/override")
+	if dir, _ := DefaultDir(); dir != "/tmp/override" {
+		t.Errorf("Expected override dir, got %s", dir)
+	}
+}
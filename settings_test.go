@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"clipboard-monitor/clipboard"
+	"clipboard-monitor/settings"
+)
+
+func TestUpdateSettingsKeepsOldSettingsWhenSaveFails(t *testing.T) {
+	// 配置目录的位置是一个普通文件，保存必然失败
+	configDir := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(configDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	monitor := clipboard.NewMonitor(10, clipboard.WithBackend(clipboard.NewMemoryBackend()))
+	ca := &ClipboardApp{monitor: monitor, configDir: configDir, settings: settings.Defaults()}
+	ca.hotkeys, _ = ca.hotkeySpecs(ca.settings.Hotkeys)
+	before := monitor.PollInterval()
+
+	s := ca.settings.Clone()
+	s.PollIntervalMS = 2000
+	if err := ca.updateSettings(s); err == nil {
+		t.Fatal("Expected save error")
+	}
+	if got := monitor.PollInterval(); got != before {
+		t.Errorf("Expected poll interval to stay %v, got %v", before, got)
+	}
+	if ca.settings.PollIntervalMS == 2000 {
+		t.Error("Expected settings to stay unchanged")
+	}
+
+	ca.configDir = t.TempDir()
+	if err := ca.updateSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	if got := monitor.PollInterval(); got != 2*time.Second {
+		t.Errorf("Expected 2s poll interval, got %v", got)
+	}
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const keyFileName = "history.key"
+
+// keyCheckPlaintext 用于校验口令是否正确的已知明文
+var keyCheckPlaintext = []byte("clipboard-monitor key check")
+
+var (
+	// ErrLocked 存储已加密但未提供密钥
+	ErrLocked = errors.New("history store is locked")
+	// ErrWrongPassphrase 口令错误
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	// ErrEncryptionExists 已经设置过加密口令
+	ErrEncryptionExists = errors.New("encryption already enabled")
+	// ErrTampered 密文无法在其所在位置通过认证：记录被修改、删除、重复、调换或从旧数据重放
+	ErrTampered = errors.New("history store has been tampered with")
+)
+
+// KDFParams Argon2id 密钥派生参数
+type KDFParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+// DefaultKDFParams 默认的密钥派生参数（64 MiB 内存）
+var DefaultKDFParams = KDFParams{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+// 密钥派生参数的上限，防止被篡改的口令文件耗尽内存或 CPU
+const (
+	maxKDFTime    = 100
+	maxKDFMemory  = 1 << 20 // KiB，即 1 GiB
+	maxKDFThreads = 64
+)
+
+// validate 检查参数是否在 Argon2id 可接受且资源可控的范围内
+func (p KDFParams) validate() error {
+	if p.Time < 1 || p.Time > maxKDFTime {
+		return fmt.Errorf("invalid kdf time: %d", p.Time)
+	}
+	if p.Threads < 1 || p.Threads > maxKDFThreads {
+		return fmt.Errorf("invalid kdf threads: %d", p.Threads)
+	}
+	// Argon2 要求每个线程至少 8 KiB 内存
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > maxKDFMemory {
+		return fmt.Errorf("invalid kdf memory: %d KiB", p.Memory)
+	}
+	return nil
+}
+
+// keyFile 保存盐值、派生参数和口令校验值，不包含密钥本身
+type keyFile struct {
+	Version int       `json:"version"`
+	KDF     string    `json:"kdf"`
+	Params  KDFParams `json:"params"`
+	Salt    []byte    `json:"salt"`
+	Check   []byte    `json:"check"`
+}
+
+// Cipher 对单条记录进行认证加密
+//
+// additionalData 不加密但参与认证，用于把密文绑定到它在日志或快照中的位置，
+// 以相同的 additionalData 才能解密。
+type Cipher interface {
+	Seal(plaintext, additionalData []byte) ([]byte, error)
+	Open(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// aeadCipher 基于 XChaCha20-Poly1305 的 Cipher 实现，密文格式为 nonce||ciphertext
+type aeadCipher struct {
+	key []byte
+}
+
+// Seal 使用随机 nonce 加密
+func (c *aeadCipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(c.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open 解密并校验
+func (c *aeadCipher) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(c.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, data := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, data, additionalData)
+}
+
+// EncryptionEnabled 检查数据目录是否已启用加密
+func EncryptionEnabled(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, keyFileName))
+	return err == nil
+}
+
+// SetupEncryption 为数据目录设置口令，返回派生出的 Cipher
+func SetupEncryption(dir, passphrase string, params KDFParams) (Cipher, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	if EncryptionEnabled(dir) {
+		return nil, ErrEncryptionExists
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create data dir: %v", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	cipher := deriveCipher(passphrase, salt, params)
+	// 口令校验值独立保存在口令文件中，不绑定位置，与已有的口令文件保持兼容
+	check, err := cipher.Seal(keyCheckPlaintext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	kf := keyFile{
+		Version: 1,
+		KDF:     "argon2id",
+		Params:  params,
+		Salt:    salt,
+		Check:   check,
+	}
+	data, err := json.Marshal(kf)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, keyFileName), data); err != nil {
+		return nil, fmt.Errorf("failed to write key file: %v", err)
+	}
+	return cipher, nil
+}
+
+// DiscardEncryption 删除口令文件，用于在数据尚未加密时撤销 SetupEncryption
+func DiscardEncryption(dir string) error {
+	err := os.Remove(filepath.Join(dir, keyFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// UnlockEncryption 使用口令派生密钥并校验，口令错误时返回 ErrWrongPassphrase
+func UnlockEncryption(dir, passphrase string) (Cipher, error) {
+	data, err := os.ReadFile(filepath.Join(dir, keyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %v", err)
+	}
+
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("failed to parse key file: %v", err)
+	}
+	if kf.KDF != "argon2id" {
+		return nil, fmt.Errorf("unsupported kdf: %s", kf.KDF)
+	}
+	if err := kf.Params.validate(); err != nil {
+		return nil, fmt.Errorf("invalid key file: %v", err)
+	}
+	if len(kf.Salt) < 8 {
+		return nil, errors.New("invalid key file: salt too short")
+	}
+
+	cipher := deriveCipher(passphrase, kf.Salt, kf.Params)
+	plain, err := cipher.Open(kf.Check, nil)
+	if err != nil || subtle.ConstantTimeCompare(plain, keyCheckPlaintext) != 1 {
+		return nil, ErrWrongPassphrase
+	}
+	return cipher, nil
+}
+
+// deriveCipher 通过 Argon2id 从口令派生密钥
+func deriveCipher(passphrase string, salt []byte, params KDFParams) *aeadCipher {
+	key := argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, chacha20poly1305.KeySize)
+	return &aeadCipher{key: key}
+}
@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testKDFParams 测试中使用的低开销参数
+var testKDFParams = KDFParams{Time: 1, Memory: 64, Threads: 1}
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := SetupEncryption(dir, "secret", testKDFParams)
+	if err != nil {
+		t.Fatalf("SetupEncryption failed: %v", err)
+	}
+
+	s, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.Put(entry("password123"))
+	s.Put(entry("token-abc"))
+
+	// 日志中不应出现明文
+	journal, err := os.ReadFile(filepath.Join(dir, journalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(journal, []byte("password123")) {
+		t.Error("Journal contains plaintext content")
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := os.ReadFile(filepath.Join(dir, snapshotFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(snapshot, []byte("token-abc")) {
+		t.Error("Snapshot contains plaintext content")
+	}
+
+	if _, err := Open(dir); err != ErrLocked {
+		t.Errorf("Expected ErrLocked without cipher, got %v", err)
+	}
+	if _, err := UnlockEncryption(dir, "wrong"); err != ErrWrongPassphrase {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+
+	unlocked, err := UnlockEncryption(dir, "secret")
+	if err != nil {
+		t.Fatalf("UnlockEncryption failed: %v", err)
+	}
+	reopened, err := Open(dir, WithCipher(unlocked))
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	assertContents(t, contents(t, reopened), "token-abc", "password123")
+}
+
+func TestEnableEncryptionRewritesPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Put(entry("before encryption"))
+	s.Close()
+
+	cipher, err := SetupEncryption(dir, "secret", testKDFParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer encrypted.Close()
+	assertContents(t, contents(t, encrypted), "before encryption")
+
+	for _, name := range []string{journalFileName, snapshotFileName} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("before encryption")) {
+			t.Errorf("%s still contains plaintext", name)
+		}
+	}
+}
+
+func TestEnableEncryptionWhileOpen(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Put(entry("open during setup"))
+
+	// 先合并旧存储的日志，关闭时不会再用明文覆盖新的快照
+	if err := plain.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := SetupEncryption(dir, "secret", testKDFParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := plain.Close(); err != nil {
+		t.Fatal(err)
+	}
+	encrypted.Close()
+
+	for _, name := range []string{journalFileName, snapshotFileName} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("open during setup")) {
+			t.Errorf("%s contains plaintext", name)
+		}
+	}
+	reopened, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	assertContents(t, contents(t, reopened), "open during setup")
+}
+
+func TestEncryptedJournalRejectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := SetupEncryption(dir, "secret", testKDFParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"a", "b", "c"} {
+		s.Put(entry(content))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, journalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	lines := strings.SplitAfter(string(data), "\n")
+	header, a, b, c := lines[0], lines[1], lines[2], lines[3]
+
+	// 日志头是明文，改动其中的序号可以让被删掉开头的日志通过认证，但快照之后的记录不再连续
+	movedHeader := `{"seq":1,"op":"header","version":4,"encrypted":true}`
+	movedHeader = fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE([]byte(movedHeader)), movedHeader)
+
+	tests := map[string][]string{
+		"dropped":    {header, a, c},
+		"duplicated": {header, a, a, b, c},
+		"reordered":  {header, b, a, c},
+		"moved base": {movedHeader, b, c},
+	}
+	for name, journal := range tests {
+		tampered := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tampered, journalFileName), []byte(strings.Join(journal, "")), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(tampered, WithCipher(cipher)); err != ErrTampered {
+			t.Errorf("%s: expected ErrTampered, got %v", name, err)
+		}
+	}
+}
+
+func TestEncryptedSnapshotRejectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := SetupEncryption(dir, "secret", testKDFParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"a", "b", "c"} {
+		s.Put(entry(content))
+	}
+	s.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	sealed := snap.Sealed
+
+	tests := map[string]func(snap *snapshotFile){
+		"dropped":   func(snap *snapshotFile) { snap.Sealed = sealed[:2] },
+		"reordered": func(snap *snapshotFile) { snap.Sealed = [][]byte{sealed[1], sealed[0], sealed[2]} },
+		"rollback":  func(snap *snapshotFile) { snap.Seq-- },
+	}
+	for name, tamper := range tests {
+		modified := snap
+		tamper(&modified)
+		data, err := json.Marshal(modified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, snapshotFileName), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(dir, WithCipher(cipher)); err != ErrTampered {
+			t.Errorf("%s: expected ErrTampered, got %v", name, err)
+		}
+	}
+}
+
+func TestEncryptedV3JournalIsRewritten(t *testing.T) {
+	dir := t.TempDir()
+	cipher, err := SetupEncryption(dir, "secret", testKDFParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 版本 3 的密文没有附加数据
+	journal := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE([]byte(`{"op":"header","version":3,"encrypted":true}`)), `{"op":"header","version":3,"encrypted":true}`)
+	for i, content := range []string{"a", "b"} {
+		rec := journalRecord{Seq: uint64(i + 1), Op: opPut, Entry: &entryRecord{ID: content, Content: content}}
+		data, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sealed, err := cipher.Seal(data, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line := base64.StdEncoding.EncodeToString(sealed)
+		journal += fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE([]byte(line)), line)
+	}
+	if err := os.WriteFile(filepath.Join(dir, journalFileName), []byte(journal), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	assertContents(t, contents(t, s), "b", "a")
+	s.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Version != SchemaVersion {
+		t.Errorf("Expected snapshot to be rewritten as version %d, got %d", SchemaVersion, snap.Version)
+	}
+	reopened, err := Open(dir, WithCipher(cipher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	assertContents(t, contents(t, reopened), "b", "a")
+}
+
+func TestDiscardEncryption(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := SetupEncryption(dir, "secret", testKDFParams); err != nil {
+		t.Fatal(err)
+	}
+	if err := DiscardEncryption(dir); err != nil {
+		t.Fatal(err)
+	}
+	if EncryptionEnabled(dir) {
+		t.Error("Expected key file to be removed")
+	}
+	if err := DiscardEncryption(dir); err != nil {
+		t.Errorf("Expected no error without a key file, got %v", err)
+	}
+}
+
+func TestSetupEncryptionTwice(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := SetupEncryption(dir, "a", testKDFParams); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetupEncryption(dir, "b", testKDFParams); err != ErrEncryptionExists {
+		t.Errorf("Expected ErrEncryptionExists, got %v", err)
+	}
+}
+
+func TestUnlockRejectsInvalidParams(t *testing.T) {
+	tests := map[string]KDFParams{
+		"zero threads": {Time: 1, Memory: 64, Threads: 0},
+		"zero time":    {Time: 0, Memory: 64, Threads: 1},
+		"huge memory":  {Time: 1, Memory: 1 << 31, Threads: 1},
+		"tiny memory":  {Time: 1, Memory: 8, Threads: 4},
+	}
+	for name, params := range tests {
+		dir := t.TempDir()
+		if _, err := SetupEncryption(dir, "secret", testKDFParams); err != nil {
+			t.Fatal(err)
+		}
+
+		// 模拟被篡改的口令文件
+		path := filepath.Join(dir, keyFileName)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kf keyFile
+		if err := json.Unmarshal(data, &kf); err != nil {
+			t.Fatal(err)
+		}
+		kf.Params = params
+		if data, err = json.Marshal(kf); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := UnlockEncryption(dir, "secret"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+		if _, err := SetupEncryption(t.TempDir(), "secret", params); err == nil {
+			t.Errorf("%s: expected SetupEncryption to reject params", name)
+		}
+	}
+}
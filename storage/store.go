@@ -0,0 +1,767 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"clipboard-monitor/clipboard"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SchemaVersion 当前存储格式版本
+//
+// 版本 2 为条目增加了 ID，版本 1 的条目在打开时补充 ID 并重写。
+// 版本 3 起较大条目的内容和格式数据压缩保存，旧版本的数据无需迁移。
+// 版本 4 起加密的日志记录和快照条目以所在位置作为附加数据密封，
+// 旧版本的密文在打开时用新格式重写。
+const SchemaVersion = 4
+
+// boundSchemaVersion 密文开始绑定位置的格式版本
+const boundSchemaVersion = 4
+
+const (
+	appDirName              = "clipboard-monitor"
+	snapshotFileName        = "history.snapshot"
+	journalFileName         = "history.journal"
+	defaultCompactThreshold = 256
+	compressThreshold       = 16 << 10 // 内容和格式数据超过该大小（字节）的记录压缩保存
+)
+
+// 日志操作类型
+const (
+	opHeader = "header"
+	opPut    = "put"
+	opUpdate = "update"
+	opDelete = "delete"
+	opClear  = "clear"
+)
+
+// entryRecord 条目的磁盘表示
+type entryRecord struct {
+	ID        string            `json:"id,omitempty"`
+	Content   string            `json:"content"`
+	Formats   map[string][]byte `json:"formats,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Pinned    bool              `json:"pinned,omitempty"`
+	PinOrder  int               `json:"pin_order,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	SourceApp string            `json:"source_app,omitempty"`
+	Packed    []byte            `json:"packed,omitempty"` // 压缩后的 Content 和 Formats，仅在磁盘上使用
+}
+
+// packedData 压缩保存的部分
+type packedData struct {
+	Content string            `json:"content"`
+	Formats map[string][]byte `json:"formats,omitempty"`
+}
+
+// journalRecord 追加日志中的一条记录
+type journalRecord struct {
+	Seq       uint64       `json:"seq,omitempty"`
+	Op        string       `json:"op"`
+	Version   int          `json:"version,omitempty"`
+	Encrypted bool         `json:"encrypted,omitempty"`
+	Entry     *entryRecord `json:"entry,omitempty"`
+}
+
+// snapshotFile 压缩后的快照文件，加密时条目逐条密封保存在 Sealed 中
+type snapshotFile struct {
+	Version   int           `json:"version"`
+	Seq       uint64        `json:"seq"`
+	Encrypted bool          `json:"encrypted,omitempty"`
+	Entries   []entryRecord `json:"entries,omitempty"`
+	Sealed    [][]byte      `json:"sealed,omitempty"`
+}
+
+// FileStore 基于追加日志和定期压缩快照的历史记录存储，实现 clipboard.Store
+type FileStore struct {
+	mu               sync.Mutex
+	dir              string
+	journal          *os.File
+	entries          []entryRecord // 最新优先
+	seq              uint64        // 最后写入的日志序号
+	pending          int           // 上次压缩后写入的日志条数
+	compactThreshold int
+	cipher           Cipher
+	plaintextFound   bool // 加密模式下读到了明文数据，需要重写
+	idsAssigned      bool // 为旧版本条目补充了 ID，需要重写
+	unboundFound     bool // 读到了未绑定位置的旧版本密文，需要重写
+}
+
+// Option FileStore 配置选项
+type Option func(*FileStore)
+
+// WithCipher 启用逐条记录加密
+func WithCipher(cipher Cipher) Option {
+	return func(s *FileStore) {
+		s.cipher = cipher
+	}
+}
+
+// DefaultDir 返回默认数据目录
+//
+// 优先使用 $CLIPBOARD_MONITOR_DATA_DIR，其次 $XDG_DATA_HOME/clipboard-monitor；
+// 都未设置时 Windows 使用本机的 %LocalAppData%，其他平台使用 ~/.local/share。
+// 历史记录可能很大，不应随漫游配置文件在域中同步，只有设置保存在漫游的 %AppData% 中。
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("CLIPBOARD_MONITOR_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appDirName), nil
+	}
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, appDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", appDirName), nil
+}
+
+// Open 打开（或创建）指定目录下的历史记录存储
+//
+// 数据目录已启用加密但未通过 WithCipher 提供密钥时返回 ErrLocked。
+func Open(dir string, opts ...Option) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create data dir: %v", err)
+	}
+
+	s := &FileStore{
+		dir:              dir,
+		compactThreshold: defaultCompactThreshold,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cipher == nil && EncryptionEnabled(dir) {
+		return nil, ErrLocked
+	}
+
+	if err := s.readSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := s.replayJournal(); err != nil {
+		return nil, err
+	}
+	s.assignIDs()
+
+	journal, err := os.OpenFile(s.journalPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %v", err)
+	}
+	s.journal = journal
+
+	info, err := journal.Stat()
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		if err := s.writeHeader(); err != nil {
+			journal.Close()
+			return nil, err
+		}
+	}
+
+	// 首次启用加密时立即用密文重写已有的明文数据，旧版本数据补充 ID 或密文未绑定位置时同样立即重写
+	if s.plaintextFound || s.idsAssigned || s.unboundFound {
+		if err := s.compact(); err != nil {
+			journal.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Load 返回当前保存的全部条目（最新优先）
+func (s *FileStore) Load() ([]clipboard.ClipboardEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]clipboard.ClipboardEntry, 0, len(s.entries))
+	for _, rec := range s.entries {
+		entries = append(entries, rec.toEntry())
+	}
+	return entries, nil
+}
+
+// Put 新增条目或将已有条目移动到顶部
+func (s *FileStore) Put(entry clipboard.ClipboardEntry) error {
+	rec := newEntryRecord(entry)
+	return s.append(journalRecord{Op: opPut, Entry: &rec})
+}
+
+// Update 原位修改条目，不改变顺序
+func (s *FileStore) Update(entry clipboard.ClipboardEntry) error {
+	rec := newEntryRecord(entry)
+	return s.append(journalRecord{Op: opUpdate, Entry: &rec})
+}
+
+// Delete 删除条目
+func (s *FileStore) Delete(entry clipboard.ClipboardEntry) error {
+	rec := newEntryRecord(entry)
+	return s.append(journalRecord{Op: opDelete, Entry: &rec})
+}
+
+// Clear 清空全部条目
+func (s *FileStore) Clear() error {
+	return s.append(journalRecord{Op: opClear})
+}
+
+// Compact 将当前状态写入快照并截断日志
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compact()
+}
+
+// Close 压缩未合并的日志并关闭存储
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journal == nil {
+		return nil
+	}
+	var err error
+	if s.pending > 0 {
+		err = s.compact()
+	}
+	if cerr := s.journal.Close(); err == nil {
+		err = cerr
+	}
+	s.journal = nil
+	return err
+}
+
+// append 应用操作并写入日志
+func (s *FileStore) append(rec journalRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journal == nil {
+		return errors.New("store is closed")
+	}
+
+	rec.Seq = s.seq + 1
+	if err := s.writeRecord(rec); err != nil {
+		return err
+	}
+	s.seq = rec.Seq
+	s.apply(rec)
+
+	s.pending++
+	if s.pending >= s.compactThreshold {
+		return s.compact()
+	}
+	return nil
+}
+
+// apply 将日志记录应用到内存状态
+func (s *FileStore) apply(rec journalRecord) {
+	switch rec.Op {
+	case opPut:
+		if i := s.find(*rec.Entry); i >= 0 {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+		}
+		s.entries = append([]entryRecord{*rec.Entry}, s.entries...)
+	case opUpdate:
+		if i := s.find(*rec.Entry); i >= 0 {
+			s.entries[i] = *rec.Entry
+		}
+	case opDelete:
+		if i := s.find(*rec.Entry); i >= 0 {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+		}
+	case opClear:
+		s.entries = nil
+	}
+}
+
+// find 返回与记录对应的条目位置，不存在时返回 -1
+func (s *FileStore) find(rec entryRecord) int {
+	for i, existing := range s.entries {
+		if existing.sameEntry(rec) {
+			return i
+		}
+	}
+	return -1
+}
+
+// assignIDs 为没有 ID 的旧版本条目分配 ID
+func (s *FileStore) assignIDs() {
+	for i := range s.entries {
+		if s.entries[i].ID == "" {
+			s.entries[i].ID = clipboard.NewEntryID()
+			s.idsAssigned = true
+		}
+	}
+}
+
+// writeHeader 写入日志头，记录格式版本、是否加密和快照序号，之后的记录从该序号加一开始连续编号
+func (s *FileStore) writeHeader() error {
+	return s.writeRecord(journalRecord{Seq: s.seq, Op: opHeader, Version: SchemaVersion, Encrypted: s.cipher != nil})
+}
+
+// writeRecord 以 "crc32 数据" 的行格式写入一条记录并落盘
+//
+// 数据为记录的 JSON；启用加密时除日志头外的记录为 base64 编码的密文，
+// 并以记录序号作为附加数据，记录被删除、重复或调换后无法通过认证。
+func (s *FileStore) writeRecord(rec journalRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if s.cipher != nil && rec.Op != opHeader {
+		sealed, err := s.cipher.Seal(data, journalAD(rec.Seq))
+		if err != nil {
+			return err
+		}
+		data = []byte(base64.StdEncoding.EncodeToString(sealed))
+	}
+
+	line := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(data), data)
+	if _, err := s.journal.WriteString(line); err != nil {
+		return fmt.Errorf("failed to write journal: %v", err)
+	}
+	return s.journal.Sync()
+}
+
+// compact 写入快照并重置日志，调用方需持有锁
+func (s *FileStore) compact() error {
+	snap := snapshotFile{
+		Version: SchemaVersion,
+		Seq:     s.seq,
+		Entries: s.entries,
+	}
+	if s.cipher != nil {
+		sealed, err := s.sealEntries(s.seq, s.entries)
+		if err != nil {
+			return err
+		}
+		snap.Encrypted = true
+		snap.Entries = nil
+		snap.Sealed = sealed
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.snapshotPath(), data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+
+	// 快照已包含全部日志内容，序号不大于快照的记录在重放时会被跳过，
+	// 因此即使在截断前崩溃也不会丢失或重复数据
+	if err := s.journal.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate journal: %v", err)
+	}
+	if err := s.writeHeader(); err != nil {
+		return err
+	}
+	s.pending = 0
+	return nil
+}
+
+// readSnapshot 读取快照文件
+func (s *FileStore) readSnapshot() error {
+	data, err := os.ReadFile(s.snapshotPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %v", err)
+	}
+
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to parse snapshot: %v", err)
+	}
+	if err := checkVersion(snap.Version); err != nil {
+		return err
+	}
+
+	entries := snap.Entries
+	if snap.Encrypted {
+		if s.cipher == nil {
+			return ErrLocked
+		}
+		entries, err = s.openEntries(snap.Version, snap.Seq, snap.Sealed)
+		if err != nil {
+			return err
+		}
+	} else if s.cipher != nil && len(entries) > 0 {
+		s.plaintextFound = true
+	}
+
+	s.entries = migrateEntries(snap.Version, entries)
+	s.seq = snap.Seq
+	return nil
+}
+
+// sealEntries 逐条加密条目，每条绑定快照序号、条目位置和条目总数
+func (s *FileStore) sealEntries(seq uint64, entries []entryRecord) ([][]byte, error) {
+	sealed := make([][]byte, 0, len(entries))
+	for i, rec := range entries {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return nil, err
+		}
+		ciphertext, err := s.cipher.Seal(data, snapshotAD(seq, i, len(entries)))
+		if err != nil {
+			return nil, err
+		}
+		sealed = append(sealed, ciphertext)
+	}
+	return sealed, nil
+}
+
+// openEntries 逐条解密条目，旧版本快照的条目未绑定位置
+func (s *FileStore) openEntries(version int, seq uint64, sealed [][]byte) ([]entryRecord, error) {
+	entries := make([]entryRecord, 0, len(sealed))
+	for i, ciphertext := range sealed {
+		var ad []byte
+		if version >= boundSchemaVersion {
+			ad = snapshotAD(seq, i, len(sealed))
+		} else {
+			s.unboundFound = true
+		}
+		data, err := s.cipher.Open(ciphertext, ad)
+		if err != nil {
+			return nil, openError(ad)
+		}
+		var rec entryRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot entry: %v", err)
+		}
+		entries = append(entries, rec)
+	}
+	return entries, nil
+}
+
+// replayJournal 重放日志中快照之后的记录，并截断末尾不完整的写入
+func (s *FileStore) replayJournal() error {
+	f, err := os.OpenFile(s.journalPath(), os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %v", err)
+	}
+	defer f.Close()
+
+	snapshotSeq := s.seq
+	version := SchemaVersion
+	encrypted := false
+	var next uint64 // 下一条记录应有的序号，由日志头给出
+	var good int64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %v", err)
+		}
+
+		data, ok := parseLine(line)
+		if !ok {
+			break
+		}
+		var ad []byte
+		if version >= boundSchemaVersion {
+			ad = journalAD(next)
+		}
+		rec, err := s.decodeRecord(data, ad)
+		if err == errCorruptRecord {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		good += int64(len(line))
+
+		if rec.Op == opHeader {
+			if err := checkVersion(rec.Version); err != nil {
+				return err
+			}
+			version, encrypted, next = rec.Version, rec.Encrypted, rec.Seq+1
+			continue
+		}
+		next = rec.Seq + 1
+		if s.cipher != nil && encrypted {
+			// 加密日志中混入的明文记录，以及快照之后不连续的记录（日志头被改动或快照被回滚）都是篡改
+			if data[0] == '{' || rec.Seq > snapshotSeq && rec.Seq != s.seq+1 {
+				return ErrTampered
+			}
+		}
+		if rec.Seq <= snapshotSeq {
+			continue
+		}
+		if rec.Entry != nil {
+			migrated := migrateEntries(version, []entryRecord{*rec.Entry})
+			rec.Entry = &migrated[0]
+		}
+		s.apply(rec)
+		s.seq = rec.Seq
+		s.pending++
+	}
+
+	// 丢弃崩溃时写了一半的记录
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > good {
+		if err := f.Truncate(good); err != nil {
+			return fmt.Errorf("failed to truncate journal: %v", err)
+		}
+	}
+	return nil
+}
+
+// journalAD 日志记录密文绑定的附加数据
+func journalAD(seq uint64) []byte {
+	return []byte("journal:" + strconv.FormatUint(seq, 10))
+}
+
+// snapshotAD 快照条目密文绑定的附加数据
+func snapshotAD(seq uint64, index, count int) []byte {
+	return []byte(fmt.Sprintf("snapshot:%d:%d/%d", seq, index, count))
+}
+
+// openError 解密失败时返回的错误：口令已由口令文件校验，绑定位置的密文无法解密说明数据被篡改；
+// 旧版本密文无法区分两者，沿用口令错误
+func openError(ad []byte) error {
+	if ad != nil {
+		return ErrTampered
+	}
+	return ErrWrongPassphrase
+}
+
+// errCorruptRecord 记录内容损坏，视为崩溃时的不完整写入
+var errCorruptRecord = errors.New("corrupt journal record")
+
+// parseLine 校验一行日志的 CRC，返回其中的数据部分
+func parseLine(line []byte) ([]byte, bool) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	sum, data, found := bytes.Cut(line, []byte(" "))
+	if !found {
+		return nil, false
+	}
+	expected, err := strconv.ParseUint(string(sum), 16, 32)
+	if err != nil || uint32(expected) != crc32.ChecksumIEEE(data) {
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeRecord 解码（必要时解密）一条日志记录，ad 为密文绑定的附加数据，旧版本的密文为 nil
+func (s *FileStore) decodeRecord(data, ad []byte) (journalRecord, error) {
+	var rec journalRecord
+	encrypted := len(data) > 0 && data[0] != '{'
+	if encrypted {
+		if s.cipher == nil {
+			return rec, ErrLocked
+		}
+		sealed, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return rec, errCorruptRecord
+		}
+		if data, err = s.cipher.Open(sealed, ad); err != nil {
+			return rec, openError(ad)
+		}
+		if ad == nil {
+			s.unboundFound = true
+		}
+	}
+
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, errCorruptRecord
+	}
+	if (rec.Op == opPut || rec.Op == opUpdate || rec.Op == opDelete) && rec.Entry == nil {
+		return rec, errCorruptRecord
+	}
+	if s.cipher != nil && !encrypted && rec.Op != opHeader {
+		s.plaintextFound = true
+	}
+	return rec, nil
+}
+
+// checkVersion 拒绝由更新版本程序写入的数据
+func checkVersion(version int) error {
+	if version > SchemaVersion {
+		return fmt.Errorf("unsupported storage schema version %d (max %d)", version, SchemaVersion)
+	}
+	return nil
+}
+
+// migrateEntries 将旧版本格式的条目升级到当前版本
+func migrateEntries(version int, entries []entryRecord) []entryRecord {
+	// 版本 1 到 2 只新增了 ID，由 assignIDs 在加载完成后统一补充，
+	// 以保证同一内容的多条日志记录对应同一个条目。新增版本时在此按顺序补充迁移步骤
+	return entries
+}
+
+// writeFileAtomic 通过临时文件加重命名的方式原子写入文件
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir 刷新目录元数据，确保重命名落盘（Windows 下不支持，忽略）
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (s *FileStore) snapshotPath() string {
+	return filepath.Join(s.dir, snapshotFileName)
+}
+
+func (s *FileStore) journalPath() string {
+	return filepath.Join(s.dir, journalFileName)
+}
+
+// newEntryRecord 从剪贴板条目创建磁盘记录
+func newEntryRecord(entry clipboard.ClipboardEntry) entryRecord {
+	return entryRecord{
+		ID:        entry.ID,
+		Content:   entry.Content,
+		Formats:   entry.Formats,
+		Timestamp: entry.Timestamp,
+		Pinned:    entry.Pinned,
+		PinOrder:  entry.PinOrder,
+		Tags:      entry.Tags,
+		SourceApp: entry.SourceApp,
+	}
+}
+
+// toEntry 转换为剪贴板条目
+func (r entryRecord) toEntry() clipboard.ClipboardEntry {
+	return clipboard.ClipboardEntry{
+		ID:        r.ID,
+		Content:   r.Content,
+		Formats:   r.Formats,
+		Timestamp: r.Timestamp,
+		Pinned:    r.Pinned,
+		PinOrder:  r.PinOrder,
+		Tags:      r.Tags,
+		SourceApp: r.SourceApp,
+	}
+}
+
+// sameEntry 判断两条记录是否对应同一个条目：都有 ID 时比较 ID，
+// 否则（版本 1 的记录）按与 Monitor 去重规则一致的内容键比较
+func (r entryRecord) sameEntry(other entryRecord) bool {
+	if r.ID != "" && other.ID != "" {
+		return r.ID == other.ID
+	}
+	return r.toEntry().Key() == other.toEntry().Key()
+}
+
+// size 内容和格式数据的总字节数
+func (r entryRecord) size() int {
+	size := len(r.Content)
+	for _, data := range r.Formats {
+		size += len(data)
+	}
+	return size
+}
+
+// MarshalJSON 较大的记录将内容和格式数据以 gzip 压缩后保存在 packed 中，压缩后没有变小时保持原样
+func (r entryRecord) MarshalJSON() ([]byte, error) {
+	type plain entryRecord
+	if r.size() <= compressThreshold {
+		return json.Marshal(plain(r))
+	}
+
+	data, err := json.Marshal(packedData{Content: r.Content, Formats: r.Formats})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= r.size() {
+		return json.Marshal(plain(r))
+	}
+
+	packed := plain(r)
+	packed.Content, packed.Formats, packed.Packed = "", nil, buf.Bytes()
+	return json.Marshal(packed)
+}
+
+// UnmarshalJSON 解析记录，压缩保存的内容和格式数据会被解压
+func (r *entryRecord) UnmarshalJSON(data []byte) error {
+	type plain entryRecord
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	if len(r.Packed) == 0 {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(r.Packed))
+	if err != nil {
+		return fmt.Errorf("failed to decompress entry: %v", err)
+	}
+	var unpacked packedData
+	if err := json.NewDecoder(zr).Decode(&unpacked); err != nil {
+		return fmt.Errorf("failed to decompress entry: %v", err)
+	}
+	r.Content, r.Formats, r.Packed = unpacked.Content, unpacked.Formats, nil
+	return nil
+}
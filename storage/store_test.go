@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"bytes"
+	"clipboard-monitor/clipboard"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func entry(content string) clipboard.ClipboardEntry {
+	return clipboard.ClipboardEntry{
+		Content:   content,
+		Timestamp: time.Now().Round(0),
+	}
+}
+
+func contents(t *testing.T, s *FileStore) []string {
+	t.Helper()
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Content)
+	}
+	return result
+}
+
+func assertContents(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	s.Put(entry("a"))
+	s.Put(entry("b"))
+	s.Put(entry("c"))
+	s.Put(entry("a")) // 移动到顶部
+	s.Delete(entry("b"))
+
+	// 不关闭直接重新打开，模拟进程崩溃
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	assertContents(t, contents(t, reopened), "a", "c")
+
+	reopened.Clear()
+	reopened.Put(entry("d"))
+	if err := reopened.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	final, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer final.Close()
+	assertContents(t, contents(t, final), "d")
+}
+
+func TestFileStoreCompaction(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.compactThreshold = 3
+
+	for _, c := range []string{"1", "2", "3", "4"} {
+		if err := s.Put(entry(c)); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, snapshotFileName)); err != nil {
+		t.Fatalf("Expected snapshot after compaction: %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	assertContents(t, contents(t, reopened), "4", "3", "2", "1")
+}
+
+func TestFileStoreTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.Put(entry("kept"))
+	s.journal.WriteString(`0badc0de {"seq":2,"op":"put","entry":{"cont`)
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	assertContents(t, contents(t, reopened), "kept")
+
+	// 截断后可以继续正常写入
+	reopened.Put(entry("next"))
+	again, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer again.Close()
+	assertContents(t, contents(t, again), "next", "kept")
+}
+
+func TestFileStoreRejectsNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(`{"version":99,"seq":0,"entries":[]}`)
+	if err := os.WriteFile(filepath.Join(dir, snapshotFileName), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(dir); err == nil {
+		t.Error("Expected error for newer schema version")
+	}
+}
+
+func TestMonitorWithFileStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.Put(entry("old 2"))
+	s.Put(entry("old 1"))
+
+	monitor := clipboard.NewMonitor(2, clipboard.WithStore(s))
+	history := monitor.GetHistory()
+	if len(history) != 2 || history[0].Content != "old 1" {
+		t.Fatalf("Expected history loaded from store, got %+v", history)
+	}
+
+	if err := monitor.DeleteEntry(history[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	assertContents(t, contents(t, s), "old 2")
+
+	monitor.ClearHistory()
+	assertContents(t, contents(t, s))
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("CLIPBOARD_MONITOR_DATA_DIR", "")
+	t.Setenv("XDG_DATA_HOME", "/tmp/xdg")
+
+	dir, err := DefaultDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join("/tmp/xdg", appDirName) {
+		t.Errorf("Unexpected data dir: %s", dir)
+	}
+}
+
+func TestFileStoreRichFormats(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := clipboard.NewEntry(map[string][]byte{clipboard.MIMEPNG: {0x89, 'P', 'N', 'G'}})
+	image.Timestamp = time.Now()
+	image.SourceApp = "gimp"
+	s.Put(image)
+	s.Close()
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	entries, _ := reopened.Load()
+	if len(entries) != 1 || string(entries[0].Formats[clipboard.MIMEPNG]) != "\x89PNG" || entries[0].SourceApp != "gimp" {
+		t.Fatalf("Expected image entry to round trip, got %+v", entries)
+	}
+}
+
+func TestFileStoreCompressesLargeEntries(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := strings.Repeat("2024-01-01 12:00:00 INFO request handled\n", 5000)
+	large := entry(log)
+	large.Formats = map[string][]byte{clipboard.MIMEHTML: []byte("<pre>" + log + "</pre>")}
+	noise := make([]byte, compressThreshold+1)
+	rand.New(rand.NewSource(1)).Read(noise)
+	image := clipboard.NewEntry(map[string][]byte{clipboard.MIMEPNG: noise})
+	image.Timestamp = time.Now()
+	s.Put(entry("small"))
+	s.Put(image)
+	s.Put(large)
+
+	info, err := os.Stat(filepath.Join(dir, journalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > int64(len(log)) {
+		t.Errorf("Expected large entry to be compressed, journal is %d bytes", info.Size())
+	}
+
+	// 日志和快照都能还原压缩的内容，无法压缩的数据原样保存
+	for _, compact := range []bool{false, true} {
+		if compact {
+			if err := s.Compact(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s.Close()
+		if s, err = Open(dir); err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := s.Load()
+		if len(entries) != 3 || entries[0].Content != log || !bytes.Equal(entries[0].Formats[clipboard.MIMEHTML], large.Formats[clipboard.MIMEHTML]) {
+			t.Fatalf("Expected compressed entry to round trip (compact=%v)", compact)
+		}
+		if !bytes.Equal(entries[1].Formats[clipboard.MIMEPNG], noise) || entries[2].Content != "small" {
+			t.Fatalf("Expected uncompressed entries to round trip (compact=%v)", compact)
+		}
+	}
+	s.Close()
+}
+
+func TestFileStoreUpdateKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := entry("a")
+	a.ID = "id-a"
+	b := entry("b")
+	b.ID = "id-b"
+	s.Put(a)
+	s.Put(b)
+
+	a.Content = "a edited"
+	if err := s.Update(a); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	assertContents(t, contents(t, reopened), "b", "a edited")
+
+	// 内容相同但 ID 不同的条目互不影响
+	other := entry("b")
+	other.ID = "id-other"
+	reopened.Delete(other)
+	assertContents(t, contents(t, reopened), "b", "a edited")
+}
+
+func TestFileStoreMigratesV1(t *testing.T) {
+	dir := t.TempDir()
+	journal := []string{
+		`{"op":"header","version":1}`,
+		`{"seq":1,"op":"put","entry":{"content":"a","timestamp":"2024-01-01T00:00:00Z"}}`,
+		`{"seq":2,"op":"put","entry":{"content":"b","timestamp":"2024-01-01T00:00:01Z"}}`,
+		`{"seq":3,"op":"delete","entry":{"content":"a","timestamp":"2024-01-01T00:00:00Z"}}`,
+		`{"seq":4,"op":"put","entry":{"content":"c","timestamp":"2024-01-01T00:00:02Z"}}`,
+	}
+	var data []byte
+	for _, line := range journal {
+		data = append(data, fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE([]byte(line)), line)...)
+	}
+	if err := os.WriteFile(filepath.Join(dir, journalFileName), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	assertContents(t, contents(t, s), "c", "b")
+	entries, _ := s.Load()
+	if entries[0].ID == "" || entries[1].ID == "" || entries[0].ID == entries[1].ID {
+		t.Fatalf("Expected migrated entries to get distinct IDs, got %+v", entries)
+	}
+
+	// 补充的 ID 已经写回磁盘，重新打开后保持不变
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	again, _ := reopened.Load()
+	if again[0].ID != entries[0].ID || again[1].ID != entries[1].ID {
+		t.Errorf("Expected IDs to persist, got %+v then %+v", entries, again)
+	}
+}
+
+func TestFileStorePinnedEntries(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"a", "b", "c"} {
+		s.Put(entry(content))
+	}
+
+	monitor := clipboard.NewMonitor(10, clipboard.WithStore(s))
+	for _, item := range monitor.GetHistory() {
+		if item.Content != "b" {
+			if _, err := monitor.Pin(item.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	monitor.ClearHistory()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	reloaded := clipboard.NewMonitor(10, clipboard.WithStore(reopened))
+	var got []string
+	for _, item := range reloaded.GetHistory() {
+		if !item.Pinned {
+			t.Errorf("Expected %q to be pinned", item.Content)
+		}
+		got = append(got, item.Content)
+	}
+	assertContents(t, got, "c", "a")
+}
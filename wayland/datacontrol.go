@@ -0,0 +1,470 @@
+//go:build linux
+
+package wayland
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// 协议接口名称
+const (
+	seatInterface       = "wl_seat"
+	extManagerInterface = "ext_data_control_manager_v1"
+	wlrManagerInterface = "zwlr_data_control_manager_v1"
+)
+
+// wl_display
+const (
+	displayID            = 1
+	displaySync          = 0
+	displayGetRegistry   = 1
+	displayEventError    = 0
+	displayEventDeleteID = 1
+)
+
+// wl_registry
+const (
+	registryBind              = 0
+	registryEventGlobal       = 0
+	registryEventGlobalRemove = 1
+)
+
+// wl_callback
+const callbackEventDone = 0
+
+// ext_data_control_manager_v1 / zwlr_data_control_manager_v1（两者操作码一致）
+const (
+	managerCreateDataSource = 0
+	managerGetDataDevice    = 1
+)
+
+// data control device
+const (
+	deviceSetSelection          = 0
+	deviceEventDataOffer        = 0
+	deviceEventSelection        = 1
+	deviceEventFinished         = 2
+	deviceEventPrimarySelection = 3
+)
+
+// data control offer
+const (
+	offerReceive    = 0
+	offerDestroy    = 1
+	offerEventOffer = 0
+)
+
+// data control source
+const (
+	sourceOffer          = 0
+	sourceDestroy        = 1
+	sourceEventSend      = 0
+	sourceEventCancelled = 1
+)
+
+// ErrUnsupported 合成器不支持 data-control 协议（例如 GNOME）
+var ErrUnsupported = errors.New("compositor does not support the data-control protocol")
+
+// roundtripTimeout 等待合成器响应的超时时间
+const roundtripTimeout = 5 * time.Second
+
+// global 注册表中的全局对象
+type global struct {
+	name    uint32
+	iface   string
+	version uint32
+}
+
+// Clipboard 基于 data-control 协议的剪贴板客户端，无需窗口焦点即可读写和监听选区
+type Clipboard struct {
+	conn *Conn
+
+	mu        sync.Mutex
+	handlers  map[uint32]func(*Event)
+	offers    map[uint32][]string // offer ID -> 提供的 MIME 类型
+	receiving map[uint32]int      // offer ID -> 正在读取该 offer 的 Receive 调用数
+	selection uint32              // 当前选区的 offer，0 表示为空
+	manager   uint32
+	device    uint32
+	source    uint32
+
+	changed chan struct{}
+	done    chan struct{}
+	err     error
+}
+
+// Connect 连接合成器并获取 data-control 设备，display 为空时使用 WAYLAND_DISPLAY
+func Connect(display string) (*Clipboard, error) {
+	conn, err := Dial(display)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewClipboard(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClipboard 在已建立的连接上初始化 data-control 客户端
+func NewClipboard(conn *Conn) (*Clipboard, error) {
+	c := &Clipboard{
+		conn:      conn,
+		handlers:  make(map[uint32]func(*Event)),
+		offers:    make(map[uint32][]string),
+		receiving: make(map[uint32]int),
+		changed:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	c.handlers[displayID] = c.handleDisplay
+	go c.dispatch()
+
+	// 枚举全局对象
+	var globals []global
+	registry := conn.NewID()
+	c.setHandler(registry, func(ev *Event) {
+		if ev.Opcode == registryEventGlobal {
+			g := global{name: ev.Uint(), iface: ev.String(), version: ev.Uint()}
+			globals = append(globals, g)
+		}
+	})
+	if err := conn.SendRequest(displayID, displayGetRegistry, Uint(registry)); err != nil {
+		return nil, err
+	}
+	if err := c.Roundtrip(); err != nil {
+		return nil, err
+	}
+
+	seat, manager := findGlobal(globals, seatInterface), findGlobal(globals, extManagerInterface)
+	if manager == nil {
+		manager = findGlobal(globals, wlrManagerInterface)
+	}
+	if seat == nil || manager == nil {
+		return nil, ErrUnsupported
+	}
+
+	seatID, err := c.bind(registry, seat, 1)
+	if err != nil {
+		return nil, err
+	}
+	c.manager, err = c.bind(registry, manager, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	c.device = conn.NewID()
+	c.setHandler(c.device, c.handleDevice)
+	if err := conn.SendRequest(c.manager, managerGetDataDevice, Uint(c.device), Uint(seatID)); err != nil {
+		return nil, err
+	}
+
+	// 等待初始选区事件
+	if err := c.Roundtrip(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// findGlobal 查找指定接口的全局对象
+func findGlobal(globals []global, iface string) *global {
+	for i := range globals {
+		if globals[i].iface == iface {
+			return &globals[i]
+		}
+	}
+	return nil
+}
+
+// bind 绑定全局对象，返回客户端对象 ID
+func (c *Clipboard) bind(registry uint32, g *global, version uint32) (uint32, error) {
+	if g.version < version {
+		version = g.version
+	}
+	id := c.conn.NewID()
+	err := c.conn.SendRequest(registry, registryBind, Uint(g.name), String(g.iface), Uint(version), Uint(id))
+	return id, err
+}
+
+// Roundtrip 等待合成器处理完此前发送的全部请求
+func (c *Clipboard) Roundtrip() error {
+	done := make(chan struct{})
+	callback := c.conn.NewID()
+	c.setHandler(callback, func(ev *Event) {
+		if ev.Opcode == callbackEventDone {
+			close(done)
+		}
+	})
+	if err := c.conn.SendRequest(displayID, displaySync, Uint(callback)); err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-c.done:
+		return c.Err()
+	case <-time.After(roundtripTimeout):
+		return errors.New("wayland roundtrip timed out")
+	}
+}
+
+// Changed 选区发生变化时收到通知
+func (c *Clipboard) Changed() <-chan struct{} {
+	return c.changed
+}
+
+// Done 连接断开时关闭
+func (c *Clipboard) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err 返回导致连接断开的错误
+func (c *Clipboard) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// MimeTypes 返回当前选区提供的 MIME 类型
+func (c *Clipboard) MimeTypes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.selection == 0 {
+		return nil
+	}
+	return append([]string(nil), c.offers[c.selection]...)
+}
+
+// Receive 读取当前选区指定 MIME 类型的数据，选区为空时返回 nil
+//
+// 读取期间 offer 被计数占用：选区此时被取代的话，
+// destroy 推迟到读取结束之后，避免向已销毁的 offer 发送 receive。
+func (c *Clipboard) Receive(mime string) ([]byte, error) {
+	c.mu.Lock()
+	offer := c.selection
+	if offer != 0 {
+		c.receiving[offer]++
+	}
+	c.mu.Unlock()
+	if offer == 0 {
+		return nil, nil
+	}
+
+	defer c.releaseOffer(offer)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	err = c.conn.SendRequest(offer, offerReceive, String(mime), FD(w.Fd()))
+	w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.SetReadDeadline(time.Now().Add(roundtripTimeout))
+	return io.ReadAll(r)
+}
+
+// SetSelection 以多种 MIME 类型提供数据并设为当前选区
+//
+// 之前的数据源由合成器发送 cancelled 后在 handleSource 中销毁，
+// 这里不能提前销毁，否则会对已销毁的对象再次发送 destroy 而导致协议错误。
+func (c *Clipboard) SetSelection(formats map[string][]byte) error {
+	source := c.conn.NewID()
+	data := make(map[string][]byte, len(formats))
+	for mime, payload := range formats {
+		data[mime] = payload
+	}
+
+	c.setHandler(source, func(ev *Event) {
+		c.handleSource(source, data, ev)
+	})
+	if err := c.conn.SendRequest(c.manager, managerCreateDataSource, Uint(source)); err != nil {
+		return err
+	}
+	for mime := range data {
+		if err := c.conn.SendRequest(source, sourceOffer, String(mime)); err != nil {
+			return err
+		}
+	}
+	if err := c.conn.SendRequest(c.device, deviceSetSelection, Uint(source)); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.source = source
+	c.mu.Unlock()
+	return nil
+}
+
+// Close 断开连接
+func (c *Clipboard) Close() error {
+	return c.conn.Close()
+}
+
+// dispatch 事件循环
+func (c *Clipboard) dispatch() {
+	for {
+		ev, err := c.conn.ReadEvent()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+
+		c.mu.Lock()
+		handler := c.handlers[ev.Sender]
+		c.mu.Unlock()
+		if handler != nil {
+			handler(ev)
+		}
+	}
+}
+
+// fail 记录错误并关闭 done
+func (c *Clipboard) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	c.err = err
+	close(c.done)
+}
+
+func (c *Clipboard) setHandler(id uint32, handler func(*Event)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[id] = handler
+}
+
+// handleDisplay 处理 wl_display 事件
+func (c *Clipboard) handleDisplay(ev *Event) {
+	switch ev.Opcode {
+	case displayEventError:
+		object, code, message := ev.Uint(), ev.Uint(), ev.String()
+		c.fail(fmt.Errorf("wayland error on object %d (code %d): %s", object, code, message))
+		c.conn.Close()
+	case displayEventDeleteID:
+		id := ev.Uint()
+		c.mu.Lock()
+		delete(c.handlers, id)
+		c.mu.Unlock()
+	}
+}
+
+// handleDevice 处理 data-control 设备事件
+func (c *Clipboard) handleDevice(ev *Event) {
+	switch ev.Opcode {
+	case deviceEventDataOffer:
+		offer := ev.Uint()
+		c.mu.Lock()
+		c.offers[offer] = nil
+		c.handlers[offer] = func(ev *Event) {
+			if ev.Opcode == offerEventOffer {
+				mime := ev.String()
+				c.mu.Lock()
+				c.offers[offer] = append(c.offers[offer], mime)
+				c.mu.Unlock()
+			}
+		}
+		c.mu.Unlock()
+
+	case deviceEventSelection:
+		offer := ev.Uint()
+		c.mu.Lock()
+		previous := c.selection
+		c.selection = offer
+		c.mu.Unlock()
+		if previous != 0 && previous != offer {
+			c.destroyOffer(previous)
+		}
+		select {
+		case c.changed <- struct{}{}:
+		default:
+		}
+
+	case deviceEventPrimarySelection:
+		// 只关注剪贴板选区，PRIMARY 的 offer 直接释放
+		if offer := ev.Uint(); offer != 0 {
+			c.mu.Lock()
+			current := c.selection
+			c.mu.Unlock()
+			if offer != current {
+				c.destroyOffer(offer)
+			}
+		}
+
+	case deviceEventFinished:
+		c.fail(errors.New("data control device finished"))
+	}
+}
+
+// destroyOffer 释放不再使用的 offer，有 receive 正在发送时由 releaseOffer 负责销毁
+func (c *Clipboard) destroyOffer(offer uint32) {
+	c.mu.Lock()
+	delete(c.offers, offer)
+	pending := c.receiving[offer] > 0
+	if !pending {
+		delete(c.handlers, offer)
+	}
+	c.mu.Unlock()
+	if !pending {
+		c.conn.SendRequest(offer, offerDestroy)
+	}
+}
+
+// releaseOffer 结束 Receive 对 offer 的占用，offer 在此期间被取代的话由最后一个占用者销毁
+func (c *Clipboard) releaseOffer(offer uint32) {
+	c.mu.Lock()
+	c.receiving[offer]--
+	_, alive := c.offers[offer]
+	last := c.receiving[offer] == 0
+	if last {
+		delete(c.receiving, offer)
+		if !alive {
+			delete(c.handlers, offer)
+		}
+	}
+	c.mu.Unlock()
+	if last && !alive {
+		c.conn.SendRequest(offer, offerDestroy)
+	}
+}
+
+// handleSource 处理数据源事件：向请求方写入数据或在被取代时销毁，数据源只在这里销毁
+func (c *Clipboard) handleSource(source uint32, data map[string][]byte, ev *Event) {
+	switch ev.Opcode {
+	case sourceEventSend:
+		mime := ev.String()
+		f, err := ev.FD()
+		if err != nil {
+			return
+		}
+		payload := data[mime]
+		// 在独立 goroutine 中写入，避免对方读取缓慢时阻塞事件循环
+		go func() {
+			defer f.Close()
+			f.Write(payload)
+		}()
+
+	case sourceEventCancelled:
+		c.mu.Lock()
+		if c.source == source {
+			c.source = 0
+		}
+		delete(c.handlers, source)
+		c.mu.Unlock()
+		c.conn.SendRequest(source, sourceDestroy)
+	}
+}
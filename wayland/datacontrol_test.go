@@ -0,0 +1,395 @@
+//go:build linux
+
+package wayland
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeCompositor 在套接字另一端模拟支持 ext-data-control 的合成器
+type fakeCompositor struct {
+	t        *testing.T
+	conn     *Conn
+	device   uint32
+	offerID  uint32
+	received chan []byte   // 客户端数据源发送的数据
+	pending  chan *os.File // 非空时 receive 的管道交给测试写入，而不是直接写入文本
+
+	mu     sync.Mutex
+	offers map[uint32]bool // 发送过的 offer -> 是否尚未销毁
+}
+
+// announce 模拟其他程序复制：发送新的 offer 并设为当前选区
+func (f *fakeCompositor) announce(offer uint32) {
+	f.mu.Lock()
+	if f.offers == nil {
+		f.offers = make(map[uint32]bool)
+	}
+	f.offers[offer] = true
+	f.mu.Unlock()
+	f.conn.SendRequest(f.device, deviceEventDataOffer, Uint(offer))
+	f.conn.SendRequest(offer, offerEventOffer, String("text/plain;charset=utf-8"))
+	f.conn.SendRequest(f.device, deviceEventSelection, Uint(offer))
+}
+
+// isOffer 对象是否为发送过的 offer（offer 与数据源的请求操作码相同，需要按对象区分）
+func (f *fakeCompositor) isOffer(id uint32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.offers[id]
+	return ok
+}
+
+// offerAlive offer 是否尚未销毁
+func (f *fakeCompositor) offerAlive(id uint32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.offers[id]
+}
+
+// protocolError 模拟合成器对无效对象的请求：发送错误并断开连接
+func (f *fakeCompositor) protocolError(object uint32) {
+	f.conn.SendRequest(displayID, displayEventError, Uint(object), Uint(0), String("invalid object"))
+	f.conn.Close()
+}
+
+func newConnPair(t *testing.T) (*Conn, *Conn) {
+	t.Helper()
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toConn := func(fd int) *Conn {
+		f := os.NewFile(uintptr(fd), "socketpair")
+		defer f.Close()
+		c, err := net.FileConn(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewConn(c.(*net.UnixConn))
+	}
+	return toConn(fds[0]), toConn(fds[1])
+}
+
+// serve 处理客户端请求（请求与事件的线路格式相同，可以复用 ReadEvent/SendRequest）
+//
+// 与真实的合成器一样，新的选区取代旧的数据源时向旧数据源发送 cancelled，
+// 对已销毁的数据源或 offer 发送请求会产生协议错误并断开连接。
+func (f *fakeCompositor) serve(text string) {
+	var registry, manager, source, selected uint32
+	offerID := uint32(0xff000001)
+	sources := make(map[uint32]bool) // 创建过的数据源 -> 是否尚未销毁
+	for {
+		req, err := f.conn.ReadEvent()
+		if err != nil {
+			return
+		}
+		switch {
+		case req.Sender == displayID && req.Opcode == displayGetRegistry:
+			registry = req.Uint()
+			f.conn.SendRequest(registry, registryEventGlobal, Uint(1), String(seatInterface), Uint(7))
+			f.conn.SendRequest(registry, registryEventGlobal, Uint(2), String(extManagerInterface), Uint(1))
+		case req.Sender == displayID && req.Opcode == displaySync:
+			callback := req.Uint()
+			f.conn.SendRequest(callback, callbackEventDone, Uint(0))
+			f.conn.SendRequest(displayID, displayEventDeleteID, Uint(callback))
+		case req.Sender == registry && req.Opcode == registryBind:
+			name, _, _, id := req.Uint(), req.String(), req.Uint(), req.Uint()
+			if name == 2 {
+				manager = id
+			}
+		case req.Sender == manager && req.Opcode == managerGetDataDevice:
+			f.device = req.Uint()
+			// 初始选区：提供一段文本
+			f.announce(offerID)
+		case req.Opcode == offerReceive && f.isOffer(req.Sender):
+			_ = req.String() // MIME 类型
+			w, err := req.FD()
+			if err != nil {
+				f.t.Error(err)
+				continue
+			}
+			if !f.offerAlive(req.Sender) {
+				f.protocolError(req.Sender)
+				return
+			}
+			if f.pending != nil {
+				f.pending <- w
+				continue
+			}
+			w.Write([]byte(text))
+			w.Close()
+		case req.Opcode == offerDestroy && f.isOffer(req.Sender):
+			if !f.offerAlive(req.Sender) {
+				f.protocolError(req.Sender)
+				return
+			}
+			f.mu.Lock()
+			f.offers[req.Sender] = false
+			f.mu.Unlock()
+			f.conn.SendRequest(displayID, displayEventDeleteID, Uint(req.Sender))
+		case req.Sender == manager && req.Opcode == managerCreateDataSource:
+			source = req.Uint()
+			sources[source] = true
+		case req.Opcode == sourceDestroy && sources[req.Sender]:
+			sources[req.Sender] = false
+			f.conn.SendRequest(displayID, displayEventDeleteID, Uint(req.Sender))
+		case req.Opcode == sourceDestroy:
+			if _, ok := sources[req.Sender]; ok {
+				// 再次销毁已销毁的数据源
+				f.protocolError(req.Sender)
+				return
+			}
+		case req.Sender == f.device && req.Opcode == deviceSetSelection:
+			if selected != 0 && selected != source && sources[selected] {
+				f.conn.SendRequest(selected, sourceEventCancelled)
+			}
+			selected = source
+			if f.received == nil {
+				continue
+			}
+			// 模拟其他程序向数据源请求数据
+			r, w, _ := os.Pipe()
+			f.conn.SendRequest(source, sourceEventSend, String("text/html"), FD(w.Fd()))
+			w.Close()
+			go func() {
+				data, _ := io.ReadAll(r)
+				r.Close()
+				f.received <- data
+			}()
+		}
+	}
+}
+
+func TestClipboardWithFakeCompositor(t *testing.T) {
+	client, server := newConnPair(t)
+	defer server.Close()
+
+	f := &fakeCompositor{t: t, conn: server, received: make(chan []byte, 1)}
+	go f.serve("你好 wayland")
+
+	c, err := NewClipboard(client)
+	if err != nil {
+		t.Fatalf("NewClipboard failed: %v", err)
+	}
+	defer c.Close()
+
+	select {
+	case <-c.Changed():
+	case <-time.After(time.Second):
+		t.Fatal("expected selection change notification")
+	}
+
+	types := c.MimeTypes()
+	if len(types) != 1 || types[0] != "text/plain;charset=utf-8" {
+		t.Fatalf("Unexpected mime types: %v", types)
+	}
+	data, err := c.Receive(types[0])
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if string(data) != "你好 wayland" {
+		t.Errorf("Expected received text, got %q", data)
+	}
+
+	if err := c.SetSelection(map[string][]byte{"text/html": []byte("<b>hi</b>")}); err != nil {
+		t.Fatalf("SetSelection failed: %v", err)
+	}
+	select {
+	case got := <-f.received:
+		if string(got) != "<b>hi</b>" {
+			t.Errorf("Expected source data to be sent, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("source data was not sent")
+	}
+}
+
+func TestSetSelectionTwiceKeepsConnection(t *testing.T) {
+	client, server := newConnPair(t)
+	defer server.Close()
+
+	f := &fakeCompositor{t: t, conn: server}
+	go f.serve("initial")
+
+	c, err := NewClipboard(client)
+	if err != nil {
+		t.Fatalf("NewClipboard failed: %v", err)
+	}
+	defer c.Close()
+
+	for _, text := range []string{"first", "second", "third"} {
+		if err := c.SetSelection(map[string][]byte{"text/plain": []byte(text)}); err != nil {
+			t.Fatalf("SetSelection %q failed: %v", text, err)
+		}
+		// 等待合成器处理 cancelled 和随后的 destroy
+		if err := c.Roundtrip(); err != nil {
+			t.Fatalf("Connection broken after setting %q: %v", text, err)
+		}
+	}
+	if err := c.Roundtrip(); err != nil || c.Err() != nil {
+		t.Fatalf("Expected connection to stay usable, got %v / %v", err, c.Err())
+	}
+	if data, err := c.Receive("text/plain;charset=utf-8"); err != nil || string(data) != "initial" {
+		t.Errorf("Expected to still receive the selection, got %q (%v)", data, err)
+	}
+}
+
+func TestReceiveWhileSelectionChanges(t *testing.T) {
+	client, server := newConnPair(t)
+	defer server.Close()
+
+	f := &fakeCompositor{t: t, conn: server, pending: make(chan *os.File, 1)}
+	go f.serve("text")
+
+	c, err := NewClipboard(client)
+	if err != nil {
+		t.Fatalf("NewClipboard failed: %v", err)
+	}
+	defer c.Close()
+
+	// 读取期间选区被取代：旧的 offer 要等读取结束后才能销毁
+	result := make(chan []byte, 1)
+	go func() {
+		data, err := c.Receive("text/plain;charset=utf-8")
+		if err != nil {
+			t.Error(err)
+		}
+		result <- data
+	}()
+	w := <-f.pending
+	f.announce(0xff000002)
+	// 第一次往返确保客户端已处理新的选区，第二次确保合成器已处理随之发出的请求
+	for i := 0; i < 2; i++ {
+		if err := c.Roundtrip(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !f.offerAlive(0xff000001) {
+		t.Fatal("Offer destroyed while a receive is in progress")
+	}
+
+	w.Write([]byte("text"))
+	w.Close()
+	if data := <-result; string(data) != "text" {
+		t.Errorf("Expected text, got %q", data)
+	}
+	if err := c.Roundtrip(); err != nil || c.Err() != nil {
+		t.Fatalf("Expected connection to stay usable, got %v / %v", err, c.Err())
+	}
+	if f.offerAlive(0xff000001) {
+		t.Error("Expected replaced offer to be destroyed after the receive")
+	}
+}
+
+func TestClipboardUnsupportedCompositor(t *testing.T) {
+	client, server := newConnPair(t)
+	defer server.Close()
+	defer client.Close()
+
+	go func() {
+		for {
+			req, err := server.ReadEvent()
+			if err != nil {
+				return
+			}
+			if req.Sender == displayID && req.Opcode == displaySync {
+				server.SendRequest(req.Uint(), callbackEventDone, Uint(0))
+			}
+		}
+	}()
+
+	if _, err := NewClipboard(client); err != ErrUnsupported {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	path, err := SocketPath("wayland-1")
+	if err != nil || path != "/run/user/1000/wayland-1" {
+		t.Errorf("Unexpected socket path %q (%v)", path, err)
+	}
+	if path, _ := SocketPath("/tmp/custom"); path != "/tmp/custom" {
+		t.Errorf("Absolute display should be used as is, got %q", path)
+	}
+}
+
+// startHeadlessSway 启动无头 sway 合成器并返回其 WAYLAND_DISPLAY，未安装时跳过测试
+func startHeadlessSway(t *testing.T) string {
+	t.Helper()
+	sway, err := exec.LookPath("sway")
+	if err != nil {
+		t.Skip("sway not installed")
+	}
+
+	runtimeDir := t.TempDir()
+	config := filepath.Join(runtimeDir, "config")
+	if err := os.WriteFile(config, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(sway, "--config", config)
+	cmd.Env = append(os.Environ(),
+		"XDG_RUNTIME_DIR="+runtimeDir,
+		"WLR_BACKENDS=headless",
+		"WLR_LIBINPUT_NO_DEVICES=1",
+		"WAYLAND_DISPLAY=",
+	)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start sway: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		matches, _ := filepath.Glob(filepath.Join(runtimeDir, "wayland-*"))
+		for _, m := range matches {
+			if filepath.Ext(m) != ".lock" {
+				return filepath.Base(m)
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("sway did not create a wayland socket")
+	return ""
+}
+
+func TestClipboardHeadlessSway(t *testing.T) {
+	display := startHeadlessSway(t)
+
+	c, err := Connect(display)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer c.Close()
+
+	const mime = "text/plain;charset=utf-8"
+	if err := c.SetSelection(map[string][]byte{mime: []byte("headless")}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case <-c.Changed():
+			data, err := c.Receive(mime)
+			if err == nil && string(data) == "headless" {
+				return
+			}
+		case <-deadline:
+			t.Fatal("selection was not echoed back by the compositor")
+		}
+	}
+}
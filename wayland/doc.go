@@ -0,0 +1,4 @@
+// Package wayland 实现了一个最小的纯 Go Wayland 客户端，
+// 仅支持读写剪贴板所需的 ext-data-control-v1 / wlr-data-control-unstable-v1 协议，
+// 以及通过 wl_keyboard 读取键盘映射。
+package wayland
@@ -0,0 +1,96 @@
+//go:build linux
+
+package wayland
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// wl_seat / wl_keyboard
+const (
+	seatGetKeyboard     = 1
+	keyboardEventKeymap = 0
+	keymapFormatXKBV1   = 1
+)
+
+// Keymap 连接合成器，读取座位键盘当前使用的 XKB 键盘映射（xkb_v1 文本格式）。
+// 虚拟键盘按物理键位注入，需要它把字符转换为当前布局下的键码。
+// display 为空时使用 WAYLAND_DISPLAY。
+func Keymap(display string) (string, error) {
+	conn, err := Dial(display)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return ReadKeymap(conn)
+}
+
+// ReadKeymap 在已建立的连接上绑定 wl_seat 并等待 wl_keyboard 的 keymap 事件
+func ReadKeymap(conn *Conn) (string, error) {
+	conn.sock.SetReadDeadline(time.Now().Add(roundtripTimeout))
+
+	registry, callback := conn.NewID(), conn.NewID()
+	if err := conn.SendRequest(displayID, displayGetRegistry, Uint(registry)); err != nil {
+		return "", err
+	}
+	if err := conn.SendRequest(displayID, displaySync, Uint(callback)); err != nil {
+		return "", err
+	}
+
+	var seat *global
+	keyboard := uint32(0)
+	for {
+		ev, err := conn.ReadEvent()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case ev.Sender == displayID && ev.Opcode == displayEventError:
+			object, code, message := ev.Uint(), ev.Uint(), ev.String()
+			return "", fmt.Errorf("wayland error on object %d (code %d): %s", object, code, message)
+
+		case ev.Sender == registry && ev.Opcode == registryEventGlobal:
+			g := global{name: ev.Uint(), iface: ev.String(), version: ev.Uint()}
+			if g.iface == seatInterface && seat == nil {
+				seat = &g
+			}
+
+		case ev.Sender == callback && ev.Opcode == callbackEventDone:
+			// 全局对象枚举完毕，绑定第一个座位并获取键盘
+			if seat == nil {
+				return "", errors.New("compositor has no seat")
+			}
+			seatID := conn.NewID()
+			keyboard = conn.NewID()
+			err := conn.SendRequest(registry, registryBind, Uint(seat.name), String(seat.iface), Uint(1), Uint(seatID))
+			if err == nil {
+				err = conn.SendRequest(seatID, seatGetKeyboard, Uint(keyboard))
+			}
+			if err != nil {
+				return "", err
+			}
+
+		case keyboard != 0 && ev.Sender == keyboard && ev.Opcode == keyboardEventKeymap:
+			format, size := ev.Uint(), ev.Uint()
+			f, err := ev.FD()
+			if err != nil {
+				return "", err
+			}
+			defer f.Close()
+			if format != keymapFormatXKBV1 {
+				return "", fmt.Errorf("unsupported keymap format %d", format)
+			}
+			// 新版本的座位要求以 MAP_PRIVATE 映射，不能直接读取
+			data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_PRIVATE)
+			if err != nil {
+				return "", fmt.Errorf("failed to map keymap: %v", err)
+			}
+			defer syscall.Munmap(data)
+			return strings.TrimRight(string(data), "\x00"), nil
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package wayland
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadKeymap(t *testing.T) {
+	client, server := newConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	const keymap = "xkb_keymap {\n\txkb_keycodes \"evdev\" { <AC01> = 38; };\n};\n"
+	f, err := os.CreateTemp(t.TempDir(), "keymap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString(keymap + "\x00")
+	fd := f.Fd()
+
+	// 合成器：列出一个座位，客户端获取键盘后发送键盘映射
+	go func() {
+		var registry, seat uint32
+		for {
+			req, err := server.ReadEvent()
+			if err != nil {
+				return
+			}
+			switch {
+			case req.Sender == displayID && req.Opcode == displayGetRegistry:
+				registry = req.Uint()
+				server.SendRequest(registry, registryEventGlobal, Uint(3), String(seatInterface), Uint(9))
+			case req.Sender == displayID && req.Opcode == displaySync:
+				server.SendRequest(req.Uint(), callbackEventDone, Uint(0))
+			case req.Sender == registry && req.Opcode == registryBind:
+				_, _, _, seat = req.Uint(), req.String(), req.Uint(), req.Uint()
+			case req.Sender == seat && req.Opcode == seatGetKeyboard:
+				keyboard := req.Uint()
+				server.SendRequest(keyboard, keyboardEventKeymap, Uint(keymapFormatXKBV1), FD(fd), Uint(len(keymap)+1))
+			}
+		}
+	}()
+
+	got, err := ReadKeymap(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != keymap {
+		t.Errorf("Unexpected keymap %q", got)
+	}
+}
+
+func TestReadKeymapWithoutSeat(t *testing.T) {
+	client, server := newConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		for {
+			req, err := server.ReadEvent()
+			if err != nil {
+				return
+			}
+			if req.Sender == displayID && req.Opcode == displaySync {
+				server.SendRequest(req.Uint(), callbackEventDone, Uint(0))
+			}
+		}
+	}()
+
+	if _, err := ReadKeymap(client); err == nil || !strings.Contains(err.Error(), "no seat") {
+		t.Errorf("Expected missing seat error, got %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+//go:build linux
+
+package wayland
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// maxMessageSize libwayland 限制的单条消息最大长度
+const maxMessageSize = 4096
+
+// byteOrder Wayland 协议使用主机字节序
+var byteOrder = binary.NativeEndian
+
+// Arg 请求参数
+type Arg interface {
+	encode(msg *outgoing)
+}
+
+// Uint 无符号整数参数（也用于 object 和 new_id）
+type Uint uint32
+
+// String 字符串参数
+type String string
+
+// FD 文件描述符参数，通过辅助数据发送
+type FD int
+
+func (a Uint) encode(msg *outgoing) {
+	msg.body = byteOrder.AppendUint32(msg.body, uint32(a))
+}
+
+func (a String) encode(msg *outgoing) {
+	msg.body = byteOrder.AppendUint32(msg.body, uint32(len(a)+1))
+	msg.body = append(msg.body, a...)
+	msg.body = append(msg.body, 0)
+	for len(msg.body)%4 != 0 {
+		msg.body = append(msg.body, 0)
+	}
+}
+
+func (a FD) encode(msg *outgoing) {
+	msg.fds = append(msg.fds, int(a))
+}
+
+// outgoing 待发送的消息
+type outgoing struct {
+	body []byte
+	fds  []int
+}
+
+// Event 收到的事件，参数按顺序通过 Uint/String/FD 读取
+type Event struct {
+	Sender uint32
+	Opcode uint16
+	data   []byte
+	conn   *Conn
+	err    error
+}
+
+// Uint 读取一个 uint/int/object/new_id 参数
+func (e *Event) Uint() uint32 {
+	if len(e.data) < 4 {
+		e.err = errors.New("event too short")
+		return 0
+	}
+	v := byteOrder.Uint32(e.data)
+	e.data = e.data[4:]
+	return v
+}
+
+// String 读取一个字符串参数
+func (e *Event) String() string {
+	n := int(e.Uint())
+	if n == 0 {
+		return ""
+	}
+	padded := (n + 3) &^ 3
+	if len(e.data) < padded {
+		e.err = errors.New("event too short")
+		return ""
+	}
+	s := string(e.data[:n-1])
+	e.data = e.data[padded:]
+	return s
+}
+
+// FD 读取一个随消息传递的文件描述符
+func (e *Event) FD() (*os.File, error) {
+	fd, ok := e.conn.popFD()
+	if !ok {
+		return nil, errors.New("missing file descriptor")
+	}
+	return os.NewFile(uintptr(fd), "wayland-fd"), nil
+}
+
+// Err 返回解析参数时遇到的错误
+func (e *Event) Err() error {
+	return e.err
+}
+
+// Conn 与合成器之间的底层连接
+type Conn struct {
+	sock *net.UnixConn
+
+	writeMu sync.Mutex
+
+	readBuf []byte
+	fdMu    sync.Mutex
+	fds     []int
+
+	idMu   sync.Mutex
+	nextID uint32
+}
+
+// SocketPath 根据 WAYLAND_DISPLAY 和 XDG_RUNTIME_DIR 计算套接字路径
+func SocketPath(display string) (string, error) {
+	if display == "" {
+		display = os.Getenv("WAYLAND_DISPLAY")
+	}
+	if display == "" {
+		display = "wayland-0"
+	}
+	if filepath.IsAbs(display) {
+		return display, nil
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		return "", errors.New("XDG_RUNTIME_DIR is not set")
+	}
+	return filepath.Join(runtimeDir, display), nil
+}
+
+// Dial 连接到 Wayland 合成器，display 为空时使用 WAYLAND_DISPLAY
+func Dial(display string) (*Conn, error) {
+	path, err := SocketPath(display)
+	if err != nil {
+		return nil, err
+	}
+	sock, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to wayland display: %v", err)
+	}
+	return NewConn(sock), nil
+}
+
+// NewConn 基于已建立的套接字创建连接，对象 ID 1 固定为 wl_display
+func NewConn(sock *net.UnixConn) *Conn {
+	return &Conn{
+		sock:   sock,
+		nextID: 2,
+	}
+}
+
+// NewID 分配一个新的客户端对象 ID
+func (c *Conn) NewID() uint32 {
+	c.idMu.Lock()
+	defer c.idMu.Unlock()
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+// SendRequest 发送请求
+func (c *Conn) SendRequest(object uint32, opcode uint16, args ...Arg) error {
+	msg := &outgoing{body: make([]byte, 8, 64)}
+	for _, arg := range args {
+		arg.encode(msg)
+	}
+	if len(msg.body) > maxMessageSize {
+		return fmt.Errorf("request too large: %d bytes", len(msg.body))
+	}
+	byteOrder.PutUint32(msg.body[0:], object)
+	byteOrder.PutUint32(msg.body[4:], uint32(len(msg.body))<<16|uint32(opcode))
+
+	var oob []byte
+	if len(msg.fds) > 0 {
+		oob = syscall.UnixRights(msg.fds...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, _, err := c.sock.WriteMsgUnix(msg.body, oob, nil)
+	return err
+}
+
+// ReadEvent 读取下一个事件，只能在单个 goroutine 中调用
+func (c *Conn) ReadEvent() (*Event, error) {
+	for {
+		if len(c.readBuf) >= 8 {
+			size := int(byteOrder.Uint32(c.readBuf[4:]) >> 16)
+			if size < 8 {
+				return nil, fmt.Errorf("invalid message size %d", size)
+			}
+			if len(c.readBuf) >= size {
+				ev := &Event{
+					Sender: byteOrder.Uint32(c.readBuf[0:]),
+					Opcode: uint16(byteOrder.Uint32(c.readBuf[4:])),
+					data:   append([]byte(nil), c.readBuf[8:size]...),
+					conn:   c,
+				}
+				c.readBuf = c.readBuf[size:]
+				return ev, nil
+			}
+		}
+
+		buf := make([]byte, maxMessageSize)
+		oob := make([]byte, syscall.CmsgSpace(28*4))
+		n, oobn, _, _, err := c.sock.ReadMsgUnix(buf, oob)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, errors.New("wayland connection closed")
+		}
+		if oobn > 0 {
+			if err := c.pushFDs(oob[:oobn]); err != nil {
+				return nil, err
+			}
+		}
+		c.readBuf = append(c.readBuf, buf[:n]...)
+	}
+}
+
+// pushFDs 解析辅助数据中的文件描述符
+func (c *Conn) pushFDs(oob []byte) error {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return err
+	}
+	c.fdMu.Lock()
+	defer c.fdMu.Unlock()
+	for _, m := range msgs {
+		fds, err := syscall.ParseUnixRights(&m)
+		if err != nil {
+			continue
+		}
+		c.fds = append(c.fds, fds...)
+	}
+	return nil
+}
+
+// popFD 取出最早收到的文件描述符
+func (c *Conn) popFD() (int, bool) {
+	c.fdMu.Lock()
+	defer c.fdMu.Unlock()
+	if len(c.fds) == 0 {
+		return -1, false
+	}
+	fd := c.fds[0]
+	c.fds = c.fds[1:]
+	return fd, true
+}
+
+// Close 关闭连接
+func (c *Conn) Close() error {
+	c.fdMu.Lock()
+	for _, fd := range c.fds {
+		syscall.Close(fd)
+	}
+	c.fds = nil
+	c.fdMu.Unlock()
+	return c.sock.Close()
+}